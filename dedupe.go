@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// dedupe implements the dedupe subcommand, which removes the extra copies of
+// the media files of an organized directory, keeping the first of each by
+// path, and records each removal in a journal. It returns the process exit
+// code.
+func dedupe(args []string) int {
+	fs := flag.NewFlagSet("dedupe", flag.ContinueOnError)
+	journal := fs.String("journal", "", "journal to record the removals in (required unless --dry-run)")
+	trash := fs.Bool("trash", false, "move the copies into "+organize.TrashDirName+" at the root of the organized directory, from where undo can restore them, instead of deleting them")
+	dryRun := fs.Bool("dry-run", false, "list the copies that would be removed, without removing them")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s dedupe:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s dedupe [--journal <journal>] [--trash] [--dry-run] <organized directory>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || (*journal == "" && !*dryRun) {
+		fs.Usage()
+		return 2
+	}
+	root := fs.Arg(0)
+	o, err := organize.New(organize.WithJournal(*journal), organize.WithBuild(buildSummary()), organize.WithExternalTools(false))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	groups, err := organize.FindDuplicates(root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	code := 0
+	for _, group := range groups {
+		keep := group[0]
+		for _, path := range group[1:] {
+			if *dryRun {
+				fmt.Printf("Would remove %s, identical to %s\n", path, keep)
+				continue
+			}
+			if err := o.Remove(root, path, "identical to "+keep, *trash); err != nil {
+				fmt.Fprintf(os.Stderr, "unable to remove %q: %v\n", path, err)
+				code = 1
+				continue
+			}
+			fmt.Printf("Removed %s, identical to %s\n", path, keep)
+		}
+	}
+	return code
+}
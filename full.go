@@ -0,0 +1,8 @@
+//go:build !minimal
+// +build !minimal
+
+package main
+
+// minimalBuild reports whether the binary is a minimal build, built with the
+// minimal tag, which leaves out the features with heavy dependencies.
+const minimalBuild = false
@@ -1,3 +1,10 @@
 module github.com/cvanderw/organizepics
 
 go 1.16
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/fsnotify/fsnotify v1.6.0
+	golang.org/x/sys v0.7.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+)
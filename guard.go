@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// systemDirs lists directories that must never be organized: doing so would
+// shuffle operating system or application files into dated directories.
+var systemDirs = []string{
+	"/bin", "/boot", "/dev", "/etc", "/lib", "/lib64", "/opt", "/proc",
+	"/root", "/sbin", "/sys", "/usr", "/var",
+	"/Applications", "/Library", "/System", "/Users",
+}
+
+// Directories with more than minNonMediaFiles files that aren't pictures or
+// videos, making up more than maxNonMediaFraction of all files, look like
+// general purpose directories rather than picture dumps.
+const (
+	minNonMediaFiles    = 20
+	maxNonMediaFraction = 0.5
+)
+
+// checkSafeToOrganize returns an error if dirName is obviously not meant to be
+// organized: a file system root, a system directory, the user's home
+// directory, or, unless mixed is set, a directory mostly holding files other
+// than pictures and videos. It protects against accidentally shuffling the
+// files of a general purpose directory, e.g. after a typo in the path. mixed
+// is set for directories known to mix pictures with other files, such as
+// Downloads, whose other files are left alone.
+func checkSafeToOrganize(dirName string, mixed bool) error {
+	abs, err := filepath.Abs(dirName)
+	if err != nil {
+		return err
+	}
+	if abs == filepath.Dir(abs) {
+		return fmt.Errorf("%q is the root of a file system", dirName)
+	}
+	dirs := systemDirs
+	if windir := os.Getenv("SystemRoot"); windir != "" {
+		dirs = append(dirs, windir)
+	}
+	for _, dir := range dirs {
+		if abs == filepath.Clean(dir) {
+			return fmt.Errorf("%q is a system directory", dirName)
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil && abs == filepath.Clean(home) {
+		return fmt.Errorf("%q is your home directory", dirName)
+	}
+
+	if mixed {
+		return nil
+	}
+	files, err := os.ReadDir(dirName)
+	if err != nil {
+		return err
+	}
+	total, nonMedia := 0, 0
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		total++
+		if !organize.IsMedia(file.Name()) {
+			nonMedia++
+		}
+	}
+	if nonMedia > minNonMediaFiles && float64(nonMedia) > maxNonMediaFraction*float64(total) {
+		return fmt.Errorf("%q holds %d files that aren't pictures or videos (out of %d)", dirName, nonMedia, total)
+	}
+	return nil
+}
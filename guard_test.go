@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckSafeToOrganize(t *testing.T) {
+	for _, dir := range []string{"/", "/etc", "/usr/"} {
+		if err := checkSafeToOrganize(dir, true); err == nil {
+			t.Errorf("checkSafeToOrganize(%q) expected error but received none", dir)
+		}
+	}
+
+	pictures := t.TempDir()
+	documents := t.TempDir()
+	for i := 0; i < 30; i++ {
+		for dir, ext := range map[string]string{pictures: "jpg", documents: "pdf"} {
+			name := filepath.Join(dir, fmt.Sprintf("file%d.%s", i, ext))
+			if err := os.WriteFile(name, nil, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := checkSafeToOrganize(pictures, false); err != nil {
+		t.Errorf("checkSafeToOrganize(pictures) returned error: %v", err)
+	}
+	if err := checkSafeToOrganize(documents, false); err == nil {
+		t.Error("checkSafeToOrganize(documents) expected error but received none")
+	}
+	if err := checkSafeToOrganize(documents, true); err != nil {
+		t.Errorf("checkSafeToOrganize(documents, true) returned error: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// matchResult is the result of the "match" method for a single name.
+type matchResult struct {
+	Name  string `json:"name"`
+	Date  string `json:"date,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// runResult is the result of the "organize" and "apply" methods.
+type runResult struct {
+	// Moved is the number of files moved.
+	Moved  int             `json:"moved"`
+	Report organize.Report `json:"report"`
+}
+
+// serveJSONRPC reads JSON-RPC 2.0 requests from r and writes the responses to
+// w, one JSON value per line, until r is exhausted. This lets scripts drive
+// organizepics without starting a process per operation. The methods are:
+//
+//	match    {"names": [...]}  dates the given file names
+//	plan     {"dir": "..."}    plans organizing dir, without changing anything
+//	organize {"dir": "..."}    organizes dir and reports what was moved
+//	apply    {"plan": "..."}   applies the plan file written by the plan command
+//
+// All methods use the options given on the command line. Unless checkDir is
+// nil, the directories to plan or organize must pass it first, like those given
+// on the command line.
+func serveJSONRPC(r io.Reader, w io.Writer, o *organize.Organizer, checkDir func(dir string) error) error {
+	dec := json.NewDecoder(r)
+	enc := json.NewEncoder(w)
+	for {
+		var req rpcRequest
+		err := dec.Decode(&req)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// The stream can't be resynchronized after malformed input.
+			enc.Encode(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, err.Error()}})
+			return err
+		}
+		resp := handleRPC(req, o, checkDir)
+		// Requests without an id are notifications and get no response.
+		if req.ID == nil {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// handleRPC executes a single JSON-RPC request.
+func handleRPC(req rpcRequest, o *organize.Organizer, checkDir func(dir string) error) rpcResponse {
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	fail := func(code int, format string, args ...interface{}) rpcResponse {
+		resp.Error = &rpcError{code, fmt.Sprintf(format, args...)}
+		return resp
+	}
+	if req.JSONRPC != "2.0" {
+		return fail(rpcInvalidRequest, "unsupported JSON-RPC version %q", req.JSONRPC)
+	}
+	var params struct {
+		Names []string `json:"names"`
+		Dir   string   `json:"dir"`
+		Plan  string   `json:"plan"`
+	}
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return fail(rpcInvalidParams, "invalid params: %v", err)
+		}
+	}
+
+	switch req.Method {
+	case "match":
+		results := []matchResult{}
+		for _, name := range params.Names {
+			result := matchResult{Name: name}
+			if date, err := o.Date(name); err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Date = date.Format("2006-01-02")
+			}
+			results = append(results, result)
+		}
+		resp.Result = results
+	case "plan", "organize":
+		if params.Dir == "" {
+			return fail(rpcInvalidParams, "missing dir")
+		}
+		if checkDir != nil {
+			if err := checkDir(params.Dir); err != nil {
+				return fail(rpcServerError, "refusing to organize: %v; use --force if you are sure", err)
+			}
+		}
+		if req.Method == "plan" {
+			p, err := o.Plan(params.Dir)
+			if err != nil {
+				return fail(rpcServerError, "%v", err)
+			}
+			resp.Result = p
+			break
+		}
+		moved, err := o.Organize(params.Dir)
+		if err != nil {
+			return fail(rpcServerError, "%v", err)
+		}
+		resp.Result = runResult{moved, o.Report()}
+	case "apply":
+		if params.Plan == "" {
+			return fail(rpcInvalidParams, "missing plan")
+		}
+		// The directory was checked when planning.
+		pf, err := loadPlan(params.Plan)
+		if err != nil {
+			return fail(rpcServerError, "%v", err)
+		}
+		moved, err := o.Apply(pf)
+		if err != nil {
+			return fail(rpcServerError, "%v", err)
+		}
+		resp.Result = runResult{moved, o.Report()}
+	default:
+		return fail(rpcMethodNotFound, "unknown method %q", req.Method)
+	}
+	return resp
+}
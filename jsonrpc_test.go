@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+func TestServeJSONRPC(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "IMG_20210222_213525.jpg"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	dirJSON, _ := json.Marshal(dir)
+	requests := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "match", "params": {"names": ["VID_20201012_124124.mp4", "notes.txt"]}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "plan", "params": {"dir": ` + string(dirJSON) + `}}`,
+		`{"jsonrpc": "2.0", "method": "plan", "params": {"dir": ` + string(dirJSON) + `}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "organize", "params": {"dir": ` + string(dirJSON) + `}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "undo"}`,
+	}, "\n")
+
+	o, err := organize.New(organize.WithExternalTools(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	checkDir := func(dir string) error { return checkSafeToOrganize(dir, false) }
+	if err := serveJSONRPC(strings.NewReader(requests), &out, o, checkDir); err != nil {
+		t.Fatalf("serveJSONRPC returned error: %v", err)
+	}
+
+	var responses []map[string]interface{}
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]interface{}
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, resp)
+	}
+	if len(responses) != 4 {
+		t.Fatalf("got %d responses, want 4 (notifications get none): %v", len(responses), responses)
+	}
+
+	match := responses[0]["result"].([]interface{})
+	if date := match[0].(map[string]interface{})["date"]; date != "2020-10-12" {
+		t.Errorf("match returned date %v, want 2020-10-12", date)
+	}
+	if match[1].(map[string]interface{})["error"] == nil {
+		t.Error("match returned no error for an unrecognized name")
+	}
+	if moves := responses[1]["result"].(map[string]interface{})["moves"].([]interface{}); len(moves) != 1 {
+		t.Errorf("plan returned %d moves, want 1", len(moves))
+	}
+	if moved := responses[2]["result"].(map[string]interface{})["moved"]; moved != 1.0 {
+		t.Errorf("organize returned moved = %v, want 1", moved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2021-02-22", "IMG_20210222_213525.jpg")); err != nil {
+		t.Errorf("organize did not move the file: %v", err)
+	}
+	if responses[3]["error"] == nil {
+		t.Error("unknown method returned no error")
+	}
+}
+
+func TestServeJSONRPCRefusesUnsafeDir(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 30; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.pdf", i)), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	img := filepath.Join(dir, "IMG_20210222_213525.jpg")
+	if err := os.WriteFile(img, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	dirJSON, _ := json.Marshal(dir)
+	requests := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "plan", "params": {"dir": ` + string(dirJSON) + `}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "organize", "params": {"dir": ` + string(dirJSON) + `}}`,
+	}, "\n")
+
+	o, err := organize.New(organize.WithExternalTools(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	checkDir := func(dir string) error { return checkSafeToOrganize(dir, false) }
+	if err := serveJSONRPC(strings.NewReader(requests), &out, o, checkDir); err != nil {
+		t.Fatalf("serveJSONRPC returned error: %v", err)
+	}
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]interface{}
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp["error"] == nil {
+			t.Errorf("request %v was not refused: %v", resp["id"], resp)
+		}
+	}
+	if _, err := os.Stat(img); err != nil {
+		t.Errorf("organize moved the file of a refused directory: %v", err)
+	}
+
+	// With --force, there is no check.
+	out.Reset()
+	if err := serveJSONRPC(strings.NewReader(requests), &out, o, nil); err != nil {
+		t.Fatalf("serveJSONRPC returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2021-02-22", "IMG_20210222_213525.jpg")); err != nil {
+		t.Errorf("organize did not move the file with --force: %v", err)
+	}
+}
+
+func TestServeJSONRPCApply(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "IMG_20210222_213525.jpg"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	o, err := organize.New(organize.WithExternalTools(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := o.PlanFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writePlan(&buf, pf); err != nil {
+		t.Fatal(err)
+	}
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(planPath, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	planJSON, _ := json.Marshal(planPath)
+	requests := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "apply", "params": {"plan": ` + string(planJSON) + `}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "apply"}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := serveJSONRPC(strings.NewReader(requests), &out, o, nil); err != nil {
+		t.Fatalf("serveJSONRPC returned error: %v", err)
+	}
+	var responses []map[string]interface{}
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]interface{}
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, resp)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("got %d responses, want 2: %v", len(responses), responses)
+	}
+	if moved := responses[0]["result"].(map[string]interface{})["moved"]; moved != 1.0 {
+		t.Errorf("apply returned moved = %v, want 1", moved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2021-02-22", "IMG_20210222_213525.jpg")); err != nil {
+		t.Errorf("apply did not move the file: %v", err)
+	}
+	if responses[1]["error"] == nil {
+		t.Error("apply without a plan returned no error")
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// byteSize is a flag.Value for a number of bytes, optionally with a K, M or G
+// suffix (powers of 1024), as in "512M".
+type byteSize int64
+
+// byteSizeUnits are the suffixes accepted by byteSize, by multiplier.
+var byteSizeUnits = map[string]int64{
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+}
+
+// String implements flag.Value.
+func (b *byteSize) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+// Set implements flag.Value.
+func (b *byteSize) Set(s string) error {
+	number, multiplier := strings.ToUpper(strings.TrimSpace(s)), int64(1)
+	number = strings.TrimSuffix(number, "B")
+	if len(number) > 0 {
+		if m, ok := byteSizeUnits[number[len(number)-1:]]; ok {
+			number, multiplier = number[:len(number)-1], m
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 || n > (1<<63-1)/multiplier {
+		return fmt.Errorf("invalid size %q, want e.g. 512M", s)
+	}
+	*b = byteSize(n * multiplier)
+	return nil
+}
+
+// lowMemoryGCPercent is the garbage collection target used when memory is
+// limited, so that the heap stays close to the memory in use.
+const lowMemoryGCPercent = 25
+
+// limitMemory makes the Go runtime collect garbage more eagerly, for runs
+// limited to maxMemory bytes.
+func limitMemory(maxMemory byteSize) {
+	if maxMemory > 0 {
+		debug.SetGCPercent(lowMemoryGCPercent)
+	}
+}
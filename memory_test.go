@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		s           string
+		want        byteSize
+		errExpected bool
+	}{
+		{"0", 0, false},
+		{"1000", 1000, false},
+		{"512M", 512 << 20, false},
+		{"512mb", 512 << 20, false},
+		{"2G", 2 << 30, false},
+		{"64k", 64 << 10, false},
+		{"", 0, true},
+		{"-1", 0, true},
+		{"1T", 0, true},
+		{"M", 0, true},
+		{"9999999999G", 0, true}, // Overflows.
+	}
+	for _, tt := range tests {
+		var got byteSize
+		err := got.Set(tt.s)
+		if err != nil && !tt.errExpected {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+		if tt.errExpected && err == nil {
+			t.Errorf("Expected error but received none (size: %q)", tt.s)
+		}
+		if got != tt.want {
+			t.Errorf("got %d, want %d (size: %q)", got, tt.want, tt.s)
+		}
+	}
+}
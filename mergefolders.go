@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// mergeFolders implements the merge-folders subcommand, which merges the
+// directories of an organized directory dated the same, such as 2021-02-22
+// and "2021-02-22 Birthday". It returns the process exit code: 1 if files
+// were left in place because of name conflicts.
+func mergeFolders(args []string) int {
+	fs := flag.NewFlagSet("merge-folders", flag.ContinueOnError)
+	layout := fs.String("layout", organize.DefaultLayout, "Go time layout the directory was organized with, e.g. 2006/2006-01-02")
+	rule := organize.MergeDescriptive
+	fs.Var(&rule, "rule", "directory to keep: descriptive (the one with a description, e.g. \"2021-02-22 Birthday\") or plain (the one named by the date alone)")
+	journal := fs.String("journal", "", "journal to record the moves in, as written with --journal")
+	dryRun := fs.Bool("dry-run", false, "log the directories that would be merged, without changing anything")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s merge-folders:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s merge-folders [--rule descriptive|plain] [--layout <date layout>] [--journal <journal>] [--dry-run] <organized directory>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	o, err := organize.New(organize.WithLayout(*layout), organize.WithJournal(*journal), organize.WithBuild(buildSummary()), organize.WithDryRun(*dryRun), organize.WithExternalTools(false))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	merges, err := o.MergeFolders(fs.Arg(0), rule)
+	verb, conflicts := "Merged", 0
+	if *dryRun {
+		verb = "Would merge"
+	}
+	for _, m := range merges {
+		for _, from := range m.From {
+			fmt.Printf("%s %q into %q\n", verb, from, m.Into)
+		}
+		for _, c := range m.Conflicts {
+			fmt.Printf("%s: left in place, %q has a file of the same name\n", c, m.Into)
+		}
+		conflicts += len(m.Conflicts)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if conflicts > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// translations maps each supported language to the translations of the
+// user-facing messages, keyed by their English text. Messages that are
+// missing fall back to English. Reports written with --report are meant for
+// machines and stay in English.
+var translations = map[string]map[string]string{
+	"de": {
+		"Summary: %s":              "Zusammenfassung: %s",
+		"%d moved":                 "%d verschoben",
+		"%d skipped":               "%d übersprungen",
+		"%d removed as duplicates": "%d als Duplikate entfernt",
+		"%d unmatched":             "%d ohne Datum",
+		"%d conflicts":             "%d Konflikte",
+		"%d errors":                "%d Fehler",
+		"Dry run: %d files would have been moved": "Probelauf: %d Dateien wären verschoben worden",
+		"%s %d/%d files (%d%%)":                   "%s %d/%d Dateien (%d%%)",
+		"%s left":                                 "noch %s",
+		"dating":                                  "Datieren",
+		"moving":                                  "Verschieben",
+	},
+	"es": {
+		"Summary: %s":              "Resumen: %s",
+		"%d moved":                 "%d movidos",
+		"%d skipped":               "%d omitidos",
+		"%d removed as duplicates": "%d eliminados por duplicados",
+		"%d unmatched":             "%d sin fecha",
+		"%d conflicts":             "%d conflictos",
+		"%d errors":                "%d errores",
+		"Dry run: %d files would have been moved": "Simulación: se habrían movido %d archivos",
+		"%s %d/%d files (%d%%)":                   "%s %d/%d archivos (%d%%)",
+		"%s left":                                 "quedan %s",
+		"dating":                                  "datación",
+		"moving":                                  "traslado",
+	},
+	"fr": {
+		"Summary: %s":              "Résumé : %s",
+		"%d moved":                 "%d déplacés",
+		"%d skipped":               "%d ignorés",
+		"%d removed as duplicates": "%d supprimés comme doublons",
+		"%d unmatched":             "%d sans date",
+		"%d conflicts":             "%d conflits",
+		"%d errors":                "%d erreurs",
+		"Dry run: %d files would have been moved": "Essai à blanc : %d fichiers auraient été déplacés",
+		"%s %d/%d files (%d%%)":                   "%s %d/%d fichiers (%d %%)",
+		"%s left":                                 "encore %s",
+		"dating":                                  "datation",
+		"moving":                                  "déplacement",
+	},
+	"it": {
+		"Summary: %s":              "Riepilogo: %s",
+		"%d moved":                 "%d spostati",
+		"%d skipped":               "%d saltati",
+		"%d removed as duplicates": "%d rimossi come duplicati",
+		"%d unmatched":             "%d senza data",
+		"%d conflicts":             "%d conflitti",
+		"%d errors":                "%d errori",
+		"Dry run: %d files would have been moved": "Prova: %d file sarebbero stati spostati",
+		"%s %d/%d files (%d%%)":                   "%s %d/%d file (%d%%)",
+		"%s left":                                 "mancano %s",
+		"dating":                                  "datazione",
+		"moving":                                  "spostamento",
+	},
+	"nl": {
+		"Summary: %s":              "Samenvatting: %s",
+		"%d moved":                 "%d verplaatst",
+		"%d skipped":               "%d overgeslagen",
+		"%d removed as duplicates": "%d verwijderd als duplicaat",
+		"%d unmatched":             "%d zonder datum",
+		"%d conflicts":             "%d conflicten",
+		"%d errors":                "%d fouten",
+		"Dry run: %d files would have been moved": "Proefdraai: %d bestanden zouden zijn verplaatst",
+		"%s %d/%d files (%d%%)":                   "%s %d/%d bestanden (%d%%)",
+		"%s left":                                 "nog %s",
+		"dating":                                  "dateren",
+		"moving":                                  "verplaatsen",
+	},
+	"pt": {
+		"Summary: %s":              "Resumo: %s",
+		"%d moved":                 "%d movidos",
+		"%d skipped":               "%d ignorados",
+		"%d removed as duplicates": "%d removidos como duplicados",
+		"%d unmatched":             "%d sem data",
+		"%d conflicts":             "%d conflitos",
+		"%d errors":                "%d erros",
+		"Dry run: %d files would have been moved": "Simulação: %d arquivos teriam sido movidos",
+		"%s %d/%d files (%d%%)":                   "%s %d/%d arquivos (%d%%)",
+		"%s left":                                 "faltam %s",
+		"dating":                                  "datação",
+		"moving":                                  "movimentação",
+	},
+}
+
+// language is the language of the user-facing messages, or empty for
+// English. It is set by setLanguage.
+var language string
+
+// setLanguage sets the language of the messages to lang, such as "de" or
+// "pt_BR.UTF-8", or if empty to the one of the environment's locale.
+// Unsupported languages leave the messages in English.
+func setLanguage(lang string) {
+	for _, v := range []string{lang, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")} {
+		if v != "" {
+			lang = v
+			break
+		}
+	}
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "_-."); i >= 0 {
+		lang = lang[:i]
+	}
+	language = ""
+	if _, ok := translations[lang]; ok {
+		language = lang
+	}
+}
+
+// languages returns the supported languages other than English, sorted.
+func languages() []string {
+	var langs []string
+	for lang := range translations {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// tr returns the translation of the message s into the language of the
+// messages, or s itself if there is none.
+func tr(s string) string {
+	if t, ok := translations[language][s]; ok {
+		return t
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+func TestSetLanguage(t *testing.T) {
+	defer setLanguage("en")
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"de", "de"},
+		{"pt_BR.UTF-8", "pt"},
+		{"fr-CA", "fr"},
+		{"en_US.UTF-8", ""},
+		{"C", ""},
+		{"xx", ""},
+	}
+	for _, tt := range tests {
+		setLanguage(tt.lang)
+		if language != tt.want {
+			t.Errorf("got %q, want %q (lang: %s)", language, tt.want, tt.lang)
+		}
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	// Every language translates the same messages, keeping their verbs.
+	english := translations["de"]
+	for lang, messages := range translations {
+		if len(messages) != len(english) {
+			t.Errorf("got %d messages in %s, want %d", len(messages), lang, len(english))
+		}
+		for s, translation := range messages {
+			if _, ok := english[s]; !ok {
+				t.Errorf("got unknown message %q in %s", s, lang)
+			}
+			if got, want := strings.Count(translation, "%"), strings.Count(s, "%"); got != want {
+				t.Errorf("got %d verbs in %q (%s), want %d", got, translation, lang, want)
+			}
+		}
+	}
+}
+
+func TestFormatSummaryTranslated(t *testing.T) {
+	defer setLanguage("en")
+	setLanguage("de")
+	report := organize.Report{Files: []organize.FileResult{{Status: organize.StatusMoved}}}
+	want := "1 verschoben, 0 übersprungen, 0 als Duplikate entfernt, 0 ohne Datum, 0 Konflikte, 0 Fehler"
+	if got := formatSummary(report); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build minimal
+// +build minimal
+
+// Minimal builds leave out the features with heavy dependencies (HTTP for
+// --notify-url and self-update, YAML and TOML for config files, file system
+// notifications for watch), for a small static binary on routers and embedded
+// NAS units.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// minimalBuild reports whether the binary is a minimal build.
+const minimalBuild = true
+
+// request would request the URL of n, but HTTP support is left out of minimal
+// builds.
+func (n indexNotifier) request() error {
+	return errors.New("--notify-url is not supported by minimal builds")
+}
+
+// selfUpdate would implement the self-update subcommand, but HTTP support is
+// left out of minimal builds.
+func selfUpdate(args []string) int {
+	fmt.Fprintln(os.Stderr, "self-update is not supported by minimal builds")
+	return 1
+}
+
+// watchDir polls dir every interval, as file system notifications are left
+// out of minimal builds. See pollDir.
+func watchDir(dir string, recursive bool, interval, settle time.Duration, organize func(), stop <-chan struct{}) {
+	pollDir(dir, recursive, interval, settle, organize, stop)
+}
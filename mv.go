@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// mv implements the mv subcommand, which moves a file of an organized
+// directory into the directory of another date, keeping the journal,
+// playlists and Recent links consistent. It returns the process exit code.
+func mv(args []string) int {
+	fs := flag.NewFlagSet("mv", flag.ContinueOnError)
+	root := fs.String("root", "", "organized directory holding the file (default: guessed from the file's location)")
+	layout := fs.String("layout", organize.DefaultLayout, "Go time layout the directory was organized with, e.g. 2006/2006-01-02")
+	journal := fs.String("journal", "", "journal to record the move in, as written with --journal")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s mv:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s mv [--root <dir>] [--layout <date layout>] [--journal <journal>] <file> <date>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+	date, err := parseSetDate(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fs.Usage()
+		return 2
+	}
+	if *root == "" {
+		*root = archiveRoot(path, *layout)
+	}
+	o, err := organize.New(organize.WithLayout(*layout), organize.WithJournal(*journal), organize.WithBuild(buildSummary()), organize.WithExternalTools(false))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	dest, err := o.Relocate(*root, path, date)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Moved %q to %q\n", path, dest)
+	return 0
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// stringsFlag is a flag.Value collecting the values of a repeatable flag.
+type stringsFlag []string
+
+// String implements flag.Value.
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+// Set implements flag.Value.
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// indexNotifier tells downstream apps (photo managers, media servers) that
+// new files were organized, so they pick them up without waiting for their
+// next periodic scan.
+type indexNotifier struct {
+	// touchPath is a marker file whose modification time is updated.
+	touchPath string
+	// url is requested with method, sending headers ("Name: value").
+	url     string
+	method  string
+	headers []string
+}
+
+// notify touches the marker file and requests the URL, if configured.
+func (n indexNotifier) notify() error {
+	if n.touchPath != "" {
+		f, err := os.OpenFile(n.touchPath, os.O_WRONLY|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		f.Close()
+		now := time.Now()
+		if err := os.Chtimes(n.touchPath, now, now); err != nil {
+			return err
+		}
+	}
+	if n.url == "" {
+		return nil
+	}
+	return n.request()
+}
@@ -0,0 +1,39 @@
+//go:build !minimal
+// +build !minimal
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// notifyTimeout bounds how long a run waits for a notified app to respond.
+const notifyTimeout = 30 * time.Second
+
+// request requests the URL of n.
+func (n indexNotifier) request() error {
+	req, err := http.NewRequest(n.method, n.url, nil)
+	if err != nil {
+		return err
+	}
+	for _, header := range n.headers {
+		i := strings.Index(header, ":")
+		if i < 0 {
+			return fmt.Errorf("invalid header %q, want \"Name: value\"", header)
+		}
+		req.Header.Add(strings.TrimSpace(header[:i]), strings.TrimSpace(header[i+1:]))
+	}
+	client := &http.Client{Timeout: notifyTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s", n.method, n.url, resp.Status)
+	}
+	return nil
+}
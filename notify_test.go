@@ -0,0 +1,49 @@
+//go:build !minimal
+// +build !minimal
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexNotifier(t *testing.T) {
+	var gotMethod, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotKey = r.Method, r.Header.Get("X-Api-Key")
+	}))
+	defer server.Close()
+
+	marker := filepath.Join(t.TempDir(), ".organized")
+	n := indexNotifier{
+		touchPath: marker,
+		url:       server.URL,
+		method:    http.MethodPost,
+		headers:   []string{"x-api-key: secret"},
+	}
+	if err := n.notify(); err != nil {
+		t.Fatalf("notify returned error: %v", err)
+	}
+	if gotMethod != http.MethodPost || gotKey != "secret" {
+		t.Errorf("server received method %q with key %q, want POST with key \"secret\"", gotMethod, gotKey)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("marker file not created: %v", err)
+	}
+}
+
+func TestIndexNotifierError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	n := indexNotifier{url: server.URL, method: http.MethodGet}
+	if err := n.notify(); err == nil {
+		t.Error("Expected error but received none")
+	}
+}
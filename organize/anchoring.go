@@ -0,0 +1,54 @@
+package organize
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// AnchoringPolicy controls where in a file name the built-in matchers' date
+// patterns may be found.
+type AnchoringPolicy string
+
+const (
+	// AnchorSubstring lets a pattern match anywhere in the name, so e.g.
+	// "xIMG_20210222_213525.jpg" is treated like "IMG_20210222_213525.jpg".
+	AnchorSubstring AnchoringPolicy = "substring"
+	// AnchorPrefix requires a pattern to match at the start of the name.
+	AnchorPrefix AnchoringPolicy = "prefix"
+
+	// DefaultAnchoring is the anchoring policy used unless configured
+	// otherwise. It matches the historical behavior of the built-in matchers.
+	DefaultAnchoring = AnchorSubstring
+)
+
+// String implements flag.Value.
+func (p *AnchoringPolicy) String() string {
+	return string(*p)
+}
+
+// Set implements flag.Value.
+func (p *AnchoringPolicy) Set(s string) error {
+	switch policy := AnchoringPolicy(s); policy {
+	case AnchorSubstring, AnchorPrefix:
+		*p = policy
+		return nil
+	}
+	return fmt.Errorf("unknown anchoring policy %q, want %q or %q", s, AnchorPrefix, AnchorSubstring)
+}
+
+// anchorMatchers returns the matchers adjusted to the given anchoring policy.
+func anchorMatchers(matchers []*MediaFileMatcher, policy AnchoringPolicy) []*MediaFileMatcher {
+	if policy != AnchorPrefix {
+		return matchers
+	}
+	anchored := make([]*MediaFileMatcher, len(matchers))
+	for i, m := range matchers {
+		a := *m
+		a.supportedRegexps = make([]*regexp.Regexp, len(m.supportedRegexps))
+		for j, re := range m.supportedRegexps {
+			a.supportedRegexps[j] = regexp.MustCompile(`^(?:` + re.String() + `)`)
+		}
+		anchored[i] = &a
+	}
+	return anchored
+}
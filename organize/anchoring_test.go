@@ -0,0 +1,60 @@
+package organize
+
+import "testing"
+
+func TestAnchorMatchers(t *testing.T) {
+	tests := []struct {
+		fileName      string
+		substringWant bool
+		prefixWant    bool
+		date          string
+	}{
+		{"IMG_20210222_213525.jpg", true, true, "2021-02-22"},
+		{"xIMG_20210222_213525.jpg", true, false, "2021-02-22"},
+		{"copy of IMG_20210222_213525.jpg", true, false, "2021-02-22"},
+		{"20170402_1979.jpg", true, true, "2017-04-02"},
+		{"backup-20170402_1979.jpg", true, false, "2017-04-02"},
+		{"C360_2019-07-17-04-02-45-169.jpg", true, true, "2019-07-17"},
+		{"old_C360_2019-07-17-04-02-45-169.jpg", true, false, "2019-07-17"},
+		{"IMG-20230415-WA0012.jpg", true, true, "2023-04-15"},
+		{"my-IMG-20230415-WA0012.jpg", true, false, "2023-04-15"},
+		{"saved signal-2022-11-05-183012.jpg", true, false, "2022-11-05"},
+		{"15 Mar 2023 - beach.jpg", true, true, "2023-03-15"},
+		{"a 15 Mar 2023 - beach.jpg", false, false, ""},
+	}
+
+	for _, tt := range tests {
+		for _, policy := range []AnchoringPolicy{AnchorSubstring, AnchorPrefix} {
+			want := tt.substringWant
+			if policy == AnchorPrefix {
+				want = tt.prefixWant
+			}
+			got := false
+			for _, m := range anchorMatchers(mediaMatchers, policy) {
+				if !m.MatchFileName(tt.fileName) {
+					continue
+				}
+				got = true
+				date, err := m.ParseDate(tt.fileName)
+				if err != nil {
+					t.Errorf("with %s anchoring, %s: ParseDate(%q): Expected no error but received: %s", policy, m.name, tt.fileName, err)
+				} else if d := date.Format("2006-01-02"); d != tt.date {
+					t.Errorf("with %s anchoring, %s: ParseDate(%q) got %s, want %s", policy, m.name, tt.fileName, d, tt.date)
+				}
+			}
+			if got != want {
+				t.Errorf("with %s anchoring, match of %q = %v, want %v", policy, tt.fileName, got, want)
+			}
+		}
+	}
+}
+
+func TestAnchoringPolicySet(t *testing.T) {
+	var p AnchoringPolicy
+	if err := p.Set("prefix"); err != nil || p != AnchorPrefix {
+		t.Errorf("Set(prefix) = %v, policy %q", err, p)
+	}
+	if err := p.Set("anywhere"); err == nil {
+		t.Error("Expected error for unknown policy but received none")
+	}
+}
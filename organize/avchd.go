@@ -0,0 +1,61 @@
+package organize
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// avchdRoots lists the locations, relative to the directory being organized,
+// at which camcorders store the BDMV directory of an AVCHD recording: the
+// root of an SD card, or a copy of its PRIVATE or AVCHD directory.
+var avchdRoots = []string{
+	filepath.Join("PRIVATE", "AVCHD", "BDMV"),
+	filepath.Join("AVCHD", "BDMV"),
+	"BDMV",
+}
+
+// avchdClips lists the clips of any AVCHD structure found in dirName, which
+// are planned like other files. Each STREAM/*.MTS clip is dated by its
+// modification time (AVCHD clip names carry no date), and has its
+// CLIPINF/*.CPI clip information file as a sidecar, so the pair stays usable
+// by editing software. The sidecars of the clips are added to sidecars.
+func avchdClips(dirName string, sidecars map[string][]string, opts options) []datedFile {
+	var clips []datedFile
+	for _, root := range avchdRoots {
+		bdmv := filepath.Join(dirName, root)
+		entries, err := os.ReadDir(filepath.Join(bdmv, "STREAM"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".mts") {
+				continue
+			}
+			if filtered(filepath.ToSlash(filepath.Join(root, "STREAM", entry.Name())), opts) {
+				continue
+			}
+			clip := datedFile{sourceFile: sourceFile{filepath.Join(bdmv, "STREAM", entry.Name()), entry}}
+			info, err := entry.Info()
+			if err != nil {
+				clip.err = fmt.Errorf("unable to stat AVCHD clip %q: %v", entry.Name(), err)
+				clips = append(clips, clip)
+				continue
+			}
+			clip.date = folderDate(info.ModTime(), false, opts.folderDate)
+			log.Printf("Dating AVCHD clip %q by its modification time", entry.Name())
+			base := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+			for _, ext := range []string{".CPI", ".cpi"} {
+				clipInfo := filepath.Join(bdmv, "CLIPINF", base+ext)
+				if _, err := os.Stat(clipInfo); err == nil {
+					sidecars[clip.path] = append(sidecars[clip.path], clipInfo)
+					break
+				}
+			}
+			clips = append(clips, clip)
+		}
+	}
+	return clips
+}
@@ -0,0 +1,81 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeAVCHD creates the clip 00000.MTS and its clip information file in the
+// AVCHD structure at root, relative to dir, modified at modTime.
+func writeAVCHD(t *testing.T, dir, root string, modTime time.Time) {
+	bdmv := filepath.Join(dir, root)
+	for _, f := range []string{filepath.Join(bdmv, "STREAM", "00000.MTS"), filepath.Join(bdmv, "CLIPINF", "00000.CPI")} {
+		if err := os.MkdirAll(filepath.Dir(f), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, []byte(root), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(f, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOrganizeAVCHD(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2012, 8, 14, 10, 30, 0, 0, time.Local)
+	writeAVCHD(t, dir, filepath.Join("PRIVATE", "AVCHD", "BDMV"), modTime)
+	// A copy of another card's AVCHD directory, whose clip has the same name.
+	writeAVCHD(t, dir, filepath.Join("AVCHD", "BDMV"), modTime)
+
+	o, err := New(WithExternalTools(false), WithOnConflict(ConflictRename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := o.Organize(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if moved != 2 {
+		t.Errorf("got %d files moved, want 2", moved)
+	}
+	for _, name := range []string{"00000.MTS", "00000.CPI", "00000_1.MTS", "00000_1.CPI"} {
+		if _, err := os.Stat(filepath.Join(dir, "2012-08-14", name)); err != nil {
+			t.Errorf("expected %s to be moved into 2012-08-14: %v", name, err)
+		}
+	}
+}
+
+func TestOrganizeAVCHDOptions(t *testing.T) {
+	root := filepath.Join("PRIVATE", "AVCHD", "BDMV")
+	clip := filepath.Join(root, "STREAM", "00000.MTS")
+	tests := []struct {
+		opts []Option
+	}{
+		{[]Option{WithDryRun(true)}},
+		{[]Option{WithExclude("*.MTS")}},
+		{[]Option{WithInclude("*.jpg")}},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeAVCHD(t, dir, root, time.Date(2012, 8, 14, 10, 30, 0, 0, time.Local))
+
+		o, err := New(append([]Option{WithExternalTools(false)}, tt.opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := o.Organize(dir); err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, clip)); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "2012-08-14")); !os.IsNotExist(err) {
+			t.Errorf("got %v, want 2012-08-14 not to exist", err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package organize
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image"
+	_ "image/jpeg" // Register the JPEG decoder for image.DecodeConfig.
+	_ "image/png"  // Register the PNG decoder for image.DecodeConfig.
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// mediaClass is the kind of picture a file holds, as guessed by classify.
+type mediaClass int
+
+const (
+	classPhoto mediaClass = iota
+	classScreenshot
+	classDocument
+)
+
+// classDirs maps each media class to the directory, under the directory being
+// organized, holding its dated directories. Photos are kept at the top level.
+var classDirs = map[mediaClass]string{
+	classPhoto:      "",
+	classScreenshot: "Screenshots",
+	classDocument:   "Documents",
+}
+
+// scanNameRegexp matches the lower case names of scanned documents, such as
+// "scan_0001.jpg" or "Scanned Document.png", but not the names that merely
+// contain the letters, such as "landscape.jpg".
+var scanNameRegexp = regexp.MustCompile(`(?:^|[^a-z])scan(?:ned)?(?:[^a-z]|$)`)
+
+// paperAspectRatios lists the aspect ratios of common paper sizes: ISO A
+// series, US Letter and US Legal.
+var paperAspectRatios = []float64{math.Sqrt2, 11 / 8.5, 14 / 8.5}
+
+// classify guesses whether the image at path is a photo, a screenshot or a
+// scanned document using simple heuristics: the file name, the image format,
+// the presence of EXIF data (written by cameras, but not by screenshot tools
+// or most scanners) and whether the dimensions match a paper size. Files that
+// can't be read as images are considered photos.
+func classify(path string) mediaClass {
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.Contains(name, "screenshot"), strings.Contains(name, "screen shot"):
+		return classScreenshot
+	case scanNameRegexp.MatchString(name):
+		return classDocument
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return classPhoto
+	}
+	defer f.Close()
+	config, format, err := image.DecodeConfig(f)
+	if err != nil {
+		return classPhoto
+	}
+	if format == "jpeg" {
+		if _, err := f.Seek(0, io.SeekStart); err != nil || jpegHasExif(f) {
+			return classPhoto
+		}
+	}
+	if isPaperSized(config.Width, config.Height) {
+		return classDocument
+	}
+	if format == "png" {
+		return classScreenshot
+	}
+	return classPhoto
+}
+
+// isPaperSized reports whether an image of the given dimensions is large
+// enough to be a scan and has the aspect ratio of a common paper size.
+func isPaperSized(width, height int) bool {
+	long, short := float64(width), float64(height)
+	if short > long {
+		long, short = short, long
+	}
+	if short < 1000 {
+		return false
+	}
+	for _, ratio := range paperAspectRatios {
+		if math.Abs(long/short-ratio) < 0.02 {
+			return true
+		}
+	}
+	return false
+}
+
+// jpegHasExif reports whether the JPEG stream r contains an EXIF APP1 segment
+// before its image data.
+func jpegHasExif(r io.Reader) bool {
+	_, ok := jpegExifSegment(r)
+	return ok
+}
+
+// jpegExifSegment returns the EXIF APP1 segment of the JPEG stream r, starting
+// with its "Exif\x00\x00" header, and whether there is one.
+func jpegExifSegment(r io.Reader) ([]byte, bool) {
+	br := bufio.NewReader(r)
+	var soi [2]byte
+	if _, err := io.ReadFull(br, soi[:]); err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return nil, false
+	}
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil || header[0] != 0xFF {
+			return nil, false
+		}
+		marker := header[1]
+		// Start of scan: all metadata segments come before it.
+		if marker == 0xDA {
+			return nil, false
+		}
+		length := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if length < 0 {
+			return nil, false
+		}
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(br, segment); err != nil {
+			return nil, false
+		}
+		if marker == 0xE1 && strings.HasPrefix(string(segment), "Exif\x00\x00") {
+			return segment, true
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package organize
+
+import (
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeImage writes a blank image of the given size and format ("jpeg" or
+// "png") to path.
+func writeImage(t *testing.T, path, format string, width, height int) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	if format == "png" {
+		err = png.Encode(f, img)
+	} else {
+		err = jpeg.Encode(f, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		fileName      string
+		format        string
+		width, height int
+		want          mediaClass
+	}{
+		{"Screenshot_20230315-142233.jpg", "jpeg", 100, 200, classScreenshot},
+		{"scan_0001.jpg", "jpeg", 100, 100, classDocument},
+		{"Scanned Document 2.jpg", "jpeg", 100, 100, classDocument},
+		{"receipt-scan.jpg", "jpeg", 100, 100, classDocument},
+		{"Scandinavia_2019.jpg", "jpeg", 640, 480, classPhoto},
+		{"pelican_landscape.jpg", "jpeg", 640, 480, classPhoto},
+		{"20230315_142233.png", "png", 1080, 2400, classScreenshot},
+		{"20230315_142233.jpg", "jpeg", 2480, 3508, classDocument}, // A4 at 300dpi.
+		{"20230315_142233.jpeg", "jpeg", 640, 480, classPhoto},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.fileName)
+		writeImage(t, path, tt.format, tt.width, tt.height)
+		if got := classify(path); got != tt.want {
+			t.Errorf("classify(%q) = %v, want %v", tt.fileName, got, tt.want)
+		}
+	}
+}
+
+func TestJpegHasExif(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plain.jpg")
+	writeImage(t, path, "jpeg", 10, 10)
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if jpegHasExif(f) {
+		t.Error("jpegHasExif reported EXIF data for a plain JPEG")
+	}
+}
@@ -0,0 +1,82 @@
+//go:build !minimal
+// +build !minimal
+
+package organize
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// config is the contents of a config file.
+type config struct {
+	Matchers []matcherConfig `yaml:"matchers" toml:"matchers"`
+}
+
+// matcherConfig declares a custom matcher, as accepted by NewPatternMatcher.
+type matcherConfig struct {
+	Name    string `yaml:"name" toml:"name"`
+	Pattern string `yaml:"pattern" toml:"pattern"`
+	Layout  string `yaml:"layout" toml:"layout"`
+	Rename  string `yaml:"rename" toml:"rename"`
+}
+
+// LoadMatchers reads the custom matchers declared in the YAML (.yaml, .yml) or
+// TOML (.toml) config file at path, in the order they are declared. Each
+// matcher has a pattern and, optionally, a name and a layout, which are
+// interpreted as by NewPatternMatcher, and a rename template, as set by
+// SetRename:
+//
+//	matchers:
+//	  - name: My camera
+//	    pattern: '^CAM(?P<day>\d\d)(?P<month>\d\d)(?P<year>\d{4})'
+func LoadMatchers(path string) ([]*MediaFileMatcher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&c); err != nil {
+			return nil, fmt.Errorf("invalid config file %q: %v", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), &c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config file %q: %v", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("invalid config file %q: unknown key %q", path, undecoded[0].String())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q, want .yaml, .yml or .toml", ext)
+	}
+
+	matchers := make([]*MediaFileMatcher, len(c.Matchers))
+	for i, mc := range c.Matchers {
+		if mc.Pattern == "" {
+			return nil, fmt.Errorf("matcher %d in %q has no pattern", i+1, path)
+		}
+		m, err := NewPatternMatcher(mc.Pattern, mc.Layout)
+		if err != nil {
+			return nil, fmt.Errorf("matcher %d in %q: %v", i+1, path, err)
+		}
+		if mc.Name != "" {
+			m.name = mc.Name
+		}
+		if err := m.SetRename(mc.Rename); err != nil {
+			return nil, fmt.Errorf("matcher %d in %q: %v", i+1, path, err)
+		}
+		matchers[i] = m
+	}
+	return matchers, nil
+}
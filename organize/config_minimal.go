@@ -0,0 +1,12 @@
+//go:build minimal
+// +build minimal
+
+package organize
+
+import "errors"
+
+// LoadMatchers would read custom matchers from a config file, but YAML and
+// TOML support is left out of minimal builds.
+func LoadMatchers(path string) ([]*MediaFileMatcher, error) {
+	return nil, errors.New("config files are not supported by minimal builds")
+}
@@ -0,0 +1,85 @@
+//go:build !minimal
+// +build !minimal
+
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMatchers(t *testing.T) {
+	tests := []struct {
+		fileName string
+		contents string
+	}{
+		{"config.yaml", `
+matchers:
+  - name: My camera
+    pattern: '^CAM(?P<day>\d\d)(?P<month>\d\d)(?P<year>\d{4})'
+  - pattern: '^SCAN_(\d{8})'
+    layout: '20060102'
+`},
+		{"config.toml", `
+[[matchers]]
+name = "My camera"
+pattern = '^CAM(?P<day>\d\d)(?P<month>\d\d)(?P<year>\d{4})'
+
+[[matchers]]
+pattern = '^SCAN_(\d{8})'
+layout = "20060102"
+`},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.fileName)
+		if err := os.WriteFile(path, []byte(tt.contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		matchers, err := LoadMatchers(path)
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		if len(matchers) != 2 {
+			t.Fatalf("got %d matchers from %s, want 2", len(matchers), tt.fileName)
+		}
+		if got, want := matchers[0].name, "My camera"; got != want {
+			t.Errorf("got name %q, want %q", got, want)
+		}
+		for _, name := range []string{"CAM22022021.jpg", "SCAN_20210222.tif"} {
+			date, err := getDate(matchers, name)
+			if err != nil {
+				t.Errorf("Expected no error but received: %s", err)
+				continue
+			}
+			if got, want := date.Format("2006-01-02"), "2021-02-22"; got != want {
+				t.Errorf("%s: got %s, want %s", name, got, want)
+			}
+		}
+	}
+}
+
+func TestLoadMatchersInvalid(t *testing.T) {
+	tests := []struct {
+		fileName string
+		contents string
+	}{
+		{"config.json", `{}`},
+		{"config.yaml", "matchers:\n  - patern: 'x'\n"},
+		{"config.yaml", "matchers:\n  - name: no pattern\n"},
+		{"config.yaml", "matchers:\n  - pattern: '^CAM(\\d{8})'\n"},
+		{"config.toml", "[[matchers]]\npattern = '(?P<year>'\n"},
+		{"config.toml", "[[matchers]]\npatern = 'x'\n"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.fileName)
+		if err := os.WriteFile(path, []byte(tt.contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadMatchers(path); err == nil {
+			t.Errorf("Expected error but received none for %s: %q", tt.fileName, tt.contents)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package organize
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConflictPolicy selects what happens when a file is to be moved onto an
+// existing file with different contents. Identical files are handled as
+// duplicates regardless.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves the file in place and reports it.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictRename moves the file under its name with the first free
+	// numeric suffix, as in IMG_0001_1.JPG, so that distinct files of the
+	// same name (e.g. from two cameras) can coexist.
+	ConflictRename ConflictPolicy = "rename"
+	// ConflictOverwrite replaces the existing file.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictFail makes organizing fail before any file is moved.
+	ConflictFail ConflictPolicy = "fail"
+)
+
+// String implements flag.Value.
+func (p *ConflictPolicy) String() string {
+	return string(*p)
+}
+
+// Set implements flag.Value.
+func (p *ConflictPolicy) Set(s string) error {
+	switch policy := ConflictPolicy(s); policy {
+	case ConflictSkip, ConflictRename, ConflictOverwrite, ConflictFail:
+		*p = policy
+		return nil
+	}
+	return fmt.Errorf("unknown policy %q, want %q, %q, %q or %q", s, ConflictSkip, ConflictRename, ConflictOverwrite, ConflictFail)
+}
+
+// resolveConflicts applies opts.onConflict to the moves of p whose
+// destination is taken, by an existing file or by an earlier move. With
+// ConflictRename, such moves get the first free name with a numeric suffix;
+// with ConflictFail, an error is returned. Other conflicts are handled when
+// moving.
+func resolveConflicts(p *Plan, opts options) error {
+	if opts.onConflict != ConflictRename && opts.onConflict != ConflictFail {
+		return nil
+	}
+	taken := make(map[string]bool)
+	isTaken := func(path string) bool {
+		_, err := os.Lstat(path)
+		return err == nil || taken[path]
+	}
+	for i := range p.Moves {
+		m := &p.Moves[i]
+		if m.Archived != "" {
+			continue
+		}
+		name := m.Name
+		if name == "" {
+			name = filepath.Base(m.Src)
+		}
+		if isTaken(filepath.Join(m.DestDir, name)) {
+			if opts.onConflict == ConflictFail {
+				return fmt.Errorf("unable to move %q: %q already exists in %q", m.Src, name, m.DestDir)
+			}
+			ext := filepath.Ext(name)
+			stem := strings.TrimSuffix(name, ext)
+			for n := 1; isTaken(filepath.Join(m.DestDir, name)); n++ {
+				name = stem + "_" + strconv.Itoa(n) + ext
+			}
+			log.Printf("%q is taken in %q, moving %q as %q", filepath.Base(m.Src), m.DestDir, m.Src, name)
+			m.Name = name
+		}
+		taken[filepath.Join(m.DestDir, name)] = true
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConflictTree creates, in a temporary directory, two different files
+// of the same name from two cameras and a different file of that name already
+// organized, and returns the directory.
+func writeConflictTree(t *testing.T) string {
+	dir := t.TempDir()
+	for path, contents := range map[string]string{
+		"a/IMG_20210222_213525.jpg":          "camera a",
+		"b/IMG_20210222_213525.jpg":          "camera b",
+		"2021-02-22/IMG_20210222_213525.jpg": "archived",
+	} {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestResolveConflictsRename(t *testing.T) {
+	dir := writeConflictTree(t)
+	opts := options{matchers: mediaMatchers, recursive: true, skipDatedDirs: true, onConflict: ConflictRename}
+	p, err := planOrganize(dir, opts)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	want := []string{"IMG_20210222_213525_1.jpg", "IMG_20210222_213525_2.jpg"}
+	if len(p.Moves) != len(want) {
+		t.Fatalf("got %d moves, want %d", len(p.Moves), len(want))
+	}
+	for i, m := range p.Moves {
+		if m.Name != want[i] {
+			t.Errorf("got %s, want %s (file: %s)", m.Name, want[i], m.Src)
+		}
+	}
+
+	executePlan(dir, p, opts)
+	for _, name := range append(want, "IMG_20210222_213525.jpg") {
+		if _, err := os.Stat(filepath.Join(dir, "2021-02-22", name)); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+}
+
+func TestResolveConflictsFail(t *testing.T) {
+	dir := writeConflictTree(t)
+	opts := options{matchers: mediaMatchers, recursive: true, skipDatedDirs: true, onConflict: ConflictFail}
+	if _, err := planOrganize(dir, opts); err == nil {
+		t.Error("Expected error but received none")
+	}
+}
+
+func TestMoveOverwrite(t *testing.T) {
+	dir := writeConflictTree(t)
+	src := filepath.Join(dir, "a", "IMG_20210222_213525.jpg")
+	destDir := filepath.Join(dir, "2021-02-22")
+	if !moveIntoDir(src, destDir, options{onConflict: ConflictOverwrite}) {
+		t.Fatal("got file not moved, want it to overwrite the existing one")
+	}
+	got, err := os.ReadFile(filepath.Join(destDir, "IMG_20210222_213525.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "camera a" {
+		t.Errorf("got %q, want %q", got, "camera a")
+	}
+}
+
+func TestNewOverwriteProtectDest(t *testing.T) {
+	if _, err := New(WithOnConflict(ConflictOverwrite), WithProtectDest(true)); err == nil {
+		t.Error("Expected error but received none")
+	}
+}
@@ -0,0 +1,94 @@
+package organize
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// CopySuffixPolicy selects how files with the " (N)" suffix added by exports
+// to avoid name clashes, such as "IMG_0001 (1).jpeg", are handled.
+type CopySuffixPolicy string
+
+const (
+	// CopySuffixKeep moves such files like any other, under their own name.
+	CopySuffixKeep CopySuffixPolicy = "keep"
+	// CopySuffixCollapse removes such files instead of moving them if they
+	// are identical to the file with the base name at the destination.
+	CopySuffixCollapse CopySuffixPolicy = "collapse"
+	// CopySuffixRename collapses identical copies like CopySuffixCollapse and
+	// moves the others under the base name if that is free.
+	CopySuffixRename CopySuffixPolicy = "rename"
+)
+
+// copySuffixRegexp matches file names with a " (N)" duplicate suffix before
+// the extension.
+var copySuffixRegexp = regexp.MustCompile(`^(.+) \(\d+\)(\.[^.]+)$`)
+
+// String implements flag.Value.
+func (p *CopySuffixPolicy) String() string {
+	return string(*p)
+}
+
+// Set implements flag.Value.
+func (p *CopySuffixPolicy) Set(s string) error {
+	switch policy := CopySuffixPolicy(s); policy {
+	case CopySuffixKeep, CopySuffixCollapse, CopySuffixRename:
+		*p = policy
+		return nil
+	}
+	return fmt.Errorf("unknown policy %q, want %q, %q or %q", s, CopySuffixKeep, CopySuffixCollapse, CopySuffixRename)
+}
+
+// copySuffixBase returns the name fileName would have without its " (N)"
+// duplicate suffix, and whether it has one.
+func copySuffixBase(fileName string) (string, bool) {
+	m := copySuffixRegexp.FindStringSubmatch(fileName)
+	if m == nil {
+		return fileName, false
+	}
+	return m[1] + m[2], true
+}
+
+// resolveCopySuffix applies opts.copySuffix to the file at srcPath, with the
+// given sidecars, about to be moved into destDir as fileName: its own name, or
+// the one it was given when planning (e.g. by a rename template keeping the
+// original name). It returns the name to move the file under, or false if the
+// file was an identical copy of the one with the base name in destDir and has
+// been removed. Files with sidecars are never removed, like other duplicates.
+func resolveCopySuffix(srcPath, fileName, destDir string, sidecars []string, opts options) (string, bool) {
+	if opts.copySuffix == "" || opts.copySuffix == CopySuffixKeep {
+		return fileName, true
+	}
+	base, ok := copySuffixBase(fileName)
+	if !ok {
+		return fileName, true
+	}
+	basePath := filepath.Join(destDir, base)
+	if _, err := os.Stat(basePath); err == nil {
+		if len(sidecars) > 0 {
+			return fileName, true
+		}
+		err := removeDuplicate(srcPath, basePath, opts)
+		if err == nil {
+			return "", false
+		}
+		if err != errDiffers {
+			log.Printf("unable to collapse %q into %q: %v", srcPath, basePath, err)
+		}
+		return fileName, true
+	}
+	if opts.copySuffix != CopySuffixRename {
+		return fileName, true
+	}
+	// The file with the base name may still be waiting to be moved, in which
+	// case it keeps the base name for itself.
+	if srcBase, ok := copySuffixBase(filepath.Base(srcPath)); ok {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(srcPath), srcBase)); err == nil {
+			return fileName, true
+		}
+	}
+	return base, true
+}
@@ -0,0 +1,177 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCopySuffixBase(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     string
+		wantOK   bool
+	}{
+		{"IMG_0001 (1).jpeg", "IMG_0001.jpeg", true},
+		{"IMG_0001 (12).HEIC", "IMG_0001.HEIC", true},
+		{"IMG_0001.jpeg", "IMG_0001.jpeg", false},
+		{"IMG_0001(1).jpeg", "IMG_0001(1).jpeg", false},
+		{" (1).jpeg", " (1).jpeg", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := copySuffixBase(tt.fileName)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("copySuffixBase(%q) = %q, %v, want %q, %v", tt.fileName, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestResolveCopySuffix(t *testing.T) {
+	tests := []struct {
+		policy      CopySuffixPolicy
+		destContent string // contents of IMG_0001.jpeg at the destination, if any
+		wantName    string
+		wantOK      bool
+	}{
+		{CopySuffixKeep, "photo", "IMG_0001 (1).jpeg", true},
+		{CopySuffixCollapse, "photo", "", false},
+		{CopySuffixCollapse, "other", "IMG_0001 (1).jpeg", true},
+		{CopySuffixCollapse, "", "IMG_0001 (1).jpeg", true},
+		{CopySuffixRename, "photo", "", false},
+		{CopySuffixRename, "other", "IMG_0001 (1).jpeg", true},
+		{CopySuffixRename, "", "IMG_0001.jpeg", true},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "IMG_0001 (1).jpeg")
+		destDir := filepath.Join(dir, "2021-02-22")
+		if err := os.WriteFile(src, []byte("photo"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Mkdir(destDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if tt.destContent != "" {
+			if err := os.WriteFile(filepath.Join(destDir, "IMG_0001.jpeg"), []byte(tt.destContent), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		name, ok := resolveCopySuffix(src, filepath.Base(src), destDir, nil, options{copySuffix: tt.policy})
+		if name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("%s with %q at the destination: got %q, %v, want %q, %v", tt.policy, tt.destContent, name, ok, tt.wantName, tt.wantOK)
+		}
+		if _, err := os.Stat(src); ok == os.IsNotExist(err) {
+			t.Errorf("%s with %q at the destination: source exists = %v, want %v", tt.policy, tt.destContent, err == nil, ok)
+		}
+	}
+}
+
+func TestResolveCopySuffixBaseInSource(t *testing.T) {
+	dir := t.TempDir()
+	destDir := filepath.Join(dir, "2021-02-22")
+	for _, name := range []string{"IMG_0001.jpeg", "IMG_0001 (1).jpeg"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	name, ok := resolveCopySuffix(filepath.Join(dir, "IMG_0001 (1).jpeg"), "IMG_0001 (1).jpeg", destDir, nil, options{copySuffix: CopySuffixRename})
+	if name != "IMG_0001 (1).jpeg" || !ok {
+		t.Errorf("got %q, %v, want %q, true", name, ok, "IMG_0001 (1).jpeg")
+	}
+}
+
+func TestResolveCopySuffixRemoval(t *testing.T) {
+	for _, withSidecar := range []bool{false, true} {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "IMG_0001 (1).jpeg")
+		destDir := filepath.Join(dir, "2021-02-22")
+		journal := filepath.Join(dir, "journal.jsonl")
+		if err := os.Mkdir(destDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range []string{src, filepath.Join(destDir, "IMG_0001.jpeg")} {
+			if err := os.WriteFile(path, []byte("photo"), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var sidecars []string
+		if withSidecar {
+			sidecars = []string{filepath.Join(dir, "IMG_0001 (1).jpeg.xmp")}
+		}
+
+		name, ok := resolveCopySuffix(src, filepath.Base(src), destDir, sidecars, options{copySuffix: CopySuffixCollapse, journal: journal})
+		entries, err := ReadJournal(journal)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		if withSidecar {
+			if name != "IMG_0001 (1).jpeg" || !ok {
+				t.Errorf("with a sidecar: got %q, %v, want %q, true", name, ok, "IMG_0001 (1).jpeg")
+			}
+			if len(entries) != 0 {
+				t.Errorf("with a sidecar: got %d journal entries, want 0", len(entries))
+			}
+			continue
+		}
+		if ok {
+			t.Errorf("got %q, %v, want removal", name, ok)
+		}
+		if len(entries) != 1 || entries[0].Src != src || entries[0].Op != RemoveDelete || entries[0].Hash == "" {
+			t.Errorf("got journal entries %+v, want the deletion of %q with its hash", entries, src)
+		}
+	}
+}
+
+func TestOrganizeCopySuffixRenamed(t *testing.T) {
+	m, err := NewPatternMatcher(`^IMG_(\d{8})_`, "20060102")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetRename(`{{.Date}} {{.Name}}{{.Ext}}`); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	destDir := filepath.Join(dir, "2021-02-22")
+	for name, content := range map[string]string{
+		"IMG_20210222_0001 (1).jpeg":                   "a",
+		"IMG_20210222_0002 (1).jpeg":                   "b",
+		"2021-02-22/2021-02-22 IMG_20210222_0002.jpeg": "b",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithMatchers(m), WithCopySuffix(CopySuffixRename), WithExternalTools(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Organize(dir); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	entries, err := os.ReadDir(destDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	// The renamed copy loses its suffix, and the identical one is collapsed.
+	want := []string{"2021-02-22 IMG_20210222_0001.jpeg", "2021-02-22 IMG_20210222_0002.jpeg"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "IMG_20210222_0002 (1).jpeg")); !os.IsNotExist(err) {
+		t.Errorf("got %v, want the identical copy removed", err)
+	}
+}
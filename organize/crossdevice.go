@@ -0,0 +1,108 @@
+package organize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// copyBufferSize is the size of the buffer files are copied across
+	// devices with, whose large reads and writes suit network file systems.
+	copyBufferSize = 1 << 20
+	// minCopyBufferSize is the smallest such buffer, however little memory
+	// the run is allowed.
+	minCopyBufferSize = 32 << 10
+)
+
+// copyBufferSizeFor returns the size of the copy buffer of each worker with
+// opts: copyBufferSize, or less if opts.maxMemory is too small for the
+// workers to use it without running short.
+func copyBufferSizeFor(opts options) int {
+	size := int64(copyBufferSize)
+	if opts.maxMemory > 0 {
+		if n := opts.maxMemory / 16 / int64(parallelism(opts)); n < size {
+			size = n
+		}
+		if size < minCopyBufferSize {
+			size = minCopyBufferSize
+		}
+	}
+	return int(size)
+}
+
+// copyAcrossDevices copies the file at srcPath to destFilePath, on another
+// file system, for moves that can't be done by renaming or linking. The copy
+// is written to a temporary file next to destFilePath, synced to disk and
+// verified against the hash of the source before it is put in place, so a
+// partial copy never appears at destFilePath. If noClobber is set, an
+// existing file at destFilePath is never replaced. The modification time of
+// the source is kept, as duplicates are recognized by it. If wantHash is not
+// empty, the copy must also have that hash, as computed from an earlier read
+// of the source. The file is copied through a buffer of bufSize bytes. The
+// source is left for the caller to remove.
+func copyAcrossDevices(srcPath, destFilePath string, noClobber bool, wantHash string, bufSize int) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(destFilePath), "."+filepath.Base(destFilePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	placed := false
+	defer func() {
+		if !placed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	h := sha256.New()
+	// Hiding the file's ReadFrom makes the copy go through the buffer.
+	if _, err := io.CopyBuffer(struct{ io.Writer }{tmp}, io.TeeReader(src, h), make([]byte, bufSize)); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	copyHash, err := hashFile(tmpPath)
+	if err != nil {
+		return err
+	}
+	if wantHash == "" {
+		wantHash = hex.EncodeToString(h.Sum(nil))
+	}
+	if copyHash != wantHash {
+		return fmt.Errorf("copy of %q is corrupt: hash %s, want %s", srcPath, copyHash, wantHash)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmpPath, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	if noClobber {
+		// Linking fails if the destination exists.
+		if err := os.Link(tmpPath, destFilePath); err != nil && !linkSucceeded(tmpPath, destFilePath) {
+			return err
+		}
+		os.Remove(tmpPath)
+	} else if err := os.Rename(tmpPath, destFilePath); err != nil && !renameSucceeded(tmpPath, destFilePath, info, wantHash) {
+		return err
+	}
+	placed = true
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package organize
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isCrossDevice reports whether err is due to moving or linking a file to
+// another file system.
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
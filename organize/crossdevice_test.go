@@ -0,0 +1,136 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyAcrossDevices(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "IMG_0001.jpg")
+	if err := os.WriteFile(src, []byte("picture"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2021, 2, 22, 21, 35, 25, 0, time.UTC)
+	if err := os.Chtimes(src, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(dir, "2021-02-22", "IMG_0001.jpg")
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyAcrossDevices(src, dest, true, "", copyBufferSize); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if same, err := sameContents(src, dest); err != nil || !same {
+		t.Errorf("got %t, %v, want identical files", same, err)
+	}
+	if info, err := os.Stat(dest); err != nil || !info.ModTime().Equal(modTime) {
+		t.Errorf("got %v, %v, want modification time %s", info, err, modTime)
+	}
+	if err := copyAcrossDevices(src, dest, true, "", copyBufferSize); !os.IsExist(err) {
+		t.Errorf("got %v, want the existing file kept", err)
+	}
+	if err := copyAcrossDevices(src, dest, false, "", copyBufferSize); err != nil {
+		t.Errorf("Expected no error but received: %s", err)
+	}
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(filepath.Dir(dest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want 1", len(entries))
+	}
+}
+
+func TestVerifyMove(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "IMG_0001.jpg")
+	dest := filepath.Join(dir, "IMG_0001-moved.jpg")
+	if err := os.WriteFile(dest, []byte("picture"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hashFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verifyMove(src, dest, hash, options{}) {
+		t.Errorf("got the move of an intact file reverted")
+	}
+
+	// A file damaged in transit is moved back.
+	if err := os.WriteFile(dest, []byte("picturf"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if verifyMove(src, dest, hash, options{}) {
+		t.Errorf("got the move of a damaged file verified")
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("Expected no error but received: %s", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("got %v, want the damaged file moved back", err)
+	}
+}
+
+func TestVerifyMoveCopied(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "IMG_0001.jpg")
+	dest := filepath.Join(dir, "IMG_0001-copied.jpg")
+	if err := os.WriteFile(src, []byte("picture"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hashFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The copy of a file, as made across file systems, is damaged: the
+	// source is kept and the copy removed, as moving it back could fail.
+	if err := os.WriteFile(dest, []byte("picturf"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if verifyMove(src, dest, hash, options{}) {
+		t.Errorf("got the move of a damaged file verified")
+	}
+	if got, err := os.ReadFile(src); err != nil || string(got) != "picture" {
+		t.Errorf("got %q (%v), want the source kept intact", got, err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("got %v, want the damaged copy removed", err)
+	}
+
+	// An intact copy has its source removed once verified.
+	if err := copyAcrossDevices(src, dest, true, hash, copyBufferSize); err != nil {
+		t.Fatal(err)
+	}
+	if !verifyMove(src, dest, hash, options{}) {
+		t.Errorf("got the move of an intact file reverted")
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("got %v, want the source removed", err)
+	}
+}
+
+func TestCopyBufferSizeFor(t *testing.T) {
+	tests := []struct {
+		workers   int
+		maxMemory int64
+		want      int
+	}{
+		{4, 0, copyBufferSize},
+		{4, 1 << 30, copyBufferSize},
+		{1, 8 << 20, 512 << 10},
+		{1, 256 << 10, minCopyBufferSize},
+	}
+
+	for _, tt := range tests {
+		if got := copyBufferSizeFor(options{workers: tt.workers, maxMemory: tt.maxMemory}); got != tt.want {
+			t.Errorf("got %d, want %d (workers: %d, max memory: %d)", got, tt.want, tt.workers, tt.maxMemory)
+		}
+	}
+}
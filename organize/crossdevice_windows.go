@@ -0,0 +1,16 @@
+package organize
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errorNotSameDevice is ERROR_NOT_SAME_DEVICE, returned when moving a file to
+// another volume.
+const errorNotSameDevice = syscall.Errno(17)
+
+// isCrossDevice reports whether err is due to moving or linking a file to
+// another file system.
+func isCrossDevice(err error) bool {
+	return errors.Is(err, errorNotSameDevice)
+}
@@ -0,0 +1,127 @@
+package organize
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	// scanDateRegexp finds candidate dates anywhere in a file name, either as
+	// YYYYMMDD or with separators as in YYYY-MM-DD.
+	scanDateRegexp = regexp.MustCompile(`(\d{4})([-_.]?)(\d{2})([-_.]?)(\d{2})`)
+	// cameraPrefixRegexp matches the prefixes devices put right before the
+	// date in file names, which make a date much more likely to be genuine.
+	cameraPrefixRegexp = regexp.MustCompile(`(?i)(IMG|VID|PXL|DSC|MVIMG|PANO|Screenshot)[-_]$`)
+)
+
+const (
+	// minScanYear is the earliest year accepted by scanDate: digital cameras
+	// were rare before, and earlier "dates" are usually other numbers.
+	minScanYear = 1990
+	// minDateScore is the minimum confidence score for scanDate to accept a
+	// date.
+	minDateScore = 50
+)
+
+// dateCandidate is a plausible date found in a file name.
+type dateCandidate struct {
+	date time.Time
+	// start and end delimit the date in the file name.
+	start, end int
+	// separated is set for dates written with separators, as in YYYY-MM-DD.
+	separated bool
+}
+
+// findDateCandidates returns the plausible dates in fileName, in the order
+// they appear. Dates must be valid calendar dates between minScanYear and
+// today and must not be part of a longer number.
+func findDateCandidates(fileName string) []dateCandidate {
+	var candidates []dateCandidate
+	for _, m := range scanDateRegexp.FindAllStringSubmatchIndex(fileName, -1) {
+		start, end := m[0], m[1]
+		// Skip digits that are part of a longer number.
+		if start > 0 && isDigit(fileName[start-1]) || end < len(fileName) && isDigit(fileName[end]) {
+			continue
+		}
+		sep1, sep2 := fileName[m[4]:m[5]], fileName[m[8]:m[9]]
+		if sep1 != sep2 {
+			continue
+		}
+		year, _ := strconv.Atoi(fileName[m[2]:m[3]])
+		month, _ := strconv.Atoi(fileName[m[6]:m[7]])
+		day, _ := strconv.Atoi(fileName[m[10]:m[11]])
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if date.Year() != year || int(date.Month()) != month || date.Day() != day {
+			continue
+		}
+		if year < minScanYear || date.After(time.Now()) {
+			continue
+		}
+		candidates = append(candidates, dateCandidate{date, start, end, sep1 != ""})
+	}
+	return candidates
+}
+
+// distinctDates returns the number of different dates among candidates.
+func distinctDates(candidates []dateCandidate) int {
+	dates := make(map[time.Time]bool)
+	for _, c := range candidates {
+		dates[c.date] = true
+	}
+	return len(dates)
+}
+
+// scanDate looks for a plausible date anywhere in fileName, for names that
+// prefix-anchored matchers miss such as "backup-IMG_20230315-final(2).jpg".
+// Candidates are scored by how likely they are to be a genuine capture date;
+// the best candidate is returned with its score (0-100) if that reaches
+// minDateScore.
+func scanDate(fileName string) (time.Time, int, error) {
+	if !IsMedia(fileName) {
+		return time.Time{}, 0, fmt.Errorf("%q is not a media file name", fileName)
+	}
+	candidates := findDateCandidates(fileName)
+	if len(candidates) == 0 {
+		return time.Time{}, 0, fmt.Errorf("no plausible date found in %q", fileName)
+	}
+	var (
+		best      time.Time
+		bestScore = -1
+	)
+	for _, c := range candidates {
+		score := 40
+		if c.separated {
+			score += 20
+		}
+		if (c.start == 0 || !isAlphanumeric(fileName[c.start-1])) && (c.end == len(fileName) || !isAlphanumeric(fileName[c.end])) {
+			score += 10
+		}
+		if cameraPrefixRegexp.MatchString(fileName[:c.start]) {
+			score += 20
+		}
+		if score > bestScore {
+			best, bestScore = c.date, score
+		}
+	}
+	// Several different dates make it unclear which one is meant.
+	if distinctDates(candidates) > 1 {
+		bestScore -= 20
+	}
+	if bestScore < minDateScore {
+		return time.Time{}, bestScore, fmt.Errorf("date %s found in %q has low confidence (%d%%)", best.Format("2006-01-02"), fileName, bestScore)
+	}
+	if bestScore > 100 {
+		bestScore = 100
+	}
+	return best, bestScore, nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isAlphanumeric(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,36 @@
+package organize
+
+import "testing"
+
+func TestScanDate(t *testing.T) {
+	tests := []struct {
+		fileName           string
+		expectedFolderName string
+		errExpected        bool
+	}{
+		{"backup-IMG_20230315-final(2).jpg", "2023-03-15", false},
+		{"holiday 2019-07-17 beach.JPG", "2019-07-17", false},
+		{"export_2021.02.22_edited.mp4", "2021-02-22", false},
+		{"WhatsApp Video 2020_10_12.mov", "2020-10-12", false},
+		{"IMG_20211341_x.jpg", "", true},     // Not a calendar date.
+		{"scan-18991231.jpg", "", true},      // Implausibly early.
+		{"id123456789012.jpg", "", true},     // Part of a longer number.
+		{"2019-07_17.jpg", "", true},         // Inconsistent separators.
+		{"abc20230315def.jpg", "", true},     // Embedded in a word.
+		{"20190717-20200101.jpg", "", true},  // Ambiguous.
+		{"holiday 2019-07-17.txt", "", true}, // Not a media file.
+	}
+
+	for _, tt := range tests {
+		date, score, err := scanDate(tt.fileName)
+		if err != nil && !tt.errExpected {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+		if tt.errExpected && err == nil {
+			t.Errorf("Expected error but received none (file name: %s, score %d)", tt.fileName, score)
+		}
+		if err == nil && date.Format("2006-01-02") != tt.expectedFolderName {
+			t.Errorf("got %s, want %s (file name: %s)", date.Format("2006-01-02"), tt.expectedFolderName, tt.fileName)
+		}
+	}
+}
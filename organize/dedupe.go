@@ -0,0 +1,247 @@
+package organize
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DuplicatePolicy selects what happens to files whose identical copy is
+// already archived at the destination.
+type DuplicatePolicy string
+
+const (
+	// DuplicateSkip leaves such files in place.
+	DuplicateSkip DuplicatePolicy = "skip"
+	// DuplicateDelete removes such files, as they are safely stored already.
+	// Files with sidecars are left in place all the same.
+	DuplicateDelete DuplicatePolicy = "delete"
+)
+
+// String implements flag.Value.
+func (p *DuplicatePolicy) String() string {
+	return string(*p)
+}
+
+// Set implements flag.Value.
+func (p *DuplicatePolicy) Set(s string) error {
+	switch policy := DuplicatePolicy(s); policy {
+	case DuplicateSkip, DuplicateDelete:
+		*p = policy
+		return nil
+	}
+	return fmt.Errorf("unknown policy %q, want %q or %q", s, DuplicateSkip, DuplicateDelete)
+}
+
+// errDiffers is returned by removeDuplicate for a file that turns out not to
+// be identical to its supposed copy.
+var errDiffers = errors.New("different contents")
+
+// handleDuplicate applies opts.duplicates to the file at srcPath, whose
+// identical copy is archived at archived, and counts it as a duplicate.
+func handleDuplicate(srcPath, archived string, sidecars []string, opts options) {
+	if opts.duplicates != DuplicateDelete || len(sidecars) > 0 {
+		opts.nearMisses.duplicate()
+		opts.report.add(srcPath, StatusSkipped, archived, "identical copy already archived")
+		return
+	}
+	err := removeDuplicate(srcPath, archived, opts)
+	if err == errDiffers {
+		log.Printf("Not removing %q, which differs from %q", srcPath, archived)
+		opts.report.add(srcPath, StatusConflict, archived, "destination file differs")
+	} else if err != nil {
+		log.Printf("unable to remove duplicate %q: %v", srcPath, err)
+		opts.report.add(srcPath, StatusError, archived, err.Error())
+	}
+}
+
+// removeDuplicate removes the file at srcPath, whose identical copy is
+// archived at archived, counting it as a duplicate and recording the removal
+// in the journal, if any. Files of the same name may have been taken for
+// copies by their size and modification time alone, so nothing is removed
+// without comparing hashes; errDiffers is returned if they differ.
+func removeDuplicate(srcPath, archived string, opts options) error {
+	same, err := sameContents(srcPath, archived)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return errDiffers
+	}
+	if opts.dryRun {
+		log.Printf("Would remove %q, identical to %q", srcPath, archived)
+	} else {
+		if err := recordRemoval(srcPath, "identical copy already archived", opts); err != nil {
+			return err
+		}
+		log.Printf("Removed %q, identical to %q", srcPath, archived)
+	}
+	opts.nearMisses.duplicate()
+	opts.report.add(srcPath, StatusRemoved, archived, "identical copy already archived")
+	return nil
+}
+
+// recordRemoval removes the file at srcPath and, if opts.journal is set,
+// records it there with its hash and reason, like Remove does.
+func recordRemoval(srcPath, reason string, opts options) error {
+	if opts.journal == "" {
+		return os.Remove(srcPath)
+	}
+	hash, err := hashFile(srcPath)
+	if err != nil {
+		return err
+	}
+	src, err := filepath.Abs(srcPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(srcPath); err != nil {
+		return err
+	}
+	runMu.Lock()
+	err = appendJournal(JournalEntry{Src: src, Time: time.Now(), Hash: hash, Op: RemoveDelete, Reason: reason}, opts)
+	runMu.Unlock()
+	if err != nil {
+		log.Printf("unable to record the removal of %q in the journal: %v", srcPath, err)
+	}
+	return nil
+}
+
+// findArchivedCopy looks in destDir for a file with the same contents as the
+// file at srcPath, whose directory entry is src. It returns the path of such a copy, or
+// an empty string if there is none. Only files of the same size are compared,
+// so directories without likely duplicates are cheap to check. Unless
+// paranoid is set, a file of the same name, size and modification time is
+// assumed to be the copy without hashing them, which keeps reruns over large
+// archives fast; files of other names are always compared by hash.
+func findArchivedCopy(srcPath string, src fs.DirEntry, destDir string, paranoid bool) (string, error) {
+	entries, err := os.ReadDir(destDir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var (
+		srcInfo os.FileInfo
+		srcHash string
+	)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		file, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+		if srcInfo == nil {
+			if srcInfo, err = src.Info(); err != nil {
+				return "", err
+			}
+		}
+		if file.Size() != srcInfo.Size() {
+			continue
+		}
+		destPath := filepath.Join(destDir, file.Name())
+		if !paranoid && file.Name() == src.Name() && file.ModTime().Equal(srcInfo.ModTime()) {
+			return destPath, nil
+		}
+		if srcHash == "" {
+			if srcHash, err = hashFile(srcPath); err != nil {
+				return "", err
+			}
+		}
+		destHash, err := hashFile(destPath)
+		if err != nil {
+			return "", err
+		}
+		if destHash == srcHash {
+			return destPath, nil
+		}
+	}
+	return "", nil
+}
+
+// sameContents reports whether the files at a and b have identical contents.
+// Files of different sizes differ; others are compared by hash.
+func sameContents(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+	hashA, err := hashFile(a)
+	if err != nil {
+		return false, err
+	}
+	hashB, err := hashFile(b)
+	if err != nil {
+		return false, err
+	}
+	return hashA == hashB, nil
+}
+
+// FindDuplicates returns the groups of media files of the organized tree at
+// root that have identical contents, each sorted by path. Hidden directories
+// and the Recent and Views directories, which only hold links, are not
+// searched. Files are only hashed if another file has the same size.
+func FindDuplicates(root string) ([][]string, error) {
+	bySize := make(map[int64][]string)
+	err := fs.WalkDir(os.DirFS(root), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && (strings.HasPrefix(d.Name(), ".") || p == RecentDirName || p == ViewsDirName) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !IsMedia(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], filepath.Join(root, filepath.FromSlash(p)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var groups [][]string
+	for _, paths := range bySize {
+		if len(paths) < 2 {
+			continue
+		}
+		byHash := make(map[string][]string)
+		for _, p := range paths {
+			hash, err := hashFile(p)
+			if err != nil {
+				return nil, err
+			}
+			byHash[hash] = append(byHash[hash], p)
+		}
+		for _, group := range byHash {
+			if len(group) > 1 {
+				sort.Strings(group)
+				groups = append(groups, group)
+			}
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups, nil
+}
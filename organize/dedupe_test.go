@@ -0,0 +1,205 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlanMarksArchivedFiles(t *testing.T) {
+	dir := t.TempDir()
+	destDir := filepath.Join(dir, "2021-02-22")
+	if err := os.Mkdir(destDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(dir, "IMG_20210222_213525.jpg"):     "photo",
+		filepath.Join(dir, "IMG_20210222_213526.jpg"):     "other",
+		filepath.Join(destDir, "IMG_20210222_213525.jpg"): "photo",
+		filepath.Join(destDir, "renamed.jpg"):             "OTHER",
+	}
+	for path, contents := range files {
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := planOrganize(dir, options{matchers: mediaMatchers})
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if len(p.Moves) != 2 {
+		t.Fatalf("got %d planned moves, want 2", len(p.Moves))
+	}
+	if got, want := p.Moves[0].Archived, filepath.Join(destDir, "IMG_20210222_213525.jpg"); got != want {
+		t.Errorf("got archived copy %q, want %q", got, want)
+	}
+	if got := p.Moves[1].Archived; got != "" {
+		t.Errorf("got archived copy %q for a file with different contents, want none", got)
+	}
+
+	moved := executePlan(dir, p, options{})
+	if moved[0] || !moved[1] {
+		t.Errorf("got moved %v, want [false true]", moved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "IMG_20210222_213525.jpg")); err != nil {
+		t.Errorf("archived file was not left in place: %v", err)
+	}
+}
+
+func TestSameContents(t *testing.T) {
+	mtime := time.Date(2021, 2, 22, 21, 35, 25, 0, time.UTC)
+	tests := []struct {
+		contentsB string
+		mtimeB    time.Time
+		want      bool
+	}{
+		{"photo", mtime, true},
+		{"photo", mtime.Add(time.Hour), true},
+		{"other", mtime.Add(time.Hour), false},
+		{"longer", mtime, false},
+		// Same size and modification time are no proof of identity.
+		{"other", mtime, false},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		a, b := filepath.Join(dir, "a.jpg"), filepath.Join(dir, "b.jpg")
+		for path, contents := range map[string]string{a: "photo", b: tt.contentsB} {
+			if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.Chtimes(a, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(b, tt.mtimeB, tt.mtimeB); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := sameContents(a, b)
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		if got != tt.want {
+			t.Errorf("sameContents with %q modified at %s = %v, want %v", tt.contentsB, tt.mtimeB, got, tt.want)
+		}
+	}
+}
+
+func TestOrganizeKeepsLookalikes(t *testing.T) {
+	// Burst shots and uncompressed RAWs often share their size, and FAT
+	// timestamps their modification time, without being copies.
+	mtime := time.Date(2021, 2, 22, 21, 35, 24, 0, time.UTC)
+	dir := t.TempDir()
+	files := map[string]string{
+		"IMG_20210222_213525.jpg":            "burst 1",
+		"IMG_20210222_213526 (1).jpg":        "burst 2",
+		"2021-02-22/IMG_20210222_213524.jpg": "burst 0",
+		"2021-02-22/IMG_20210222_213526.jpg": "burst 3",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithExternalTools(false), WithDuplicates(DuplicateDelete), WithCopySuffix(CopySuffixCollapse))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Organize(dir); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	for _, name := range []string{"2021-02-22/IMG_20210222_213525.jpg", "2021-02-22/IMG_20210222_213526 (1).jpg", "2021-02-22/IMG_20210222_213524.jpg", "2021-02-22/IMG_20210222_213526.jpg"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+}
+
+func TestMoveOntoDuplicate(t *testing.T) {
+	tests := []struct {
+		policy        DuplicatePolicy
+		destContents  string
+		wantSrcLeft   bool
+		wantDuplicate bool
+	}{
+		{DuplicateSkip, "photo", true, true},
+		{DuplicateDelete, "photo", false, true},
+		{DuplicateDelete, "other", true, false},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		srcPath := filepath.Join(dir, "IMG_20210222_213525.jpg")
+		destDir := filepath.Join(dir, "2021-02-22")
+		if err := os.Mkdir(destDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(srcPath, []byte("photo"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(destDir, "IMG_20210222_213525.jpg"), []byte(tt.destContents), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		nearMisses := &NearMisses{}
+		if moveIntoDir(srcPath, destDir, options{duplicates: tt.policy, nearMisses: nearMisses}) {
+			t.Errorf("got file moved onto an existing one (policy: %s)", tt.policy)
+		}
+		_, err := os.Stat(srcPath)
+		if left := err == nil; left != tt.wantSrcLeft {
+			t.Errorf("got source left %t, want %t (policy: %s, contents: %s)", left, tt.wantSrcLeft, tt.policy, tt.destContents)
+		}
+		if got := nearMisses.Duplicates == 1; got != tt.wantDuplicate {
+			t.Errorf("got duplicate %t, want %t (policy: %s, contents: %s)", got, tt.wantDuplicate, tt.policy, tt.destContents)
+		}
+		if got := nearMisses.Overwrites == 1; got == tt.wantDuplicate {
+			t.Errorf("got overwrite %t, want %t (policy: %s, contents: %s)", got, !tt.wantDuplicate, tt.policy, tt.destContents)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"2021-02-22/IMG_20210222_213525.jpg": "photo",
+		"2021-02-22/IMG_20210222_213526.jpg": "photo",
+		"2021-02-23/copy.jpg":                "photo",
+		"2021-02-23/other.jpg":               "other",
+		"2021-02-23/notes.txt":               "photo", // Not a media file.
+		".trash/2021-02-22/old.jpg":          "photo", // Hidden.
+		"Recent/IMG_20210222_213525.jpg":     "photo", // Only links.
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := FindDuplicates(root)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	want := [][]string{{
+		filepath.Join(root, "2021-02-22", "IMG_20210222_213525.jpg"),
+		filepath.Join(root, "2021-02-22", "IMG_20210222_213526.jpg"),
+		filepath.Join(root, "2021-02-23", "copy.jpg"),
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
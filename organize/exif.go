@@ -0,0 +1,184 @@
+package organize
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// exifExtensions lists the (lower case) extensions of files whose EXIF
+// metadata is read by exifDate.
+var exifExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true,
+}
+
+// exifDateTag identifies an EXIF date tag and the tag holding its UTC offset.
+type exifDateTag struct {
+	id, offsetID uint16
+}
+
+// exifDateTags maps the date tags of DateTags that exist in EXIF to their
+// tag IDs. The others are specific to QuickTime.
+var exifDateTags = map[string]exifDateTag{
+	"DateTimeOriginal": {0x9003, 0x9011},
+	"CreateDate":       {0x9004, 0x9012},
+	"ModifyDate":       {0x0132, 0x9010},
+}
+
+// exifIFDPointer is the tag of IFD0 pointing to the EXIF sub-IFD.
+const exifIFDPointer = 0x8769
+
+// exifMakerNote is the tag of the EXIF sub-IFD holding the camera maker's
+// proprietary metadata.
+const exifMakerNote = 0x927C
+
+// exifDate reads the capture date of the JPEG file at path from its EXIF
+// metadata, using the first of tags that is present. Dates are in the time
+// zone recorded with them, if any, and in local time otherwise.
+func exifDate(path string, tags []string) (time.Time, error) {
+	values, err := jpegExifValues(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date, ok := exifTagsDate(values, tags); ok {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("no EXIF date in %q", path)
+}
+
+// jpegExifValues returns the ASCII values of the EXIF metadata of the JPEG
+// file at path, by tag.
+func jpegExifValues(path string) (map[uint16]string, error) {
+	if !exifExtensions[strings.ToLower(filepath.Ext(path))] {
+		return nil, fmt.Errorf("%q is not a JPEG file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	segment, ok := jpegExifSegment(f)
+	if !ok {
+		return nil, fmt.Errorf("%q has no EXIF metadata", path)
+	}
+	values, err := exifValues(segment[len("Exif\x00\x00"):])
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXIF metadata in %q: %v", path, err)
+	}
+	return values, nil
+}
+
+// exifMetadata returns the ASCII values of the EXIF metadata of the JPEG,
+// HEIF or RAW file at path, by tag.
+func exifMetadata(path string) (map[uint16]string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch {
+	case heifExtensions[ext]:
+		return heifExifValues(path)
+	case rawExtensions[ext]:
+		return rawExifValues(path)
+	}
+	return jpegExifValues(path)
+}
+
+// exifTagsDate returns the date of the first of tags that is present in the
+// EXIF values, and whether there was one.
+func exifTagsDate(values map[uint16]string, tags []string) (time.Time, bool) {
+	for _, name := range tags {
+		tag, ok := exifDateTags[name]
+		if !ok {
+			continue
+		}
+		if s, ok := values[tag.id]; ok {
+			if date, err := parseExifDate(s, values[tag.offsetID]); err == nil {
+				return date, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// exifValues returns the ASCII values of IFD0 and the EXIF sub-IFD of the
+// TIFF structure embedded in an EXIF segment, by tag.
+func exifValues(tiff []byte) (map[uint16]string, error) {
+	if len(tiff) < 8 {
+		return nil, errors.New("truncated TIFF header")
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("unknown byte order %q", tiff[:2])
+	}
+	values := make(map[uint16]string)
+	exifIFD, err := readIFD(tiff, order, order.Uint32(tiff[4:]), values)
+	if err != nil {
+		return nil, err
+	}
+	if exifIFD != 0 {
+		if _, err := readIFD(tiff, order, exifIFD, values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// readIFD adds the ASCII values of the IFD at offset in tiff to values, and
+// the raw maker note if present. It returns the offset of the EXIF sub-IFD if
+// the IFD points to one.
+func readIFD(tiff []byte, order binary.ByteOrder, offset uint32, values map[uint16]string) (uint32, error) {
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return 0, fmt.Errorf("IFD offset %d out of range", offset)
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	entries := tiff[offset+2:]
+	if len(entries) < count*12 {
+		return 0, errors.New("truncated IFD")
+	}
+	var exifIFD uint32
+	for i := 0; i < count; i++ {
+		entry := entries[i*12 : (i+1)*12]
+		tag, typ, n := order.Uint16(entry), order.Uint16(entry[2:]), order.Uint32(entry[4:])
+		switch {
+		case tag == exifIFDPointer:
+			exifIFD = order.Uint32(entry[8:])
+		case tag == exifMakerNote && typ == 7: // UNDEFINED
+			start := order.Uint32(entry[8:])
+			if n > 4 && uint64(start)+uint64(n) <= uint64(len(tiff)) {
+				values[tag] = string(tiff[start : start+n])
+			}
+		case typ == 2: // ASCII
+			data := entry[8:12]
+			if n > 4 {
+				start := order.Uint32(entry[8:])
+				if uint64(start)+uint64(n) > uint64(len(tiff)) {
+					continue
+				}
+				data = tiff[start : start+n]
+			}
+			if int(n) < len(data) {
+				data = data[:n]
+			}
+			values[tag] = strings.TrimRight(string(data), "\x00 ")
+		}
+	}
+	return exifIFD, nil
+}
+
+// parseExifDate parses an EXIF date such as "2023:03:15 14:22:33", in the
+// time zone given by offset (e.g. "+02:00") if not empty.
+func parseExifDate(s, offset string) (time.Time, error) {
+	if offset != "" {
+		if date, err := time.Parse("2006:01:02 15:04:05-07:00", s+offset); err == nil {
+			return date, nil
+		}
+	}
+	return time.ParseInLocation("2006:01:02 15:04:05", s, time.Local)
+}
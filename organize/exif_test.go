@@ -0,0 +1,131 @@
+package organize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// tiffEntry is an ASCII entry of a test IFD.
+type tiffEntry struct {
+	tag   uint16
+	value string
+}
+
+// exifJPEG returns a minimal JPEG file whose EXIF segment holds the given
+// IFD0 and EXIF sub-IFD entries, in little endian byte order.
+func exifJPEG(ifd0, exifIFD []tiffEntry) []byte {
+	tiff := exifTIFF(ifd0, exifIFD)
+	var jpeg bytes.Buffer
+	jpeg.Write([]byte{0xFF, 0xD8, 0xFF, 0xE1})
+	binary.Write(&jpeg, binary.BigEndian, uint16(2+6+len(tiff)))
+	jpeg.WriteString("Exif\x00\x00")
+	jpeg.Write(tiff)
+	jpeg.Write([]byte{0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9})
+	return jpeg.Bytes()
+}
+
+// exifTIFF returns the TIFF structure of EXIF metadata holding the given
+// IFD0 and EXIF sub-IFD entries, in little endian byte order.
+func exifTIFF(ifd0, exifIFD []tiffEntry) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("II*\x00")
+	binary.Write(&tiff, binary.LittleEndian, uint32(8))
+	ifdSize := func(entries []tiffEntry) int { return 2 + 12*len(entries) + 4 }
+	// IFD0 has an extra entry pointing to the EXIF sub-IFD. The data of
+	// the entries follows both IFDs.
+	exifOffset := 8 + ifdSize(ifd0) + 12
+	dataOffset := exifOffset + ifdSize(exifIFD)
+	var data bytes.Buffer
+	writeIFD := func(entries []tiffEntry, pointer bool) {
+		n := len(entries)
+		if pointer {
+			n++
+		}
+		binary.Write(&tiff, binary.LittleEndian, uint16(n))
+		for _, e := range entries {
+			// Maker notes are UNDEFINED, other entries ASCII.
+			typ, value := uint16(2), e.value+"\x00"
+			if e.tag == exifMakerNote {
+				typ, value = 7, e.value
+			}
+			binary.Write(&tiff, binary.LittleEndian, struct {
+				Tag, Type uint16
+				Count     uint32
+			}{e.tag, typ, uint32(len(value))})
+			if len(value) <= 4 {
+				tiff.WriteString(value + "\x00\x00\x00\x00"[:4-len(value)])
+				continue
+			}
+			binary.Write(&tiff, binary.LittleEndian, uint32(dataOffset+data.Len()))
+			data.WriteString(value)
+		}
+		if pointer {
+			binary.Write(&tiff, binary.LittleEndian, struct {
+				Tag, Type     uint16
+				Count, Offset uint32
+			}{exifIFDPointer, 4, 1, uint32(exifOffset)})
+		}
+		binary.Write(&tiff, binary.LittleEndian, uint32(0))
+	}
+	writeIFD(ifd0, true)
+	writeIFD(exifIFD, false)
+	tiff.Write(data.Bytes())
+	return tiff.Bytes()
+}
+
+func TestExifDate(t *testing.T) {
+	ifd0 := []tiffEntry{{0x0132, "2024:01:01 10:00:00"}}
+	exif := []tiffEntry{
+		{0x9003, "2023:03:15 14:22:33"},
+		{0x9011, "+02:00"},
+		{0x9004, "2023:03:16 09:00:00"},
+	}
+	path := filepath.Join(t.TempDir(), "IMG_1234.JPG")
+	if err := os.WriteFile(path, exifJPEG(ifd0, exif), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tags []string
+		want time.Time
+	}{
+		{defaultDateTags, time.Date(2023, 3, 15, 14, 22, 33, 0, time.FixedZone("", 2*60*60))},
+		{dateTagOrder("CreateDate"), time.Date(2023, 3, 16, 9, 0, 0, 0, time.Local)},
+		{dateTagOrder("ModifyDate"), time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		date, err := exifDate(path, tt.tags)
+		if err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+			continue
+		}
+		if !date.Equal(tt.want) {
+			t.Errorf("got %s, want %s (tags: %v)", date, tt.want, tt.tags)
+		}
+	}
+}
+
+func TestExifDateErrors(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.jpg")
+	writeImage(t, plain, "jpeg", 10, 10)
+	noDate := filepath.Join(dir, "nodate.jpg")
+	if err := os.WriteFile(noDate, exifJPEG([]tiffEntry{{0x010F, "Camera"}}, nil), 0600); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.jpg")
+	if err := os.WriteFile(invalid, exifJPEG(nil, []tiffEntry{{0x9003, "0000:00:00 00:00:00"}}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{plain, noDate, invalid, filepath.Join(dir, "video.mp4")} {
+		if _, err := exifDate(path, defaultDateTags); err == nil {
+			t.Errorf("Expected error but received none (path: %s)", path)
+		}
+	}
+}
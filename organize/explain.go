@@ -0,0 +1,101 @@
+package organize
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+)
+
+// explainUnmatched returns human readable descriptions of the matchers that
+// came close to handling fileName, and how they fell short. It is intended to
+// be called for file names that no matcher handles, to ease figuring out why a
+// file was skipped.
+func explainUnmatched(matchers []*MediaFileMatcher, fileName string) []string {
+	var reasons []string
+	for _, matcher := range matchers {
+		if matcher.MatchFileName(fileName) {
+			if _, err := matcher.ParseDate(fileName); err != nil {
+				reasons = append(reasons, fmt.Sprintf("%s: pattern matched but the date is invalid: %v", matcher.name, err))
+			}
+			continue
+		}
+		if reason := matcher.extensionMiss(fileName); reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", matcher.name, reason))
+			continue
+		}
+		for _, re := range matcher.supportedRegexps {
+			if reason := explainNearMiss(re, fileName); reason != "" {
+				reasons = append(reasons, fmt.Sprintf("%s: %s", matcher.name, reason))
+			}
+		}
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no matcher came close")
+	}
+	return reasons
+}
+
+// explainNearMiss describes how fileName fails to match re, or returns an
+// empty string if fileName bears no resemblance to what re expects.
+func explainNearMiss(re *regexp.Regexp, fileName string) string {
+	if re.MatchString(fileName) {
+		return ""
+	}
+	if caseInsensitive(re).MatchString(fileName) {
+		return fmt.Sprintf("pattern %q would match if letter case were ignored", re)
+	}
+	if ext := filepath.Ext(fileName); ext != "" {
+		if want := trailingLiteral(re); want != "" {
+			base := strings.TrimSuffix(fileName, ext)
+			if re.MatchString(base + "." + strings.TrimPrefix(want, ".")) {
+				return fmt.Sprintf("name fits pattern %q but extension %q is not supported", re, ext)
+			}
+		}
+	}
+	if prefix, _ := re.LiteralPrefix(); prefix != "" && strings.Contains(fileName, prefix) {
+		return fmt.Sprintf("prefix %q found but the rest of the name does not fit pattern %q", prefix, re)
+	}
+	return ""
+}
+
+// caseInsensitiveRegexps caches the case insensitive versions of the patterns
+// given to explainNearMiss, by pattern, as it is called for each pattern of
+// each matcher, for every file that can't be dated.
+var caseInsensitiveRegexps sync.Map
+
+// caseInsensitive returns the version of re that ignores letter case.
+func caseInsensitive(re *regexp.Regexp) *regexp.Regexp {
+	if folded, ok := caseInsensitiveRegexps.Load(re.String()); ok {
+		return folded.(*regexp.Regexp)
+	}
+	folded := regexp.MustCompile("(?i)" + re.String())
+	caseInsensitiveRegexps.Store(re.String(), folded)
+	return folded
+}
+
+// trailingLiteral returns the literal text that re requires at the end of its
+// match (typically a file extension), or an empty string if there is none.
+func trailingLiteral(re *regexp.Regexp) string {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	parsed = parsed.Simplify()
+	if parsed.Op != syntax.OpConcat {
+		return ""
+	}
+	for i := len(parsed.Sub) - 1; i >= 0; i-- {
+		switch sub := parsed.Sub[i]; sub.Op {
+		case syntax.OpEndText, syntax.OpEndLine:
+			continue
+		case syntax.OpLiteral:
+			return string(sub.Rune)
+		default:
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package organize
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExplainUnmatched(t *testing.T) {
+	tests := []struct {
+		fileName     string
+		wantContains string
+	}{
+		{"img_20210222_213525.jpg", "letter case"},
+		{"IMG_20210222_213525.txt", `extension ".txt" is not supported`},
+		{"C360_2019-07-17-169.jpg", `prefix "C360_" found`},
+		{"IMG_20211341_213525.jpg", "date is invalid"},
+		{"notes.txt", "no matcher came close"},
+	}
+
+	for _, tt := range tests {
+		reasons := explainUnmatched(mediaMatchers, tt.fileName)
+		joined := strings.Join(reasons, "\n")
+		if !strings.Contains(joined, tt.wantContains) {
+			t.Errorf("explainUnmatched(%q) = %q, want it to contain %q", tt.fileName, joined, tt.wantContains)
+		}
+	}
+}
+
+func TestTrailingLiteral(t *testing.T) {
+	for _, re := range []*regexp.Regexp{
+		regexp.MustCompile(`^DSC_\d{8}_\d+\.jpg$`),
+		regexp.MustCompile(`^DSC_(?P<date>\d{8})_\d+\.jpg`),
+	} {
+		if got := trailingLiteral(re); got != ".jpg" {
+			t.Errorf("trailingLiteral(%q) = %q, want %q", re, got, ".jpg")
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package organize
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// externalTool is an optional third party program used to read capture dates
+// from file metadata. External tools cover far more formats than organizepics
+// understands on its own, but are only used when installed.
+type externalTool struct {
+	// name is the name of the tool's executable.
+	name string
+	// handles reports whether the tool should be consulted for fileName.
+	handles func(fileName string) bool
+	// args returns the command line arguments used to query path for the
+	// given date tags.
+	args func(path string, tags []string) []string
+	// parse extracts the capture date from the tool's output, using the
+	// first of the date tags found.
+	parse func(out []byte, tags []string) (time.Time, error)
+}
+
+// DateTags lists the EXIF/QuickTime tags that may hold a file's capture date.
+// ModifyDate is only used if explicitly preferred: editing software updates it.
+var DateTags = []string{"DateTimeOriginal", "CreationDate", "CreateDate", "MediaCreateDate", "ModifyDate"}
+
+// defaultDateTags lists the tags read for a file's capture date by default, in
+// order of preference.
+var defaultDateTags = DateTags[:4]
+
+// dateTagOrder returns the tags read for a file's capture date, in order of
+// preference, with preferred (if not empty) first. Scanned photos for
+// example often carry the scan date in DateTimeOriginal and the true date in
+// another tag.
+func dateTagOrder(preferred string) []string {
+	if preferred == "" {
+		return defaultDateTags
+	}
+	tags := []string{preferred}
+	for _, tag := range defaultDateTags {
+		if tag != preferred {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// validDateTag reports whether tag is one of DateTags.
+func validDateTag(tag string) bool {
+	for _, t := range DateTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+var externalTools = []externalTool{
+	{
+		name:    "exiftool",
+		handles: IsMedia,
+		args: func(path string, tags []string) []string {
+			args := []string{"-json", "-d", "%Y-%m-%d %H:%M:%S%z"}
+			for _, tag := range tags {
+				args = append(args, "-"+tag)
+			}
+			return append(args, path)
+		},
+		parse: parseExiftoolOutput,
+	},
+	{
+		name:    "ffprobe",
+		handles: isVideo,
+		args: func(path string, _ []string) []string {
+			return []string{"-v", "quiet", "-print_format", "json", "-show_entries", "format_tags=com.apple.quicktime.creationdate,creation_time", path}
+		},
+		parse: func(out []byte, _ []string) (time.Time, error) {
+			return parseFfprobeOutput(out)
+		},
+	},
+}
+
+// availableExternalTools returns the external tools installed on this system.
+func availableExternalTools() []externalTool {
+	var tools []externalTool
+	for _, tool := range externalTools {
+		if _, err := exec.LookPath(tool.name); err == nil {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// InstalledExternalTools returns the names of the external tools used to read
+// metadata dates that are installed on this system.
+func InstalledExternalTools() []string {
+	var names []string
+	for _, tool := range availableExternalTools() {
+		names = append(names, tool.name)
+	}
+	return names
+}
+
+// externalToolDate determines the capture date of the file at path using the
+// first of tools that handles the file and finds one of the date tags.
+func externalToolDate(tools []externalTool, tags []string, path string) (time.Time, error) {
+	for _, tool := range tools {
+		if !tool.handles(path) {
+			continue
+		}
+		out, err := exec.Command(tool.name, tool.args(path, tags)...).Output()
+		if err != nil {
+			continue
+		}
+		if date, err := tool.parse(out, tags); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no external tool found a date for %q", path)
+}
+
+// exiftoolDateLayouts are the layouts of the dates exiftool outputs, with the
+// time zone of the capture if the file records it. Dates without one are
+// interpreted as local time.
+var exiftoolDateLayouts = []string{
+	"2006-01-02 15:04:05-0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+}
+
+// parseExiftoolOutput parses the JSON output of exiftool, using the first of
+// tags that holds a valid date.
+func parseExiftoolOutput(out []byte, tags []string) (time.Time, error) {
+	var results []map[string]interface{}
+	if err := json.Unmarshal(out, &results); err != nil {
+		return time.Time{}, err
+	}
+	if len(results) == 0 {
+		return time.Time{}, fmt.Errorf("exiftool returned no results")
+	}
+	for _, tag := range tags {
+		value, ok := results[0][tag].(string)
+		if !ok {
+			continue
+		}
+		for _, layout := range exiftoolDateLayouts {
+			if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return date, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("exiftool found no date tags")
+}
+
+// parseFfprobeOutput parses the capture date from the JSON output of ffprobe,
+// preferring the com.apple.quicktime.creationdate tag, which records the time
+// zone of the capture, over creation_time, which is in UTC and converted to
+// local time.
+func parseFfprobeOutput(out []byte) (time.Time, error) {
+	var result struct {
+		Format struct {
+			Tags struct {
+				QuickTimeCreationDate string `json:"com.apple.quicktime.creationdate"`
+				CreationTime          string `json:"creation_time"`
+			} `json:"tags"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return time.Time{}, err
+	}
+	if date, err := parseMetadataDate(result.Format.Tags.QuickTimeCreationDate); err == nil {
+		return date, nil
+	}
+	if result.Format.Tags.CreationTime == "" {
+		return time.Time{}, fmt.Errorf("ffprobe found no creation_time")
+	}
+	date, err := time.Parse(time.RFC3339Nano, result.Format.Tags.CreationTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return date.Local(), nil
+}
@@ -0,0 +1,79 @@
+package organize
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExiftoolOutput(t *testing.T) {
+	tests := []struct {
+		out         string
+		want        time.Time
+		errExpected bool
+	}{
+		{`[{"SourceFile": "a.jpg", "DateTimeOriginal": "2021-02-22 21:35:25", "CreateDate": "2020-01-01 00:00:00"}]`,
+			time.Date(2021, 2, 22, 21, 35, 25, 0, time.Local), false},
+		{`[{"SourceFile": "a.mov", "CreateDate": "0000:00:00 00:00:00", "MediaCreateDate": "2019-07-17 04:02:45"}]`,
+			time.Date(2019, 7, 17, 4, 2, 45, 0, time.Local), false},
+		{`[{"SourceFile": "a.heic", "DateTimeOriginal": "2023-03-15 23:22:33+0200"}]`,
+			time.Date(2023, 3, 15, 23, 22, 33, 0, time.FixedZone("", 2*60*60)), false},
+		{`[{"SourceFile": "a.png"}]`, time.Time{}, true},
+		{`not json`, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseExiftoolOutput([]byte(tt.out), defaultDateTags)
+		if err != nil && !tt.errExpected {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+		if tt.errExpected && err == nil {
+			t.Errorf("Expected error but received none (output: %s)", tt.out)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("got %s, want %s (output: %s)", got, tt.want, tt.out)
+		}
+	}
+}
+
+func TestParseExiftoolOutputPreferredTag(t *testing.T) {
+	out := []byte(`[{"SourceFile": "scan.jpg", "DateTimeOriginal": "2021-02-22 21:35:25", "ModifyDate": "1998-12-25 12:00:00"}]`)
+	got, err := parseExiftoolOutput(out, dateTagOrder("ModifyDate"))
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if want := time.Date(1998, 12, 25, 12, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestParseFfprobeOutput(t *testing.T) {
+	got, err := parseFfprobeOutput([]byte(`{"format": {"tags": {"creation_time": "2020-10-12T12:41:24.000000Z"}}}`))
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if want := time.Date(2020, 10, 12, 12, 41, 24, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	got, err = parseFfprobeOutput([]byte(`{"format": {"tags": {"com.apple.quicktime.creationdate": "2023-03-15T23:22:33+0200", "creation_time": "2023-03-15T21:22:33.000000Z"}}}`))
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if want := "2023-03-15 23:22"; got.Format("2006-01-02 15:04") != want {
+		t.Errorf("got %s, want %s in the capture time zone", got, want)
+	}
+
+	if _, err := parseFfprobeOutput([]byte(`{"format": {}}`)); err == nil {
+		t.Error("Expected error but received none")
+	}
+}
+
+func TestExternalToolsHandleMedia(t *testing.T) {
+	for _, tool := range externalTools {
+		for _, name := range []string{"notes.txt", "IMG_0001.xmp", "archive.zip"} {
+			if tool.handles(name) {
+				t.Errorf("%s handles %q, want only media files", tool.name, name)
+			}
+		}
+	}
+}
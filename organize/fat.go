@@ -0,0 +1,27 @@
+package organize
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+)
+
+// dosNameRegexp matches 8.3 style names of media files, such as PICT0012.JPG,
+// as written by older cameras onto FAT formatted memory cards.
+var dosNameRegexp = regexp.MustCompile(`^(?i)[A-Z0-9_~-]{1,8}\.(JPG|TIF|AVI|MOV|MPG|MP4|3GP|THM|WAV|CRW|CR2|NEF|ORF|PEF|RAF|DNG|ARW)$`)
+
+// fatResolution is the resolution of FAT modification timestamps.
+const fatResolution = 2 * time.Second
+
+// fatTimestampDate returns the date recorded by the camera in the FAT
+// directory entry of an 8.3 named media file, which file systems expose as the
+// file's modification time. The result is only a best guess: the timestamp
+// has a 2-second resolution, the camera clock may never have been set, and
+// copying the file off the card may have replaced it.
+func fatTimestampDate(info os.FileInfo) (time.Time, error) {
+	if !dosNameRegexp.MatchString(info.Name()) {
+		return time.Time{}, fmt.Errorf("%q is not an 8.3 media file name", info.Name())
+	}
+	return info.ModTime().Truncate(fatResolution), nil
+}
@@ -0,0 +1,50 @@
+package organize
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// fakeFileInfo is an os.FileInfo for a regular file with the given name and
+// modification time.
+type fakeFileInfo struct {
+	name    string
+	modTime time.Time
+}
+
+func (f fakeFileInfo) Name() string       { return f.name }
+func (f fakeFileInfo) Size() int64        { return 0 }
+func (f fakeFileInfo) Mode() os.FileMode  { return 0600 }
+func (f fakeFileInfo) ModTime() time.Time { return f.modTime }
+func (f fakeFileInfo) IsDir() bool        { return false }
+func (f fakeFileInfo) Sys() interface{}   { return nil }
+
+func TestFatTimestampDate(t *testing.T) {
+	modTime := time.Date(2004, 7, 3, 13, 45, 11, 0, time.Local)
+	tests := []struct {
+		fileName    string
+		want        time.Time
+		errExpected bool
+	}{
+		{"PICT0012.JPG", time.Date(2004, 7, 3, 13, 45, 10, 0, time.Local), false},
+		{"MVI_0034.AVI", time.Date(2004, 7, 3, 13, 45, 10, 0, time.Local), false},
+		{"dsc00012.jpg", time.Date(2004, 7, 3, 13, 45, 10, 0, time.Local), false},
+		{"PICTURE0012.JPG", time.Time{}, true}, // Base name too long.
+		{"PICT0012.JPEG", time.Time{}, true},   // Extension too long.
+		{"NOTES.TXT", time.Time{}, true},       // Not a media file.
+	}
+
+	for _, tt := range tests {
+		got, err := fatTimestampDate(fakeFileInfo{tt.fileName, modTime})
+		if err != nil && !tt.errExpected {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+		if tt.errExpected && err == nil {
+			t.Errorf("Expected error but received none (file name: %s)", tt.fileName)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("got %s, want %s (file name: %s)", got, tt.want, tt.fileName)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package organize
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// WithInclude makes the Organizer only consider the files matching one of the
+// glob patterns (as in path.Match, e.g. "*.jpg"), if any are given. Patterns
+// without a slash match file names; those with one, slash separated paths
+// relative to the organized directory (e.g. "DCIM/*/*.jpg").
+func WithInclude(patterns ...string) Option {
+	return func(o *options) { o.include = append(o.include, patterns...) }
+}
+
+// WithExclude makes the Organizer never consider the files matching one of
+// the glob patterns, given as for WithInclude (e.g. "*.tmp" or ".trashed-*"),
+// such as partial downloads and thumbnails. Exclusions take precedence over
+// inclusions.
+func WithExclude(patterns ...string) Option {
+	return func(o *options) { o.exclude = append(o.exclude, patterns...) }
+}
+
+// checkGlobs returns an error if any of patterns is malformed.
+func checkGlobs(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchesGlob reports whether the file at the slash separated path p matches
+// any of patterns.
+func matchesGlob(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		name := path.Base(p)
+		if strings.Contains(pattern, "/") {
+			name = p
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filtered reports whether the file at the slash separated path p, relative
+// to the organized directory, is left out by the include and exclude patterns
+// of opts.
+func filtered(p string, opts options) bool {
+	if len(opts.include) > 0 && !matchesGlob(opts.include, p) {
+		return true
+	}
+	return matchesGlob(opts.exclude, p)
+}
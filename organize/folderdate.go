@@ -0,0 +1,79 @@
+package organize
+
+import (
+	"fmt"
+	"time"
+)
+
+// FolderDatePolicy selects the time zone in which capture times are read to
+// pick the day a file is filed under, which matters for files captured
+// around midnight.
+//
+// Capture times come in three kinds: those recorded with their time zone
+// (EXIF offsets, Apple's creation dates, zoned names), instants without one
+// (QuickTime movie headers, UTC names, modification times), and wall clock
+// times of an unknown time zone (EXIF without offsets, names). Dates found in
+// names without a time of day, such as IMG_20210222_*, are taken as they are
+// by all policies.
+type FolderDatePolicy string
+
+const (
+	// FolderDateCamera files by the wall clock of the device at capture,
+	// where its time zone is recorded, and by the local time zone of this
+	// machine otherwise.
+	FolderDateCamera FolderDatePolicy = "camera"
+	// FolderDateLocal files by the local time zone of this machine, e.g. a
+	// photo taken at 23:30 in New York is filed under the next day in Paris.
+	FolderDateLocal FolderDatePolicy = "local"
+	// FolderDateUTC files by UTC, taking wall clock times of an unknown time
+	// zone to be local.
+	FolderDateUTC FolderDatePolicy = "utc"
+	// DefaultFolderDate is the folder date policy used unless configured
+	// otherwise.
+	DefaultFolderDate = FolderDateCamera
+)
+
+// String implements flag.Value.
+func (p *FolderDatePolicy) String() string {
+	return string(*p)
+}
+
+// Set implements flag.Value.
+func (p *FolderDatePolicy) Set(s string) error {
+	switch policy := FolderDatePolicy(s); policy {
+	case FolderDateCamera, FolderDateLocal, FolderDateUTC:
+		*p = policy
+		return nil
+	}
+	return fmt.Errorf("unknown folder date policy %q, want %q, %q or %q", s, FolderDateCamera, FolderDateLocal, FolderDateUTC)
+}
+
+// WithFolderDate sets the policy selecting the time zone files are filed by.
+func WithFolderDate(policy FolderDatePolicy) Option {
+	return func(o *options) { o.folderDate = policy }
+}
+
+// folderDate returns date, a capture time as determined by fileDate, in the
+// time zone selected by policy. Dates that are the wall clock time of the
+// device are kept as they are, lacking a time zone to convert from.
+func folderDate(date time.Time, wallClock bool, policy FolderDatePolicy) time.Time {
+	if wallClock {
+		return date
+	}
+	switch policy {
+	case FolderDateLocal:
+		return date.Local()
+	case FolderDateUTC:
+		return date.UTC()
+	}
+	return date
+}
+
+// formatFolderDate formats date, as returned by folderDate, for the report:
+// with its time zone, unless it is the wall clock time of the device.
+func formatFolderDate(date time.Time, wallClock bool) string {
+	if wallClock {
+		return date.Format("2006-01-02T15:04:05")
+	}
+	return date.Format(time.RFC3339)
+}
@@ -0,0 +1,60 @@
+package organize
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFolderDate(t *testing.T) {
+	newYork := time.FixedZone("EST", -5*60*60)
+	// Taken at 23:30 in New York, which is the next day in UTC.
+	zoned := time.Date(2021, 2, 22, 23, 30, 0, 0, newYork)
+	named := time.Date(2021, 2, 22, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		date      time.Time
+		wallClock bool
+		policy    FolderDatePolicy
+		want      string
+	}{
+		{zoned, false, FolderDateCamera, "2021-02-22T23:30:00-05:00"},
+		{zoned, false, FolderDateUTC, "2021-02-23T04:30:00Z"},
+		{zoned, false, FolderDateLocal, zoned.Local().Format(time.RFC3339)},
+		{named, true, FolderDateCamera, "2021-02-22T00:00:00"},
+		{named, true, FolderDateUTC, "2021-02-22T00:00:00"}, // Dates of names are kept.
+		{named, true, FolderDateLocal, "2021-02-22T00:00:00"},
+		// Capture times recorded in UTC are converted like any other.
+		{named, false, FolderDateLocal, named.Local().Format(time.RFC3339)},
+	}
+	for _, tt := range tests {
+		if got := formatFolderDate(folderDate(tt.date, tt.wallClock, tt.policy), tt.wallClock); got != tt.want {
+			t.Errorf("got %s, want %s (date: %s, wall clock: %v, policy: %s)", got, tt.want, tt.date, tt.wallClock, tt.policy)
+		}
+	}
+}
+
+func TestFolderDateUTCCaptureTime(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("EDT", -4*60*60)
+	defer func() { time.Local = local }()
+
+	// Recorded as 2023-03-16T02:00:00Z, i.e. 22:00 the evening before in New York.
+	date := time.Date(2023, 3, 16, 2, 0, 0, 0, time.UTC)
+	if got := folderDate(date, false, FolderDateLocal).Format("2006-01-02"); got != "2023-03-15" {
+		t.Errorf("got %s, want %s", got, "2023-03-15")
+	}
+	if got := folderDate(date, false, FolderDateUTC).Format("2006-01-02"); got != "2023-03-16" {
+		t.Errorf("got %s, want %s", got, "2023-03-16")
+	}
+}
+
+func TestFolderDatePolicySet(t *testing.T) {
+	var p FolderDatePolicy
+	for _, s := range []string{"camera", "local", "utc"} {
+		if err := p.Set(s); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+	if err := p.Set("gmt"); err == nil {
+		t.Errorf("Expected error but received none")
+	}
+}
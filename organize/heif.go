@@ -0,0 +1,229 @@
+package organize
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// heifExtensions lists the (lower case) extensions of HEIF files, whose EXIF
+// metadata is read by heifDate.
+var heifExtensions = map[string]bool{
+	".heic": true, ".heif": true, ".hif": true,
+}
+
+// Limits on the size of HEIF metadata read into memory, to protect against
+// corrupt files. The meta box mostly holds item locations and properties.
+const (
+	maxHEIFMetaSize = 16 << 20
+	maxHEIFExifSize = 4 << 20
+)
+
+// heifDate reads the capture date of the HEIF (e.g. HEIC) file at path from
+// the EXIF metadata item it embeds, using the first of tags that is present,
+// as exifDate does for JPEG files.
+func heifDate(path string, tags []string) (time.Time, error) {
+	values, err := heifExifValues(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date, ok := exifTagsDate(values, tags); ok {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("no EXIF date in %q", path)
+}
+
+// heifExifValues returns the ASCII values of the EXIF metadata of the HEIF
+// file at path, by tag.
+func heifExifValues(path string) (map[uint16]string, error) {
+	if !heifExtensions[strings.ToLower(filepath.Ext(path))] {
+		return nil, fmt.Errorf("%q is not a HEIF file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var meta []byte
+	for meta == nil {
+		typ, size, err := readBoxHeader(f)
+		if err == io.EOF {
+			return nil, fmt.Errorf("no metadata found in %q", path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case typ == "meta" && size >= 0 && size <= maxHEIFMetaSize:
+			meta = make([]byte, size)
+			_, err = io.ReadFull(f, meta)
+		case size < 0:
+			return nil, fmt.Errorf("no metadata found in %q", path)
+		default:
+			_, err = f.Seek(size, io.SeekCurrent)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	tiff, err := heifExif(f, meta)
+	if err != nil {
+		return nil, fmt.Errorf("no EXIF metadata in %q: %v", path, err)
+	}
+	values, err := exifValues(tiff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXIF metadata in %q: %v", path, err)
+	}
+	return values, nil
+}
+
+// heifExif returns the TIFF structure of the EXIF item described by the
+// payload of the meta box of the HEIF file r.
+func heifExif(r io.ReaderAt, meta []byte) ([]byte, error) {
+	if len(meta) < 4 {
+		return nil, errors.New("truncated meta box")
+	}
+	// The meta box is a full box, starting with its version and flags.
+	children := boxes(meta[4:])
+	id, err := heifExifItemID(children["iinf"])
+	if err != nil {
+		return nil, err
+	}
+	extents, err := heifItemExtents(children["iloc"], id)
+	if err != nil {
+		return nil, err
+	}
+	var item []byte
+	for _, e := range extents {
+		if e.length > maxHEIFExifSize || uint64(len(item))+e.length > maxHEIFExifSize {
+			return nil, errors.New("EXIF item too large")
+		}
+		data := make([]byte, e.length)
+		if _, err := r.ReadAt(data, int64(e.offset)); err != nil {
+			return nil, err
+		}
+		item = append(item, data...)
+	}
+	// The item starts with the offset of the TIFF header, past the usual
+	// "Exif\0\0" marker.
+	if len(item) < 4 {
+		return nil, errors.New("truncated EXIF item")
+	}
+	start := uint64(binary.BigEndian.Uint32(item)) + 4
+	if start > uint64(len(item)) {
+		return nil, errors.New("invalid EXIF item header")
+	}
+	return item[start:], nil
+}
+
+// heifExifItemID returns the ID of the EXIF item listed in the payload of an
+// iinf box.
+func heifExifItemID(iinf []byte) (uint32, error) {
+	if len(iinf) < 6 {
+		return 0, errors.New("no item information")
+	}
+	entries := iinf[6:]
+	if iinf[0] != 0 {
+		entries = iinf[8:]
+	}
+	for len(entries) >= 8 {
+		size := int(binary.BigEndian.Uint32(entries))
+		if size < 8 || size > len(entries) {
+			break
+		}
+		infe := entries[8:size]
+		entries = entries[size:]
+		if len(infe) < 4 {
+			continue
+		}
+		// Only infe versions 2 and 3 carry an item type.
+		switch version := infe[0]; {
+		case version == 2 && len(infe) >= 12 && string(infe[8:12]) == "Exif":
+			return uint32(binary.BigEndian.Uint16(infe[4:])), nil
+		case version == 3 && len(infe) >= 14 && string(infe[10:14]) == "Exif":
+			return binary.BigEndian.Uint32(infe[4:]), nil
+		}
+	}
+	return 0, errors.New("no EXIF item")
+}
+
+// heifExtent is a contiguous part of an item, at an offset in the file.
+type heifExtent struct {
+	offset, length uint64
+}
+
+// heifItemExtents returns the extents of the item with the given ID, as
+// listed in the payload of an iloc box.
+func heifItemExtents(iloc []byte, id uint32) ([]heifExtent, error) {
+	r := &byteReader{b: iloc}
+	version := r.uint(1)
+	r.uint(3) // Flags.
+	sizes := r.uint(1)
+	offsetSize, lengthSize := int(sizes>>4), int(sizes&0xF)
+	sizes = r.uint(1)
+	baseOffsetSize, indexSize := int(sizes>>4), int(sizes&0xF)
+	if version == 0 {
+		indexSize = 0
+	}
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+	count := r.uint(idSize)
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		itemID := r.uint(idSize)
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			method = r.uint(2) & 0xF
+		}
+		r.uint(2) // Data reference index.
+		base := r.uint(baseOffsetSize)
+		extentCount := r.uint(2)
+		var extents []heifExtent
+		for j := uint64(0); j < extentCount && r.err == nil; j++ {
+			r.uint(indexSize)
+			offset := r.uint(offsetSize)
+			length := r.uint(lengthSize)
+			extents = append(extents, heifExtent{base + offset, length})
+		}
+		if r.err != nil || itemID != uint64(id) {
+			continue
+		}
+		if method != 0 {
+			return nil, fmt.Errorf("unsupported construction method %d of EXIF item", method)
+		}
+		return extents, nil
+	}
+	if r.err != nil {
+		return nil, errors.New("truncated item locations")
+	}
+	return nil, errors.New("EXIF item has no location")
+}
+
+// byteReader reads big endian unsigned integers of various sizes from b. Once
+// b is exhausted it records an error and returns zeros.
+type byteReader struct {
+	b   []byte
+	err error
+}
+
+// uint reads an integer of n (0 to 8) bytes.
+func (r *byteReader) uint(n int) uint64 {
+	if n > len(r.b) || n > 8 {
+		r.err = errors.New("truncated")
+		r.b = nil
+		return 0
+	}
+	var v uint64
+	for _, c := range r.b[:n] {
+		v = v<<8 | uint64(c)
+	}
+	r.b = r.b[n:]
+	return v
+}
@@ -0,0 +1,80 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// heifFile returns a minimal HEIF file whose EXIF item, stored in an mdat
+// box, holds the given TIFF structure.
+func heifFile(tiff []byte) []byte {
+	item := append(u32(6), "Exif\x00\x00"...)
+	item = append(item, tiff...)
+	ftyp := mkbox("ftyp", []byte("heic"), u32(0), []byte("mif1heic"))
+	build := func(offset uint32) []byte {
+		// A version 2 infe box for item 1, of type Exif, preceded by a
+		// hidden image item.
+		infeImage := mkbox("infe", []byte{2, 0, 0, 0, 0, 2, 0, 0}, []byte("hvc1"), []byte{0})
+		infeExif := mkbox("infe", []byte{2, 0, 0, 0, 0, 1, 0, 0}, []byte("Exif"), []byte{0})
+		iinf := mkbox("iinf", []byte{0, 0, 0, 0, 0, 2}, infeImage, infeExif)
+		// A version 1 iloc box with 4 byte offsets and lengths, no base
+		// offsets and 2 items.
+		iloc := mkbox("iloc", []byte{1, 0, 0, 0, 0x44, 0x00, 0, 2},
+			[]byte{0, 2, 0, 0, 0, 0, 0, 1}, u32(0), u32(0),
+			[]byte{0, 1, 0, 0, 0, 0, 0, 1}, u32(offset), u32(uint32(len(item))))
+		hdlr := mkbox("hdlr", u32(0), u32(0), []byte("pict"), make([]byte, 13))
+		return mkbox("meta", u32(0), hdlr, iinf, iloc)
+	}
+	meta := build(0)
+	meta = build(uint32(len(ftyp) + len(meta) + 8))
+	var b []byte
+	b = append(b, ftyp...)
+	b = append(b, meta...)
+	return append(b, mkbox("mdat", item)...)
+}
+
+func TestHEIFDate(t *testing.T) {
+	exif := []tiffEntry{
+		{0x9003, "2023:03:15 14:22:33"},
+		{0x9011, "+02:00"},
+	}
+	path := filepath.Join(t.TempDir(), "IMG_1234.HEIC")
+	if err := os.WriteFile(path, heifFile(exifTIFF(nil, exif)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := heifDate(path, dateTagOrder(""))
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	want := time.Date(2023, 3, 15, 14, 22, 33, 0, time.FixedZone("", 2*60*60))
+	if !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestHEIFDateErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		fileName string
+		contents []byte
+	}{
+		{"IMG_1234.jpg", heifFile(exifTIFF(nil, []tiffEntry{{0x9003, "2023:03:15 14:22:33"}}))},
+		{"IMG_1235.heic", mkbox("ftyp", []byte("heic"))},
+		{"IMG_1236.heic", heifFile(exifTIFF(nil, nil))},
+		{"IMG_1237.heic", heifFile([]byte("not a TIFF header"))},
+		{"IMG_1238.heic", []byte{0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.fileName)
+		if err := os.WriteFile(path, tt.contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := heifDate(path, dateTagOrder("")); err == nil {
+			t.Errorf("Expected error but received none (file name: %s)", tt.fileName)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package organize
+
+import "os"
+
+// Network file systems, notably SMB, may report that an operation failed when
+// the connection dropped before the reply, although the server completed it.
+// Treating such an error as a failure would leave the file where the run
+// doesn't expect it, so the file system is checked before believing it.
+
+// renameSucceeded reports whether the rename of srcPath to destFilePath did
+// happen despite its error: the source is gone and the destination has the
+// size src, the state of the source before the rename, had, and the hash
+// srcHash if not empty.
+func renameSucceeded(srcPath, destFilePath string, src os.FileInfo, srcHash string) bool {
+	if src == nil {
+		return false
+	}
+	if _, err := os.Lstat(srcPath); !os.IsNotExist(err) {
+		return false
+	}
+	dest, err := os.Lstat(destFilePath)
+	if err != nil || dest.Size() != src.Size() {
+		return false
+	}
+	if srcHash == "" {
+		return true
+	}
+	hash, err := hashFile(destFilePath)
+	return err == nil && hash == srcHash
+}
+
+// linkSucceeded reports whether destFilePath is a hard link to srcPath, i.e.
+// whether linking it did happen despite an error.
+func linkSucceeded(srcPath, destFilePath string) bool {
+	src, err := os.Lstat(srcPath)
+	if err != nil {
+		return false
+	}
+	dest, err := os.Lstat(destFilePath)
+	return err == nil && os.SameFile(src, dest)
+}
+
+// removed reports whether the file at path is gone, i.e. whether removing it
+// did happen despite an error.
+func removed(path string) bool {
+	_, err := os.Lstat(path)
+	return os.IsNotExist(err)
+}
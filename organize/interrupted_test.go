@@ -0,0 +1,99 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameSucceeded(t *testing.T) {
+	content := []byte("IMG_0001")
+	hash := "e8b2d3c8a3ef5a79fd8b7a8e6e8f0e1b2ad5c3f1a4e9f1a1f6d5a3b6a1c2d3e4"
+	tests := []struct {
+		name string
+		// srcLeft and destContent are the state after the rename, with a nil
+		// destContent for no destination.
+		srcLeft     bool
+		destContent []byte
+		srcHash     string
+		want        bool
+	}{
+		{"renamed", false, content, "", true},
+		{"source left", true, content, "", false},
+		{"no destination", false, nil, "", false},
+		{"size differs", false, []byte("IMG_00"), "", false},
+		{"hash differs", false, content, hash, false},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		src, dest := filepath.Join(dir, "IMG_0001.jpg"), filepath.Join(dir, "2021-02-22.jpg")
+		if err := os.WriteFile(src, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Lstat(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tt.srcLeft {
+			os.Remove(src)
+		}
+		if tt.destContent != nil {
+			if err := os.WriteFile(dest, tt.destContent, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := renameSucceeded(src, dest, info, tt.srcHash); got != tt.want {
+			t.Errorf("got %t, want %t (%s)", got, tt.want, tt.name)
+		}
+	}
+}
+
+func TestRenameSucceededHash(t *testing.T) {
+	dir := t.TempDir()
+	src, dest := filepath.Join(dir, "IMG_0001.jpg"), filepath.Join(dir, "moved.jpg")
+	if err := os.WriteFile(src, []byte("IMG_0001"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hashFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(src, dest); err != nil {
+		t.Fatal(err)
+	}
+	if !renameSucceeded(src, dest, info, hash) {
+		t.Errorf("Expected the rename to be found to have succeeded")
+	}
+}
+
+func TestLinkSucceeded(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "IMG_0001.jpg")
+	if err := os.WriteFile(src, []byte("IMG_0001"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	linked, copied := filepath.Join(dir, "linked.jpg"), filepath.Join(dir, "copied.jpg")
+	if err := os.Link(src, linked); err != nil {
+		t.Skipf("hard links unsupported: %v", err)
+	}
+	if err := os.WriteFile(copied, []byte("IMG_0001"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		dest string
+		want bool
+	}{
+		{linked, true},
+		{copied, false},
+		{filepath.Join(dir, "missing.jpg"), false},
+	}
+	for _, tt := range tests {
+		if got := linkSucceeded(src, tt.dest); got != tt.want {
+			t.Errorf("got %t, want %t (destination: %s)", got, tt.want, tt.dest)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package organize
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JournalEntry records a file moved or removed by the Organizer, as a line of
+// a journal file.
+type JournalEntry struct {
+	Src string `json:"src"`
+	// Dst is where the file was moved to, or empty if it was deleted.
+	Dst  string    `json:"dst"`
+	Time time.Time `json:"time"`
+	// Hash is the SHA-256 hash of the file's contents, in hex.
+	Hash string `json:"hash"`
+	// Op is the removal recorded, if the file was removed rather than
+	// organized: RemoveTrash or RemoveDelete.
+	Op string `json:"op,omitempty"`
+	// Reason is why the file was removed.
+	Reason string `json:"reason,omitempty"`
+	// Build describes the binary that made the change, if known, e.g.
+	// "organizepics v1.2.0 (0123abc)".
+	Build string `json:"build,omitempty"`
+}
+
+// The removals recorded in JournalEntry.Op.
+const (
+	// RemoveTrash is a file moved into the trash.
+	RemoveTrash = "trash"
+	// RemoveDelete is a file deleted for good.
+	RemoveDelete = "delete"
+)
+
+// recordMove appends an entry for the move of the file at srcPath to
+// destFilePath to the journal of opts. Each entry is written, and the file
+// closed, right away, so that an interrupted run leaves a complete journal.
+func recordMove(srcPath, destFilePath string, opts options) error {
+	hash, err := hashFile(destFilePath)
+	if err != nil {
+		return err
+	}
+	src, err := filepath.Abs(srcPath)
+	if err != nil {
+		return err
+	}
+	dst, err := filepath.Abs(destFilePath)
+	if err != nil {
+		return err
+	}
+	return appendJournal(JournalEntry{Src: src, Dst: dst, Time: time.Now(), Hash: hash}, opts)
+}
+
+// appendJournal appends e, attributed to the build of opts, to the journal of
+// opts.
+func appendJournal(e JournalEntry, opts options) error {
+	e.Build = opts.build
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(opts.journal, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadJournal returns the entries of the journal file at path, in the order
+// the moves were made.
+func ReadJournal(path string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid journal entry: %v", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Undo reverses the moves recorded in the journal file at path, latest first,
+// moving each file back where it came from. Files that have changed or gone
+// since, and files whose original location is taken, are left alone, as are
+// deleted files; trashed files are restored. The directories the files were
+// moved into are removed if left empty. If dryRun is set, the moves that
+// would be made are logged instead. It returns the number of files moved
+// back.
+func Undo(path string, dryRun bool) (int, error) {
+	entries, err := ReadJournal(path)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Op == RemoveDelete {
+			log.Printf("not undoing the deletion of %q, which is gone for good", e.Src)
+			continue
+		}
+		if hash, err := hashFile(e.Dst); err != nil {
+			log.Printf("unable to undo the move of %q: %v", e.Src, err)
+			continue
+		} else if hash != e.Hash {
+			log.Printf("not undoing the move of %q, as %q has changed since", e.Src, e.Dst)
+			continue
+		}
+		if _, err := os.Lstat(e.Src); err == nil {
+			log.Printf("not undoing the move of %q, which exists again", e.Src)
+			continue
+		}
+		if dryRun {
+			log.Printf("Would move %q back to %q", e.Dst, e.Src)
+			count++
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(e.Src), 0700); err != nil {
+			log.Printf("unable to undo the move of %q: %v", e.Src, err)
+			continue
+		}
+		dst, _ := os.Lstat(e.Dst)
+		err := os.Rename(e.Dst, e.Src)
+		if err != nil && !isCrossDevice(err) && renameSucceeded(e.Dst, e.Src, dst, e.Hash) {
+			err = nil
+		}
+		if isCrossDevice(err) {
+			if err = copyAcrossDevices(e.Dst, e.Src, true, e.Hash, copyBufferSize); err == nil {
+				os.Remove(e.Dst)
+			}
+		}
+		if err != nil {
+			log.Printf("unable to undo the move of %q: %v", e.Src, err)
+			continue
+		}
+		count++
+		// Remove the directory the file was moved into, if now empty.
+		// Removing a directory that isn't empty fails harmlessly.
+		os.Remove(filepath.Dir(e.Dst))
+	}
+	return count, nil
+}
@@ -0,0 +1,62 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalUndo(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"IMG_20210222_213525.jpg", "IMG_20210223_080000.jpg", "IMG_20210223_090000.jpg"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	journal := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	o, err := New(WithExternalTools(false), WithJournal(journal), WithBuild("organizepics v1.2.0 (0123abc)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Organize(dir); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	entries, err := ReadJournal(journal)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if len(entries) != len(names) {
+		t.Fatalf("got %d journal entries, want %d", len(entries), len(names))
+	}
+	for _, e := range entries {
+		if e.Build != "organizepics v1.2.0 (0123abc)" {
+			t.Errorf("got build %q, want %q", e.Build, "organizepics v1.2.0 (0123abc)")
+		}
+	}
+
+	// A file changed since it was moved is left alone.
+	changed := filepath.Join(dir, "2021-02-23", "IMG_20210223_090000.jpg")
+	if err := os.WriteFile(changed, []byte("edited"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	n, err := Undo(journal, false)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if n != 2 {
+		t.Errorf("got %d files moved back, want 2", n)
+	}
+	for _, name := range names[:2] {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+	if _, err := os.Stat(changed); err != nil {
+		t.Errorf("Expected no error but received: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2021-02-22")); !os.IsNotExist(err) {
+		t.Errorf("got %v, want the emptied dated directory removed", err)
+	}
+}
@@ -0,0 +1,124 @@
+package organize
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runBudget tracks the resources used by a run against its limits.
+type runBudget struct {
+	// maxRuntime and maxBytes are the limits, or zero for none.
+	maxRuntime time.Duration
+	maxBytes   int64
+	start      time.Time
+	// bytes is the size of the files moved so far.
+	bytes int64
+	// remaining holds the moves left undone once a limit was reached.
+	remaining []PlannedMove
+	// stopped is set once a limit was reached.
+	stopped bool
+}
+
+// limited reports whether the budget has any limit.
+func (b *runBudget) limited() bool {
+	return b != nil && (b.maxRuntime > 0 || b.maxBytes > 0)
+}
+
+// take reports whether moving the file at path stays within the budget, and
+// if so counts its size against it. A file larger than maxBytes is allowed as
+// the first file of a run, so that it doesn't hold up every run.
+func (b *runBudget) take(path string) bool {
+	if b.maxRuntime > 0 && time.Since(b.start) >= b.maxRuntime {
+		return false
+	}
+	if b.maxBytes > 0 {
+		info, err := os.Lstat(path)
+		if err != nil {
+			// Moving will fail and report it.
+			return true
+		}
+		if b.bytes > 0 && b.bytes+info.Size() > b.maxBytes {
+			return false
+		}
+		b.bytes += info.Size()
+	}
+	return true
+}
+
+// admit reports whether the move m stays within the budget, counting it
+// against the budget if so. Once a move doesn't, the budget is stopped and it
+// and all later moves are left undone.
+func (b *runBudget) admit(m PlannedMove) bool {
+	runMu.Lock()
+	defer runMu.Unlock()
+	if !b.stopped && b.take(m.Src) {
+		return true
+	}
+	b.stopped = true
+	b.remaining = append(b.remaining, m)
+	return false
+}
+
+// runState is the content of a state file: the moves a run left undone when
+// it reached its limits, for the next run to resume.
+type runState struct {
+	// Dir is the absolute path of the organized directory.
+	Dir   string        `json:"dir"`
+	Moves []PlannedMove `json:"moves"`
+}
+
+// loadState returns the moves left undone in dirName by a previous run, as
+// recorded in the state file at path, if any. Moves of files that have gone
+// since are dropped.
+func loadState(path, dirName string) ([]PlannedMove, bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var state runState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, false, fmt.Errorf("invalid state file %q: %v", path, err)
+	}
+	if abs, err := filepath.Abs(dirName); err != nil || abs != state.Dir {
+		log.Printf("Ignoring state file %q, which is for %q", path, state.Dir)
+		return nil, false, nil
+	}
+	var moves []PlannedMove
+	for _, m := range state.Moves {
+		if _, err := os.Lstat(m.Src); err == nil {
+			moves = append(moves, m)
+		}
+	}
+	return moves, true, nil
+}
+
+// saveState records the moves left undone in dirName in the state file at
+// path, or removes the state file if there are none.
+func saveState(path, dirName string, moves []PlannedMove) error {
+	if len(moves) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	abs, err := filepath.Abs(dirName)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(runState{abs, moves}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
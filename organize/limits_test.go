@@ -0,0 +1,74 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrganizeMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"IMG_20210222_000001.jpg", "IMG_20210222_000002.jpg", "IMG_20210222_000003.jpg"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, 100), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	o, err := New(WithExternalTools(false), WithMaxBytes(150), WithStateFile(stateFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for run, want := range []int{1, 1, 1, 0} {
+		moved, err := o.Organize(dir)
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		if moved != want {
+			t.Errorf("got %d files moved in run %d, want %d", moved, run, want)
+		}
+		_, err = os.Stat(stateFile)
+		if left := run < 2; left != (err == nil) {
+			t.Errorf("got state file error %v in run %d, want a state file: %t", err, run, left)
+		}
+	}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, "2021-02-22", name)); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+}
+
+func TestLoadStateOtherDir(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	src := filepath.Join(t.TempDir(), "IMG_20210222_000001.jpg")
+	if err := os.WriteFile(src, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveState(stateFile, "a", []PlannedMove{{Src: src, DestDir: "a/2021-02-22"}}); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+
+	moves, ok, err := loadState(stateFile, "a")
+	if err != nil || !ok || len(moves) != 1 {
+		t.Errorf("got %v, %t, %v, want the saved move", moves, ok, err)
+	}
+	if _, ok, err := loadState(stateFile, "b"); err != nil || ok {
+		t.Errorf("got %t, %v, want the state of another directory ignored", ok, err)
+	}
+	if err := os.Remove(src); err != nil {
+		t.Fatal(err)
+	}
+	if moves, _, _ := loadState(stateFile, "a"); len(moves) != 0 {
+		t.Errorf("got %v, want the moves of files that have gone dropped", moves)
+	}
+}
+
+func TestNewInvalidLimits(t *testing.T) {
+	for _, opt := range []Option{WithMaxRuntime(-1), WithMaxBytes(-1)} {
+		if _, err := New(opt); err == nil {
+			t.Error("Expected error but received none")
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package organize
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+)
+
+// appleMakerNoteHeader starts the EXIF maker notes of iPhone photos. It is
+// followed by a version and the byte order of the maker note's IFD.
+const appleMakerNoteHeader = "Apple iOS\x00"
+
+// appleContentIDTag is the tag of the Apple maker note holding the content
+// identifier shared by the photo and video of a Live Photo.
+const appleContentIDTag = 0x0011
+
+// quickTimeContentIDKey is the metadata key of QuickTime files holding the
+// content identifier shared by the photo and video of a Live Photo.
+const quickTimeContentIDKey = "com.apple.quicktime.content.identifier"
+
+// livePhotoExtensions lists the (lower case) extensions of the photos of Live
+// Photos. Their videos are .mov files.
+var livePhotoExtensions = map[string]bool{
+	".heic": true, ".jpg": true, ".jpeg": true,
+}
+
+// photoContentID returns the Live Photo content identifier recorded in the
+// Apple maker note of the photo at path, if any.
+func photoContentID(path string) (string, bool) {
+	values, err := exifMetadata(path)
+	if err != nil {
+		return "", false
+	}
+	note := []byte(values[exifMakerNote])
+	if len(note) < len(appleMakerNoteHeader)+4 || !strings.HasPrefix(string(note), appleMakerNoteHeader) {
+		return "", false
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if string(note[len(appleMakerNoteHeader)+2:len(appleMakerNoteHeader)+4]) == "II" {
+		order = binary.LittleEndian
+	}
+	// Offsets in the maker note are relative to its start.
+	tags := make(map[uint16]string)
+	if _, err := readIFD(note, order, uint32(len(appleMakerNoteHeader)+4), tags); err != nil {
+		return "", false
+	}
+	id := tags[appleContentIDTag]
+	return id, id != ""
+}
+
+// videoContentID returns the Live Photo content identifier recorded in the
+// metadata of the QuickTime video at path, if any.
+func videoContentID(path string) (string, bool) {
+	moov, _, err := readQuickTimeMetadata(path)
+	if err != nil {
+		return "", false
+	}
+	id, ok := quickTimeKeyValue(moov, quickTimeContentIDKey)
+	return id, ok && id != ""
+}
+
+// pairLivePhotos makes the photo and video of Live Photos share a date, so
+// that they end up in the same directory even if only one of them could be
+// dated, as pairRAWFiles does for RAW+JPEG pairs. The photo and video are in
+// the same directory, and either have the same base name, such as
+// IMG_0001.HEIC and IMG_0001.MOV, or the same content identifier in their
+// metadata. The date of the photo wins.
+func pairLivePhotos(files []datedFile) {
+	stem := func(path string) string {
+		return strings.ToLower(strings.TrimSuffix(path, filepath.Ext(path)))
+	}
+	photos := make(map[string]int)
+	var videos []int
+	for i, f := range files {
+		switch ext := strings.ToLower(filepath.Ext(f.path)); {
+		case livePhotoExtensions[ext]:
+			photos[stem(f.path)] = i
+		case ext == ".mov":
+			videos = append(videos, i)
+		}
+	}
+	if len(photos) == 0 {
+		return
+	}
+
+	// Videos not named like their photo (e.g. after exporting) are paired by
+	// content identifier, which is only read if there are any.
+	var unpaired []int
+	dirs := make(map[string]bool)
+	for _, i := range videos {
+		if j, ok := photos[stem(files[i].path)]; ok {
+			pairDates(&files[j], &files[i])
+			continue
+		}
+		unpaired = append(unpaired, i)
+		dirs[filepath.Dir(files[i].path)] = true
+	}
+	if len(unpaired) == 0 {
+		return
+	}
+	byID := make(map[string]int)
+	for _, j := range photos {
+		dir := filepath.Dir(files[j].path)
+		if !dirs[dir] {
+			continue
+		}
+		if id, ok := photoContentID(files[j].path); ok {
+			byID[filepath.Join(dir, id)] = j
+		}
+	}
+	for _, i := range unpaired {
+		id, ok := videoContentID(files[i].path)
+		if !ok {
+			continue
+		}
+		if j, ok := byID[filepath.Join(filepath.Dir(files[i].path), id)]; ok {
+			pairDates(&files[j], &files[i])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package organize
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// appleMakerNote returns an Apple maker note recording the given Live Photo
+// content identifier.
+func appleMakerNote(id string) string {
+	note := []byte(appleMakerNoteHeader + "\x00\x01MM")
+	note = append(note, 0, 1)
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry, appleContentIDTag)
+	binary.BigEndian.PutUint16(entry[2:], 2)
+	binary.BigEndian.PutUint32(entry[4:], uint32(len(id)+1))
+	binary.BigEndian.PutUint32(entry[8:], uint32(len(note)+12+4))
+	note = append(note, entry...)
+	note = append(note, 0, 0, 0, 0)
+	return string(append(note, id+"\x00"...))
+}
+
+// contentIDMovie returns a minimal QuickTime file recording the given Live
+// Photo content identifier.
+func contentIDMovie(id string) []byte {
+	keys := mkbox("keys", u32(0), u32(1), mkbox("mdta", []byte(quickTimeContentIDKey)))
+	ilst := mkbox("ilst", mkbox(string(u32(1)), mkbox("data", u32(1), u32(0), []byte(id))))
+	meta := mkbox("meta", mkbox("hdlr", make([]byte, 25)), keys, ilst)
+	return append(mkbox("ftyp", []byte("qt  "), u32(0)), mkbox("moov", meta)...)
+}
+
+func TestContentIDs(t *testing.T) {
+	dir := t.TempDir()
+	const id = "9F5A1C3E-0B7D-4E2A-8C61-3D2B7E4F1A90"
+	photo := filepath.Join(dir, "IMG_0001.JPG")
+	if err := os.WriteFile(photo, exifJPEG(nil, []tiffEntry{{exifMakerNote, appleMakerNote(id)}}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	video := filepath.Join(dir, "IMG_E0001.MOV")
+	if err := os.WriteFile(video, contentIDMovie(id), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, ok := photoContentID(photo); !ok || got != id {
+		t.Errorf("got %q (%v), want %q", got, ok, id)
+	}
+	if got, ok := videoContentID(video); !ok || got != id {
+		t.Errorf("got %q (%v), want %q", got, ok, id)
+	}
+
+	// The pair is found by content identifier despite the different names.
+	day := time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC)
+	files := []datedFile{
+		{sourceFile{path: photo}, day, false, nil},
+		{sourceFile{path: video}, time.Time{}, false, errors.New("no date")},
+	}
+	pairLivePhotos(files)
+	if files[1].err != nil || !files[1].date.Equal(day) {
+		t.Errorf("got %s (%v), want %s", files[1].date, files[1].err, day)
+	}
+}
+
+func TestPairLivePhotos(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2023, 3, d, 12, 0, 0, 0, time.UTC) }
+	errNoDate := errors.New("no date")
+	file := func(path string) sourceFile { return sourceFile{path: path} }
+	files := []datedFile{
+		{file("a/IMG_0001.HEIC"), day(15), false, nil},
+		{file("a/IMG_0001.MOV"), time.Time{}, false, errNoDate}, // Follows the photo.
+		{file("a/IMG_0002.JPG"), time.Time{}, false, errNoDate}, // Follows the video.
+		{file("a/IMG_0002.mov"), day(16), false, nil},
+		{file("a/IMG_0003.HEIC"), day(17), false, nil},
+		{file("a/IMG_0003.MOV"), day(18), false, nil}, // Recorded in UTC; follows the photo.
+		{file("b/IMG_0003.MOV"), day(19), false, nil}, // Different directory.
+		{file("a/IMG_0004.MOV"), time.Time{}, false, errNoDate},
+	}
+	pairLivePhotos(files)
+
+	want := []time.Time{day(15), day(15), day(16), day(16), day(17), day(17), day(19), {}}
+	for i, f := range files {
+		if !f.date.Equal(want[i]) {
+			t.Errorf("got %s, want %s (path: %s)", f.date, want[i], f.path)
+		}
+		if (f.err == nil) != !want[i].IsZero() {
+			t.Errorf("got error %v for %s", f.err, f.path)
+		}
+	}
+}
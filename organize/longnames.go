@@ -0,0 +1,112 @@
+package organize
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// minShortenedStem is the fewest bytes of the original name kept when
+	// shortening it; names that would keep less are not moved.
+	minShortenedStem = 8
+	// maxKeptExtension is the longest extension kept when shortening a name.
+	// Longer ones are presumably not extensions at all.
+	maxKeptExtension = 16
+)
+
+// fileNameLimits returns the longest file name and path, in bytes, that the
+// file systems of the current platform accept. Windows limits names and paths
+// in UTF-16 code units, which never outnumber the bytes of their UTF-8
+// encoding, and its paths to MAX_PATH (260 including the terminating NUL)
+// unless long paths are enabled.
+func fileNameLimits() (nameMax, pathMax int) {
+	switch runtime.GOOS {
+	case "windows":
+		return 255, 259
+	case "darwin", "ios":
+		return 255, 1023
+	default:
+		return 255, 4095
+	}
+}
+
+// shortenedFileName returns name if it is at most room bytes long, or else a
+// shortened name that is: the beginning of the original name followed by a
+// hash of it, so that distinct long names stay distinct, and the original
+// extension. It reports false if no such name fits.
+func shortenedFileName(name string, room int) (string, bool) {
+	if len(name) <= room {
+		return name, true
+	}
+	ext := filepath.Ext(name)
+	if len(ext) > maxKeptExtension {
+		ext = ""
+	}
+	stem := strings.TrimSuffix(name, ext)
+	sum := sha1.Sum([]byte(name))
+	suffix := "~" + hex.EncodeToString(sum[:4]) + ext
+	keep := room - len(suffix)
+	if keep < minShortenedStem {
+		return "", false
+	}
+	// Cut at a character boundary.
+	for keep > 0 && !utf8.RuneStart(stem[keep]) {
+		keep--
+	}
+	return stem[:keep] + suffix, true
+}
+
+// sidecarRoom returns how many bytes longer than the name of its media file
+// the name of the longest sidecar of m is, e.g. 4 for IMG_0001.jpg.xmp.
+func sidecarRoom(m PlannedMove) int {
+	room := 0
+	mediaName := filepath.Base(m.Src)
+	for _, sidecar := range m.Sidecars {
+		if extra := len(filepath.Base(sidecar)) - len(mediaName); extra > room {
+			room = extra
+		}
+	}
+	return room
+}
+
+// shortenLongNames renames the moves of p whose destination name or path, or
+// that of one of their sidecars, exceeds the limits of the platform, as some
+// export tools generate names of hundreds of characters. The original name is
+// logged, and kept as the source of the planned move. Files that cannot be
+// given a short enough name are left unmatched.
+func shortenLongNames(p *Plan) {
+	nameMax, pathMax := fileNameLimits()
+	moves := p.Moves[:0]
+	for _, m := range p.Moves {
+		name := m.Name
+		if name == "" {
+			name = filepath.Base(m.Src)
+		}
+		destDir := m.DestDir
+		if abs, err := filepath.Abs(destDir); err == nil {
+			destDir = abs
+		}
+		reserve := sidecarRoom(m)
+		room := nameMax - reserve
+		if r := pathMax - len(destDir) - len(string(filepath.Separator)) - reserve; r < room {
+			room = r
+		}
+		short, ok := shortenedFileName(name, room)
+		if !ok {
+			p.Unmatched = append(p.Unmatched, UnmatchedFile{m.Src, fmt.Sprintf("destination path in %q would be too long", m.DestDir), m.Sidecars, false})
+			continue
+		}
+		if short != name {
+			log.Printf("Shortening %q to %q", name, short)
+			m.Name = short
+		}
+		moves = append(moves, m)
+	}
+	p.Moves = moves
+}
@@ -0,0 +1,74 @@
+package organize
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShortenedFileName(t *testing.T) {
+	long := strings.Repeat("x", 300) + ".jpg"
+	tests := []struct {
+		name    string
+		room    int
+		wantLen int
+		wantOk  bool
+	}{
+		{"IMG_0001.jpg", 255, len("IMG_0001.jpg"), true},
+		{long, 255, 255, true},
+		{long, 100, 100, true},
+		{strings.Repeat("é", 200) + ".jpg", 100, 99, true},
+		{long, 20, 0, false},
+	}
+	for _, test := range tests {
+		got, ok := shortenedFileName(test.name, test.room)
+		if ok != test.wantOk {
+			t.Errorf("got ok %t, want %t (room: %d)", ok, test.wantOk, test.room)
+			continue
+		}
+		if len(got) != test.wantLen {
+			t.Errorf("got %d bytes (%q), want %d", len(got), got, test.wantLen)
+		}
+		if ok && filepath.Ext(got) != ".jpg" {
+			t.Errorf("got %q, want the .jpg extension kept", got)
+		}
+	}
+
+	other, _ := shortenedFileName(strings.Repeat("x", 300)+"y.jpg", 100)
+	if short, _ := shortenedFileName(long, 100); short == other {
+		t.Errorf("got %q for two different names, want distinct names", short)
+	}
+}
+
+func TestShortenLongNames(t *testing.T) {
+	nameMax, pathMax := fileNameLimits()
+	dest, err := filepath.Abs("dest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deep := filepath.Join(dest, strings.Repeat("d", pathMax-len(dest)-100))
+	longName := strings.Repeat("x", nameMax-6) + ".jpg"
+	p := Plan{Moves: []PlannedMove{
+		{Src: "IMG_0001.jpg", DestDir: dest},
+		{Src: longName, DestDir: dest, Sidecars: []string{longName + ".json"}},
+		{Src: longName, DestDir: deep},
+		{Src: "IMG_0002.jpg", DestDir: filepath.Join(deep, strings.Repeat("d", 95))},
+	}}
+	shortenLongNames(&p)
+
+	if len(p.Moves) != 3 {
+		t.Fatalf("got %d moves, want 3", len(p.Moves))
+	}
+	if p.Moves[0].Name != "" {
+		t.Errorf("got %q, want the name kept", p.Moves[0].Name)
+	}
+	if got, want := len(p.Moves[1].Name), nameMax-len(".json"); got != want {
+		t.Errorf("got %d bytes, want %d", got, want)
+	}
+	if got, want := len(filepath.Join(deep, p.Moves[2].Name)), pathMax; got != want {
+		t.Errorf("got a %d byte path, want %d", got, want)
+	}
+	if len(p.Unmatched) != 1 || p.Unmatched[0].Path != "IMG_0002.jpg" {
+		t.Errorf("got unmatched %v, want IMG_0002.jpg", p.Unmatched)
+	}
+}
@@ -0,0 +1,358 @@
+package organize
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// MediaFileMatcher represents an element capable of parsing date information
+// for a given file name. Each MediaFileMatcher is specifically intended to
+// handle certain file types and is capable of parsing date information from
+// those applicable file names. For example, a MediaFileMatcher intended to
+// match image files of format "IMG_YYYYMMDD_*.jpg" is capable of parsing out
+// the intended date in format YYYY-MM-DD but is unable to reliably do so for
+// other file formats it is not designed for.
+type MediaFileMatcher struct {
+	// name is a short human readable description of the file names handled by
+	// the matcher, used in diagnostics.
+	name             string
+	supportedRegexps []*regexp.Regexp
+	// extensions, if set, lists the (lower case) extensions of the files the
+	// matcher supports, in any letter case. The supportedRegexps then only
+	// describe the dates in their names.
+	extensions map[string]bool
+	// layout, if set, is the Go time layout of the text captured by the group
+	// named "date" in the supportedRegexps. The date is then parsed from the
+	// part of the name the pattern matched, and parseDate is not used.
+	layout    string
+	parseDate func(s string) (time.Time, error)
+	// zoned is set if the dates found by the matcher are capture times with a
+	// time zone, rather than the wall clock time of the device.
+	zoned bool
+	// rename, if set, is the template of the names files are moved as.
+	rename *template.Template
+}
+
+// MatchFileName determines whether or not the MediaFileMatcher supports the
+// file with name givey by the parameter s.
+func (m *MediaFileMatcher) MatchFileName(s string) bool {
+	if m.extensions != nil && !m.extensions[strings.ToLower(filepath.Ext(s))] {
+		return false
+	}
+	for _, re := range m.supportedRegexps {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDate parses the date encoded in the provided string `s`. Note that
+// calling ParseDate on file name for which MatchFileName returns false is not
+// deterministic and would most likely not provide meaningful results. A
+// non-nil error is returned if the encoded date is not a valid calendar date.
+//
+// Suggested usage pattern:
+//
+//	if (matcher.MatchFileName(s)) {
+//	  date, err := matcher.ParseDate(s)
+//	  // Do something with `date`.
+//	}
+func (m *MediaFileMatcher) ParseDate(s string) (time.Time, error) {
+	if m.layout == "" {
+		return m.parseDate(s)
+	}
+	for _, re := range m.supportedRegexps {
+		if re.MatchString(s) {
+			return patternDate(re, m.layout, s)
+		}
+	}
+	return time.Time{}, fmt.Errorf("no pattern of %s matches %q", m.name, s)
+}
+
+// NearMiss describes how the file name s falls short of being supported by
+// the MediaFileMatcher, or returns an empty string if s bears no resemblance
+// to the names it supports.
+func (m *MediaFileMatcher) NearMiss(s string) string {
+	if reason := m.extensionMiss(s); reason != "" {
+		return reason
+	}
+	for _, re := range m.supportedRegexps {
+		if reason := explainNearMiss(re, s); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// extensionMiss describes how the file name s is supported by the matcher but
+// for its extension, or returns an empty string.
+func (m *MediaFileMatcher) extensionMiss(s string) string {
+	ext := filepath.Ext(s)
+	if m.extensions == nil || m.extensions[strings.ToLower(ext)] {
+		return ""
+	}
+	for _, re := range m.supportedRegexps {
+		if re.MatchString(s) {
+			return fmt.Sprintf("name fits pattern %q but extension %q is not supported", re, ext)
+		}
+	}
+	return ""
+}
+
+// extensionPattern matches the extension at the end of a file name. The
+// built-in matchers' patterns end with it, leaving the supported extensions to
+// MediaFileMatcher.extensions.
+const extensionPattern = `\.\w+$`
+
+// mediaMatchers are the built-in matchers, tried in order.
+var mediaMatchers = []*MediaFileMatcher{
+	{
+		// Intended to match files of format
+		//  - IMG_YYYYMMDD_NUMBER.jpg
+		//  - VID_YYYYMMDD_NUMBER.mp4
+		//  - PXL_YYYYMMDD_NUMBER.{jpg,heic,dng,mp4}
+		name: "IMG/VID/PXL_YYYYMMDD_*",
+		supportedRegexps: []*regexp.Regexp{
+			regexp.MustCompile(`IMG_(?P<date>\d{8})_.+` + extensionPattern),
+			regexp.MustCompile(`VID_(?P<date>\d{8})_.+` + extensionPattern),
+			regexp.MustCompile(`PXL_(?P<date>\d{8})_.+` + extensionPattern),
+		},
+		extensions: mediaExtensions,
+		layout:     "20060102",
+	},
+	{
+		// Intended to match C360_YYYY-MM-DD-hh-mm-ss-mmm.jpg.
+		name: "C360_YYYY-MM-DD-hh-mm-ss-mmm",
+		supportedRegexps: []*regexp.Regexp{
+			regexp.MustCompile(`C360_(?P<date>\d{4}-\d\d-\d\d)-\d\d-\d\d-\d\d-\d{3}` + extensionPattern),
+		},
+		extensions: mediaExtensions,
+		layout:     "2006-01-02",
+	},
+	{
+		// Intended to match WhatsApp media, such as
+		//	- IMG-YYYYMMDD-WANUMBER.jpg
+		//	- VID-YYYYMMDD-WANUMBER.mp4
+		name: "IMG/VID-YYYYMMDD-WA*",
+		supportedRegexps: []*regexp.Regexp{
+			regexp.MustCompile(`IMG-(?P<date>\d{8})-WA.+` + extensionPattern),
+			regexp.MustCompile(`VID-(?P<date>\d{8})-WA.+` + extensionPattern),
+		},
+		extensions: mediaExtensions,
+		layout:     "20060102",
+	},
+	{
+		// Intended to match media saved from messengers, such as
+		//	- photo_YYYY-MM-DD_hh-mm-ss.jpg (Telegram)
+		//	- video_YYYY-MM-DD_hh-mm-ss.mp4 (Telegram)
+		//	- signal-YYYY-MM-DD-hhmmss.jpg (Signal)
+		name: "photo/video_YYYY-MM-DD_hh-mm-ss, signal-YYYY-MM-DD-*",
+		supportedRegexps: []*regexp.Regexp{
+			regexp.MustCompile(`(?:photo|video)_(?P<date>\d{4}-\d\d-\d\d)_\d\d-\d\d-\d\d.*` + extensionPattern),
+			regexp.MustCompile(`signal-(?P<date>\d{4}-\d\d-\d\d)-\d+.*` + extensionPattern),
+		},
+		extensions: mediaExtensions,
+		layout:     "2006-01-02",
+	},
+	{
+		// Intended to match files of format
+		//	- YYYYMMDD_NUMBER.jpg
+		//	- YYYYMMDD_NUMBER.mp4
+		name: "YYYYMMDD_*",
+		supportedRegexps: []*regexp.Regexp{
+			regexp.MustCompile(`(?P<date>\d{8})_.+` + extensionPattern),
+		},
+		extensions: mediaExtensions,
+		layout:     "20060102",
+	},
+	{
+		// Intended to match timestamps with a UTC offset, such as
+		//	- 2023-03-15T14-22-33+0200.jpg
+		//	- 2023-03-15 14.22.33Z.mp4
+		name: "YYYY-MM-DDThh-mm-ss+hhmm",
+		supportedRegexps: []*regexp.Regexp{
+			zonedTimestampRegexp,
+		},
+		extensions: mediaExtensions,
+		parseDate:  parseZonedTimestamp,
+		zoned:      true,
+	},
+	{
+		// Intended to match ISO 8601 style timestamps such as
+		//	- 2023-03-15 14.22.33.jpg
+		//	- 2023-03-15T142233.mp4
+		//	- 20230315T142233Z.jpg
+		name:             "ISO 8601 timestamp",
+		supportedRegexps: isoTimestampRegexps,
+		extensions:       mediaExtensions,
+		parseDate:        parseISOTimestamp,
+		zoned:            true,
+	},
+	{
+		// Intended to match screen recordings (and screenshots) such as
+		//	- Screen Recording 2023-03-15 at 14.22.33.mov (macOS)
+		//	- Screenshot 2023-03-15 at 14.22.33.png (macOS)
+		//	- Screenrecorder-2023-03-15-14-22-33-123.mp4 (Android)
+		name: "Screen Recording YYYY-MM-DD at hh.mm.ss",
+		supportedRegexps: []*regexp.Regexp{
+			regexp.MustCompile(`^(?:Screen Recording|Screen Shot|Screenshot) (?P<date>\d{4}-\d\d-\d\d) at \d{1,2}\.\d\d\.\d\d.*` + extensionPattern),
+			regexp.MustCompile(`^Screenrecorder-(?P<date>\d{4}-\d\d-\d\d)-\d\d-\d\d-\d\d(?:-\d+)?` + extensionPattern),
+		},
+		extensions: mediaExtensions,
+		layout:     "2006-01-02",
+	},
+	{
+		// Intended to match files with textual month names, in a number of
+		// languages, such as
+		//	- 15 Mar 2023 - beach.jpg
+		//	- 2023-Mar-15.heic
+		name: "DD Month YYYY / YYYY-Month-DD",
+		supportedRegexps: []*regexp.Regexp{
+			dayMonthYearRegexp,
+			yearMonthDayRegexp,
+		},
+		extensions: mediaExtensions,
+		parseDate:  parseTextualMonthDate,
+	},
+}
+
+// NewPatternMatcher creates a MediaFileMatcher from a user supplied regular
+// expression. If layout is empty, pattern must contain the named groups
+// "year", "month" and "day". Otherwise the text captured by the group named
+// "date" (or the first group, or the whole match if pattern has no groups) is
+// parsed using layout, which is a Go time layout such as "20060102".
+func NewPatternMatcher(pattern, layout string) (*MediaFileMatcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	if layout == "" {
+		for _, group := range []string{"year", "month", "day"} {
+			if re.SubexpIndex(group) < 0 {
+				return nil, fmt.Errorf("pattern %q has no (?P<%s>...) group and no layout was given", pattern, group)
+			}
+		}
+	}
+	return &MediaFileMatcher{
+		name:             pattern,
+		supportedRegexps: []*regexp.Regexp{re},
+		parseDate: func(s string) (time.Time, error) {
+			return patternDate(re, layout, s)
+		},
+		zoned: layoutHasZone(layout),
+	}, nil
+}
+
+// layoutHasZone reports whether the Go time layout includes a time zone.
+func layoutHasZone(layout string) bool {
+	for _, zone := range []string{"MST", "Z07", "-07"} {
+		if strings.Contains(layout, zone) {
+			return true
+		}
+	}
+	return false
+}
+
+// patternDate extracts the date from s as described for NewPatternMatcher.
+func patternDate(re *regexp.Regexp, layout, s string) (time.Time, error) {
+	m := re.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("pattern %q does not match %q", re, s)
+	}
+	if layout == "" {
+		var vals [3]int
+		for i, group := range []string{"year", "month", "day"} {
+			v, err := strconv.Atoi(m[re.SubexpIndex(group)])
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid %s in %q: %v", group, s, err)
+			}
+			vals[i] = v
+		}
+		date := time.Date(vals[0], time.Month(vals[1]), vals[2], 0, 0, 0, 0, time.UTC)
+		if date.Year() != vals[0] || int(date.Month()) != vals[1] || date.Day() != vals[2] {
+			return time.Time{}, fmt.Errorf("invalid date %04d-%02d-%02d in %q", vals[0], vals[1], vals[2], s)
+		}
+		return date, nil
+	}
+	text := m[0]
+	if i := re.SubexpIndex("date"); i >= 0 {
+		text = m[i]
+	} else if re.NumSubexp() > 0 {
+		text = m[1]
+	}
+	date, err := time.Parse(layout, text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q with layout %q: %v", text, layout, err)
+	}
+	return date, nil
+}
+
+// getFolderName accepts a file name and returns name that would be appropriate
+// to store that given file, according to the built-in matchers. If no such
+// folder name can be determined then this function returns a non-nil error.
+func getFolderName(fileName string) (string, error) {
+	date, err := getDate(mediaMatchers, fileName)
+	if err != nil {
+		return "", err
+	}
+	return date.Format("2006-01-02"), nil
+}
+
+// invalidDateError reports a file name that a matcher supports, but whose
+// date is not a valid calendar date.
+type invalidDateError struct {
+	fileName string
+	err      error
+}
+
+func (e *invalidDateError) Error() string {
+	return fmt.Sprintf("invalid date in %q: %v", e.fileName, e.err)
+}
+
+// nameMatcher returns the first of matchers that both supports fileName and
+// finds a valid date in it, or nil.
+func nameMatcher(matchers []*MediaFileMatcher, fileName string) *MediaFileMatcher {
+	for _, matcher := range matchers {
+		if !matcher.MatchFileName(fileName) {
+			continue
+		}
+		if _, err := matcher.ParseDate(fileName); err == nil {
+			return matcher
+		}
+	}
+	return nil
+}
+
+// getDate returns the date encoded in fileName by the first of matchers that
+// both supports the name and finds a valid date in it.
+func getDate(matchers []*MediaFileMatcher, fileName string) (time.Time, error) {
+	date, _, err := matchDate(matchers, fileName)
+	return date, err
+}
+
+// matchDate is like getDate, and also returns the matcher that found the date.
+func matchDate(matchers []*MediaFileMatcher, fileName string) (time.Time, *MediaFileMatcher, error) {
+	var parseErr error
+	for _, matcher := range matchers {
+		if !matcher.MatchFileName(fileName) {
+			continue
+		}
+		date, err := matcher.ParseDate(fileName)
+		if err == nil {
+			return date, matcher, nil
+		}
+		if parseErr == nil {
+			parseErr = &invalidDateError{fileName, err}
+		}
+	}
+	if parseErr != nil {
+		return time.Time{}, nil, parseErr
+	}
+	return time.Time{}, nil, fmt.Errorf("no matcher found for %q", fileName)
+}
@@ -0,0 +1,121 @@
+package organize
+
+import "testing"
+
+func TestGetFolderName(t *testing.T) {
+	tests := []struct {
+		fileName           string
+		expectedFolderName string
+		errExpected        bool
+	}{
+		{"1234", "", true}, // No matcher should exist for this, so expect an error.
+		{"IMG_20210222_213525.jpg", "2021-02-22", false},
+		{"VID_20201012_124124.mp4", "2020-10-12", false},
+		{"IMG_20211341_213525.jpg", "", true}, // Not a valid calendar date.
+		{"VID_20201012_124124_325_someextrastuff.mp4", "2020-10-12", false},
+		{"PXL_20210123_124124.mp4", "2021-01-23", false},
+		{"PXL_19891211_124124.jpg", "1989-12-11", false},
+		{"IMG_20210222_213525.JPG", "2021-02-22", false}, // Extensions are case-insensitive.
+		{"IMG_20210222_213525.jpeg", "2021-02-22", false},
+		{"IMG_20210222_213525.png", "2021-02-22", false},
+		{"IMG_20210222_213525.gif", "2021-02-22", false},
+		{"VID_20210222_213525.MOV", "2021-02-22", false},
+		{"VID_20210222_213525.avi", "2021-02-22", false},
+		{"VID_20210222_213525.3gp", "2021-02-22", false},
+		{"VID_20210222_213525.mkv", "2021-02-22", false},
+		{"VID_20210222_213525.webm", "2021-02-22", false},
+		{"IMG_20210222_213525.txt", "", true}, // Not a media file.
+		{"C360_2019-07-17-04-02-45-169.JPG", "2019-07-17", false},
+		{"IMG_20230315_142233.heic", "2023-03-15", false},
+		{"PXL_20230315_142233123.heic", "2023-03-15", false},
+		{"20230315_142233.heic", "2023-03-15", false},
+		{"C360_2019-07-17-04-02-45-169.jpg", "2019-07-17", false},
+		{"C360_2019-07-17-04-02-45-169-12.jpg", "", true},
+		{"C360_2019-07-17-169.jpg", "", true},
+		{"IMG-20230415-WA0012.jpg", "2023-04-15", false},
+		{"VID-20230415-WA0003.mp4", "2023-04-15", false},
+		{"IMG-20230415-WA0012 (1).JPEG", "2023-04-15", false},
+		{"IMG-20230431-WA0012.jpg", "", true},  // April has 30 days.
+		{"IMG-20230415-WA0012.opus", "", true}, // Not a media file.
+		{"photo_2023-04-15_12-30-45.jpg", "2023-04-15", false},
+		{"video_2023-04-15_12-30-45 (2).mp4", "2023-04-15", false},
+		{"signal-2023-04-15-123045.jpg", "2023-04-15", false},
+		{"signal-2023-04-15-12-30-45-123.png", "2023-04-15", false},
+		{"signal-2023-02-30-123045.jpg", "", true}, // Not a calendar date.
+		{"20170402_1979.jpg", "2017-04-02", false},
+		{"20181030_1985.mp4", "2018-10-30", false},
+		{"2023-03-15T14-22-33+0200.jpg", "2023-03-15", false},
+		{"2023-03-15T23-22-33-0500.MP4", "2023-03-15", false}, // In the camera's time zone.
+		{"2023-03-15T14:22:33+02:00_edit.jpg", "2023-03-15", false},
+		{"2023-03-15T14-22-33+2500.jpg", "", true}, // Invalid offset.
+		{"2023-03-15 14.22.33.jpg", "2023-03-15", false},
+		{"2023-03-15T142233.mp4", "2023-03-15", false},
+		{"2023-03-15 14.22.33-2.png", "2023-03-15", false},
+		{"20230315T142233.jpg", "2023-03-15", false},
+		{"2023-02-30 14.22.33.jpg", "", true}, // Not a calendar date.
+		{"Screen Recording 2023-03-15 at 14.22.33.mov", "2023-03-15", false},
+		{"Screen Shot 2021-02-22 at 9.05.01 PM.png", "2021-02-22", false},
+		{"Screenrecorder-2023-03-15-14-22-33-123.mp4", "2023-03-15", false},
+		{"15 Mar 2023 - beach.jpg", "2023-03-15", false},
+		{"2023-Mar-15.heic", "2023-03-15", false},
+		{"3. März 2021.JPG", "2021-03-03", false},
+		{"15-févr-2022 soirée.mov", "2022-02-15", false},
+		{"1 dic 2019.png", "2019-12-01", false},
+		{"31 Apr 2023.jpg", "", true}, // April has 30 days.
+		{"15 Foo 2023.jpg", "", true}, // Unknown month name.
+		{"15 Mar 2023.txt", "", true}, // Not a media file.
+	}
+
+	for _, tt := range tests {
+		name, err := getFolderName(tt.fileName)
+		if err != nil && !tt.errExpected {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+		if tt.errExpected && err == nil {
+			t.Error("Expected error but received none")
+		}
+		if name != tt.expectedFolderName {
+			t.Errorf("got %s, want %s (file name: %s)", name, tt.expectedFolderName, tt.fileName)
+		}
+	}
+}
+
+func TestPatternMatcher(t *testing.T) {
+	tests := []struct {
+		pattern, layout    string
+		fileName           string
+		expectedFolderName string
+		errExpected        bool
+	}{
+		{`^DSC_(?P<year>\d{4})(?P<month>\d\d)(?P<day>\d\d)`, "", "DSC_20210222_0001.jpg", "2021-02-22", false},
+		{`^DSC_(?P<year>\d{4})(?P<month>\d\d)(?P<day>\d\d)`, "", "DSC_20211341_0001.jpg", "", true},
+		{`^DSC_(\d{8})`, "20060102", "DSC_20210222_0001.jpg", "2021-02-22", false},
+		{`^shot-(?P<date>\d\d\.\d\d\.\d{4})`, "02.01.2006", "shot-22.02.2021.png", "2021-02-22", false},
+		{`^DSC_(\d{8})`, "20060102", "IMG_20210222_0001.jpg", "", true},
+	}
+
+	for _, tt := range tests {
+		m, err := NewPatternMatcher(tt.pattern, tt.layout)
+		if err != nil {
+			t.Fatalf("NewPatternMatcher(%q, %q) returned error: %v", tt.pattern, tt.layout, err)
+		}
+		date, err := patternDate(m.supportedRegexps[0], tt.layout, tt.fileName)
+		if err != nil && !tt.errExpected {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+		if tt.errExpected && err == nil {
+			t.Errorf("Expected error but received none (file name: %s)", tt.fileName)
+		}
+		if err == nil && date.Format("2006-01-02") != tt.expectedFolderName {
+			t.Errorf("got %s, want %s (file name: %s)", date.Format("2006-01-02"), tt.expectedFolderName, tt.fileName)
+		}
+	}
+}
+
+func TestNewPatternMatcherErrors(t *testing.T) {
+	for _, pattern := range []string{`IMG_(\d{8}`, `IMG_(?P<year>\d{4})`} {
+		if _, err := NewPatternMatcher(pattern, ""); err == nil {
+			t.Errorf("NewPatternMatcher(%q) expected error but received none", pattern)
+		}
+	}
+}
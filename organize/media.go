@@ -0,0 +1,47 @@
+package organize
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// imageExtensions lists the (lower case) extensions of image files.
+var imageExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".heic": true,
+	".heif": true, ".tif": true, ".tiff": true, ".bmp": true, ".webp": true,
+	".dng": true, ".cr2": true, ".cr3": true, ".nef": true, ".arw": true,
+	".raf": true, ".orf": true, ".rw2": true,
+}
+
+// videoExtensions lists the (lower case) extensions of video files.
+var videoExtensions = map[string]bool{
+	".mp4": true, ".mov": true, ".avi": true, ".mts": true, ".m2ts": true,
+	".mkv": true, ".3gp": true, ".webm": true, ".mpg": true,
+}
+
+// mediaExtensions lists the (lower case) extensions of image and video files.
+var mediaExtensions = func() map[string]bool {
+	extensions := make(map[string]bool, len(imageExtensions)+len(videoExtensions))
+	for ext := range imageExtensions {
+		extensions[ext] = true
+	}
+	for ext := range videoExtensions {
+		extensions[ext] = true
+	}
+	return extensions
+}()
+
+// isImage reports whether fileName names an image file.
+func isImage(fileName string) bool {
+	return imageExtensions[strings.ToLower(filepath.Ext(fileName))]
+}
+
+// isVideo reports whether fileName names a video file.
+func isVideo(fileName string) bool {
+	return videoExtensions[strings.ToLower(filepath.Ext(fileName))]
+}
+
+// IsMedia reports whether fileName names an image or video file.
+func IsMedia(fileName string) bool {
+	return mediaExtensions[strings.ToLower(filepath.Ext(fileName))]
+}
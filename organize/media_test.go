@@ -0,0 +1,36 @@
+package organize
+
+import "testing"
+
+func TestIsVideo(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     bool
+	}{
+		{"VID_20201012_124124.mp4", true},
+		{"00000.MTS", true},
+		{"clip.MOV", true},
+		{"IMG_20210222_213525.jpg", false},
+		{"mp4", false},
+	}
+
+	for _, tt := range tests {
+		if got := isVideo(tt.fileName); got != tt.want {
+			t.Errorf("isVideo(%q) = %v, want %v", tt.fileName, got, tt.want)
+		}
+	}
+}
+
+func TestIsMedia(t *testing.T) {
+	for name, want := range map[string]bool{
+		"IMG_1234.HEIC": true,
+		"PICT0012.JPG":  true,
+		"clip.mkv":      true,
+		"notes.txt":     false,
+		"Makefile":      false,
+	} {
+		if got := IsMedia(name); got != want {
+			t.Errorf("IsMedia(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package organize
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MergeRule selects which of the directories of a date, such as 2021-02-22
+// and "2021-02-22 Birthday" left by manual sorting, the others are merged
+// into.
+type MergeRule string
+
+const (
+	// MergeDescriptive merges the directory named by the date alone into the
+	// one with a description. Several descriptions for a date are presumably
+	// different events, so such dates are left alone.
+	MergeDescriptive MergeRule = "descriptive"
+	// MergePlain merges the directories with a description into the one
+	// named by the date alone.
+	MergePlain MergeRule = "plain"
+)
+
+// String implements flag.Value.
+func (r *MergeRule) String() string {
+	return string(*r)
+}
+
+// Set implements flag.Value.
+func (r *MergeRule) Set(s string) error {
+	switch rule := MergeRule(s); rule {
+	case MergeDescriptive, MergePlain:
+		*r = rule
+		return nil
+	}
+	return fmt.Errorf("unknown merge rule %q, want %q or %q", s, MergeDescriptive, MergePlain)
+}
+
+// FolderMerge is the merging of the directories of a date into one.
+type FolderMerge struct {
+	Into string   `json:"into"`
+	From []string `json:"from"`
+	// Conflicts are the files left in place because a file of the same name
+	// is already in Into.
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// MergeFolders merges the directories of the organized tree at root dated the
+// same by the Organizer's layout, where one is named by the date alone and
+// another by the date followed by a space and a description, per rule. The
+// files of the merged directories are moved, recorded in the journal if any,
+// and the playlists and Recent links listing them are updated; the merged
+// directories are removed if left empty. In a dry run, the merges are only
+// returned.
+func (o *Organizer) MergeFolders(root string, rule MergeRule) ([]FolderMerge, error) {
+	if o.opts.folderNamer != nil {
+		return nil, errors.New("only trees organized by a layout can be merged")
+	}
+	dirs, err := dateDirs(root, o.opts.layout)
+	if err != nil {
+		return nil, err
+	}
+	var plains []string
+	for plain := range dirs {
+		plains = append(plains, plain)
+	}
+	sort.Strings(plains)
+
+	var merges []FolderMerge
+	for _, plain := range plains {
+		described := dirs[plain].described
+		if !dirs[plain].plain || len(described) == 0 {
+			continue
+		}
+		merge := FolderMerge{Into: plain, From: described}
+		if rule == MergeDescriptive {
+			if len(described) > 1 {
+				log.Printf("Not merging %q, as it has several descriptions: %s", plain, strings.Join(described, ", "))
+				continue
+			}
+			merge = FolderMerge{Into: described[0], From: []string{plain}}
+		}
+		for _, from := range merge.From {
+			conflicts, err := o.mergeFolder(root, from, merge.Into, dirs[plain].date)
+			merge.Conflicts = append(merge.Conflicts, conflicts...)
+			if err != nil {
+				return append(merges, merge), err
+			}
+		}
+		merges = append(merges, merge)
+	}
+	return merges, nil
+}
+
+// dateDirSet is the directories of a date in an organized tree.
+type dateDirSet struct {
+	date time.Time
+	// plain is whether the directory named by the date alone exists.
+	plain bool
+	// described are the directories named by the date and a description.
+	described []string
+}
+
+// dateDirs returns the directories of the organized tree at root by the
+// slash separated path of the directory named by their date alone.
+func dateDirs(root, layout string) (map[string]*dateDirSet, error) {
+	dirs := make(map[string]*dateDirSet)
+	err := fs.WalkDir(os.DirFS(root), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || p == "." {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir
+		}
+		class, dated := splitClassDir(p)
+		base := path.Base(dated)
+		described := false
+		if i := strings.Index(base, " "); i > 0 {
+			dated, described = path.Join(path.Dir(dated), base[:i]), true
+		}
+		date, err := time.ParseInLocation(layout, dated, time.Local)
+		if err != nil || date.Format(layout) != dated {
+			return nil
+		}
+		plain := path.Join(class, dated)
+		set := dirs[plain]
+		if set == nil {
+			set = &dateDirSet{date: date}
+			dirs[plain] = set
+		}
+		if described {
+			set.described = append(set.described, p)
+		} else {
+			set.plain = true
+		}
+		return fs.SkipDir
+	})
+	return dirs, err
+}
+
+// mergeFolder moves the files of the directory from of the organized tree at
+// root into the directory into, both of the given date. It returns the files
+// left in place because into has a file of the same name.
+func (o *Organizer) mergeFolder(root, from, into string, date time.Time) ([]string, error) {
+	srcDir := filepath.Join(root, filepath.FromSlash(from))
+	destDir := filepath.Join(root, filepath.FromSlash(into))
+	files, err := dirFiles(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []string
+	for _, f := range files {
+		dest := filepath.Join(destDir, filepath.Base(f.path))
+		if _, err := os.Lstat(dest); err == nil {
+			conflicts = append(conflicts, f.path)
+			continue
+		}
+		if o.opts.dryRun {
+			continue
+		}
+		if !moveFile(f.path, dest, "", o.opts) {
+			return conflicts, fmt.Errorf("unable to move %q", f.path)
+		}
+		if o.opts.journal != "" {
+			if err := recordMove(f.path, dest, o.opts); err != nil {
+				return conflicts, fmt.Errorf("moved %q but unable to record it in the journal: %v", f.path, err)
+			}
+		}
+		listed, err := removeFromPlaylists(root, f.path)
+		if err != nil {
+			return conflicts, err
+		}
+		if listed {
+			if err := addToPlaylist(root, date, dest); err != nil {
+				return conflicts, err
+			}
+		}
+		if err := relinkRecent(root, f.path, dest); err != nil {
+			return conflicts, err
+		}
+	}
+	if !o.opts.dryRun {
+		// Removing a directory that isn't empty fails harmlessly.
+		os.Remove(srcDir)
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,88 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeFolders(t *testing.T) {
+	tests := []struct {
+		rule      MergeRule
+		dryRun    bool
+		want      []FolderMerge
+		wantFiles []string
+	}{
+		{
+			MergeDescriptive, false,
+			[]FolderMerge{{Into: "2021-02-22 Birthday", From: []string{"2021-02-22"}, Conflicts: []string{"2021-02-22/IMG_0002.jpg"}}},
+			[]string{"2021-02-22 Birthday/IMG_0001.jpg", "2021-02-22 Birthday/IMG_0002.jpg", "2021-02-22/IMG_0002.jpg", "2021-02-23 Hike/IMG_0004.jpg", "2021-02-24 Party/IMG_0005.jpg", "2021-02-24 Zoo/IMG_0006.jpg", "2021-02-24/IMG_0007.jpg"},
+		},
+		{
+			MergePlain, false,
+			[]FolderMerge{
+				{Into: "2021-02-22", From: []string{"2021-02-22 Birthday"}, Conflicts: []string{"2021-02-22 Birthday/IMG_0002.jpg"}},
+				{Into: "2021-02-24", From: []string{"2021-02-24 Party", "2021-02-24 Zoo"}},
+			},
+			[]string{"2021-02-22/IMG_0001.jpg", "2021-02-22/IMG_0002.jpg", "2021-02-22 Birthday/IMG_0002.jpg", "2021-02-23 Hike/IMG_0004.jpg", "2021-02-24/IMG_0005.jpg", "2021-02-24/IMG_0006.jpg", "2021-02-24/IMG_0007.jpg"},
+		},
+		{
+			MergePlain, true,
+			[]FolderMerge{
+				{Into: "2021-02-22", From: []string{"2021-02-22 Birthday"}, Conflicts: []string{"2021-02-22 Birthday/IMG_0002.jpg"}},
+				{Into: "2021-02-24", From: []string{"2021-02-24 Party", "2021-02-24 Zoo"}},
+			},
+			[]string{"2021-02-22 Birthday/IMG_0001.jpg", "2021-02-24 Party/IMG_0005.jpg"},
+		},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		for _, p := range []string{"2021-02-22/IMG_0002.jpg", "2021-02-22 Birthday/IMG_0001.jpg", "2021-02-22 Birthday/IMG_0002.jpg", "2021-02-23 Hike/IMG_0004.jpg", "2021-02-24 Party/IMG_0005.jpg", "2021-02-24 Zoo/IMG_0006.jpg", "2021-02-24/IMG_0007.jpg"} {
+			path := filepath.Join(root, filepath.FromSlash(p))
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(p), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := addToPlaylist(root, time.Date(2021, 2, 22, 0, 0, 0, 0, time.Local), filepath.Join(root, "2021-02-22 Birthday", "IMG_0001.jpg")); err != nil {
+			t.Fatal(err)
+		}
+		o, err := New(WithExternalTools(false), WithDryRun(tt.dryRun))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := o.MergeFolders(root, tt.rule)
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		for i := range tt.want {
+			for j, c := range tt.want[i].Conflicts {
+				tt.want[i].Conflicts[j] = filepath.Join(root, filepath.FromSlash(c))
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got %+v, want %+v (rule: %s)", got, tt.want, tt.rule)
+		}
+		for _, p := range tt.wantFiles {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(p))); err != nil {
+				t.Errorf("Expected no error but received: %s (rule: %s)", err, tt.rule)
+			}
+		}
+		if tt.rule == MergePlain && !tt.dryRun {
+			playlist, err := os.ReadFile(filepath.Join(root, "2021-02-22.m3u"))
+			if err != nil {
+				t.Fatalf("Expected no error but received: %s", err)
+			}
+			if got, want := string(playlist), "#EXTM3U\n2021-02-22/IMG_0001.jpg\n"; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+			if _, err := os.Stat(filepath.Join(root, "2021-02-24 Zoo")); !os.IsNotExist(err) {
+				t.Errorf("Expected the merged directory to be removed, but received: %v", err)
+			}
+		}
+	}
+}
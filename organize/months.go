@@ -0,0 +1,90 @@
+package organize
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// monthNames lists, per locale, the full and abbreviated month names commonly
+// found in file names produced by export tools. Each row holds the names for
+// January through December.
+var monthNames = [][12]string{
+	// English.
+	{"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"},
+	{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"},
+	{"jan", "feb", "mar", "apr", "may", "june", "july", "aug", "sept", "oct", "nov", "dec"},
+	// German.
+	{"januar", "februar", "märz", "april", "mai", "juni", "juli", "august", "september", "oktober", "november", "dezember"},
+	{"jän", "feb", "mär", "apr", "mai", "jun", "jul", "aug", "sep", "okt", "nov", "dez"},
+	{"jänner", "feber", "maerz", "apr", "mai", "jun", "jul", "aug", "sep", "okt", "nov", "dez"},
+	{"jan", "feb", "mrz", "apr", "mai", "jun", "jul", "aug", "sep", "okt", "nov", "dez"},
+	// French.
+	{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	{"janv", "févr", "mars", "avr", "mai", "juin", "juil", "août", "sept", "oct", "nov", "déc"},
+	{"janvier", "fevrier", "mars", "avril", "mai", "juin", "juillet", "aout", "septembre", "octobre", "novembre", "decembre"},
+	{"janv", "fevr", "mars", "avr", "mai", "juin", "juil", "aout", "sept", "oct", "nov", "dec"},
+	// Spanish.
+	{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"},
+	{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "setiembre", "oct", "nov", "dic"},
+	// Italian.
+	{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+	{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+	// Dutch.
+	{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+	{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+	// Portuguese.
+	{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+	{"janeiro", "fevereiro", "marco", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+}
+
+// monthsByName maps a lower case month name, in any of the supported locales,
+// to its month.
+var monthsByName = func() map[string]time.Month {
+	months := make(map[string]time.Month)
+	for _, names := range monthNames {
+		for i, name := range names {
+			if m, ok := months[name]; ok && m != time.Month(i+1) {
+				panic(fmt.Sprintf("month name %q is ambiguous: %v or %v", name, m, time.Month(i+1)))
+			}
+			months[name] = time.Month(i + 1)
+		}
+	}
+	return months
+}()
+
+var (
+	// dayMonthYearRegexp matches e.g. "15 Mar 2023 - beach.jpg".
+	dayMonthYearRegexp = regexp.MustCompile(`^(?P<day>\d{1,2})[ ._-]+(?P<month>\pL+)\.?[ ._-]+(?P<year>\d{4})(?:\D.*)?` + extensionPattern)
+	// yearMonthDayRegexp matches e.g. "2023-Mar-15.heic".
+	yearMonthDayRegexp = regexp.MustCompile(`^(?P<year>\d{4})[ ._-]+(?P<month>\pL+)\.?[ ._-]+(?P<day>\d{1,2})(?:\D.*)?` + extensionPattern)
+)
+
+// parseTextualMonthDate parses the date out of a file name matched by either
+// dayMonthYearRegexp or yearMonthDayRegexp.
+func parseTextualMonthDate(s string) (time.Time, error) {
+	re := dayMonthYearRegexp
+	if !re.MatchString(s) {
+		re = yearMonthDayRegexp
+	}
+	m := re.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("no textual date found in %q", s)
+	}
+	monthName := m[re.SubexpIndex("month")]
+	month, ok := monthsByName[strings.ToLower(monthName)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown month name %q", monthName)
+	}
+	year, _ := strconv.Atoi(m[re.SubexpIndex("year")])
+	day, _ := strconv.Atoi(m[re.SubexpIndex("day")])
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Month() != month || date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid day %d of %v %d", day, month, year)
+	}
+	return date, nil
+}
@@ -0,0 +1,78 @@
+package organize
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// MultipleDatesPolicy selects the date used for file names containing more
+// than one date, such as "IMG_20230101_copy_of_20221225.jpg".
+type MultipleDatesPolicy string
+
+const (
+	// MultipleDatesFirst uses the first date in the name.
+	MultipleDatesFirst MultipleDatesPolicy = "first"
+	// MultipleDatesLast uses the last date in the name.
+	MultipleDatesLast MultipleDatesPolicy = "last"
+	// MultipleDatesMetadata uses the date in the name that agrees with the
+	// file's metadata, falling back to the first date in the name.
+	MultipleDatesMetadata MultipleDatesPolicy = "metadata"
+)
+
+// String implements flag.Value.
+func (p *MultipleDatesPolicy) String() string {
+	return string(*p)
+}
+
+// Set implements flag.Value.
+func (p *MultipleDatesPolicy) Set(s string) error {
+	switch policy := MultipleDatesPolicy(s); policy {
+	case MultipleDatesFirst, MultipleDatesLast, MultipleDatesMetadata:
+		*p = policy
+		return nil
+	}
+	return fmt.Errorf("unknown policy %q, want %q, %q or %q", s, MultipleDatesFirst, MultipleDatesLast, MultipleDatesMetadata)
+}
+
+// resolveMultipleDates returns the date to use for the file at path, whose
+// name was matched with the given date, according to opts.multipleDates. Only
+// the day of the matcher's date is changed, keeping the time of day and time
+// zone it found. A warning naming the date used is logged if the name
+// contains several different dates.
+func resolveMultipleDates(path, fileName string, date time.Time, opts options) time.Time {
+	candidates := findDateCandidates(fileName)
+	if distinctDates(candidates) < 2 {
+		return date
+	}
+	chosen := onDay(date, candidates[0].date)
+	switch opts.multipleDates {
+	case MultipleDatesLast:
+		chosen = onDay(date, candidates[len(candidates)-1].date)
+	case MultipleDatesMetadata:
+		if metaDate, err := metadataDate(path, opts); err == nil {
+			for _, c := range candidates {
+				if c.date.Format("2006-01-02") == metaDate.Format("2006-01-02") {
+					chosen = onDay(date, c.date)
+					break
+				}
+			}
+		}
+	}
+	var dates []string
+	for _, c := range candidates {
+		dates = append(dates, c.date.Format("2006-01-02"))
+	}
+	log.Printf("%q contains several dates (%s); using %s per the %q policy", fileName, strings.Join(dates, ", "), chosen.Format("2006-01-02"), opts.multipleDates)
+	return chosen
+}
+
+// onDay returns date moved to the calendar day of day, at the same time of
+// day and in the same time zone, unless they are on the same day already.
+func onDay(date, day time.Time) time.Time {
+	if sameDay(date, day) {
+		return date
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+}
@@ -0,0 +1,70 @@
+package organize
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveMultipleDates(t *testing.T) {
+	matched := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		fileName string
+		policy   MultipleDatesPolicy
+		want     string
+	}{
+		{"IMG_20230101_copy_of_20221225.jpg", MultipleDatesFirst, "2023-01-01"},
+		{"IMG_20230101_copy_of_20221225.jpg", MultipleDatesLast, "2022-12-25"},
+		// Without metadata the first date is used.
+		{"IMG_20230101_copy_of_20221225.jpg", MultipleDatesMetadata, "2023-01-01"},
+		{"IMG_20230101_20230101.jpg", MultipleDatesLast, "2023-01-01"},
+		{"IMG_20230101_213525.jpg", MultipleDatesLast, "2023-01-01"},
+		// The matcher's date is not the first date in the name.
+		{"20221225_IMG_20230101.jpg", MultipleDatesFirst, "2022-12-25"},
+		{"20221225_IMG_20230101.jpg", MultipleDatesMetadata, "2022-12-25"},
+	}
+
+	for _, tt := range tests {
+		opts := options{multipleDates: tt.policy}
+		got := resolveMultipleDates("/nonexistent/"+tt.fileName, tt.fileName, matched, opts)
+		if got.Format("2006-01-02") != tt.want {
+			t.Errorf("with %s policy got %s, want %s (file name: %s)", tt.policy, got.Format("2006-01-02"), tt.want, tt.fileName)
+		}
+	}
+}
+
+func TestResolveMultipleDatesKeepsTime(t *testing.T) {
+	zone := time.FixedZone("CET", 60*60)
+	matched := time.Date(2023, 1, 1, 21, 35, 25, 0, zone)
+	fileName := "IMG_20230101_copy_of_20221225.jpg"
+	tests := []struct {
+		policy MultipleDatesPolicy
+		want   time.Time
+	}{
+		{MultipleDatesFirst, matched},
+		{MultipleDatesLast, time.Date(2022, 12, 25, 21, 35, 25, 0, zone)},
+	}
+
+	for _, tt := range tests {
+		got := resolveMultipleDates("/nonexistent/"+fileName, fileName, matched, options{multipleDates: tt.policy})
+		if !got.Equal(tt.want) || got.Location() != zone {
+			t.Errorf("with %s policy got %s, want %s", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestResolveMultipleDatesLogsDateUsed(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	fileName := "20221225_IMG_20230101.jpg"
+	matched := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	got := resolveMultipleDates("/nonexistent/"+fileName, fileName, matched, options{multipleDates: MultipleDatesFirst})
+	if want := "using " + got.Format("2006-01-02") + " "; !strings.Contains(buf.String(), want) {
+		t.Errorf("got log %q, want it to contain %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,79 @@
+package organize
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MediaFile describes a file being organized, as passed to a FolderNamer.
+type MediaFile struct {
+	// Path is the path of the file, which can be used to read more of its
+	// metadata.
+	Path string
+	// Date is the capture date determined for the file.
+	Date time.Time
+}
+
+// FolderNamer names the directory a file is organized into. It lets embedders
+// file pictures by event, by person or by any other scheme, while reusing the
+// scanning, dating and moving of an Organizer.
+type FolderNamer interface {
+	// FolderPath returns the slash separated path, relative to the
+	// destination root, of the directory f belongs in. An error leaves the
+	// file in place.
+	FolderPath(f MediaFile) (string, error)
+}
+
+// FolderNamerFunc adapts a function to the FolderNamer interface.
+type FolderNamerFunc func(f MediaFile) (string, error)
+
+// FolderPath calls fn(f).
+func (fn FolderNamerFunc) FolderPath(f MediaFile) (string, error) {
+	return fn(f)
+}
+
+// LayoutNamer returns a FolderNamer filing files into directories named by
+// their date in the given Go time layout, as configured by WithLayout. It can
+// serve as a fallback for custom FolderNamers.
+func LayoutNamer(layout string) FolderNamer {
+	return FolderNamerFunc(func(f MediaFile) (string, error) {
+		return f.Date.Format(layout), nil
+	})
+}
+
+// folderPath returns the path, relative to the destination root, of the
+// directory f is moved into.
+func folderPath(f MediaFile, opts options) (string, error) {
+	namer := opts.folderNamer
+	if namer == nil {
+		layout := opts.layout
+		if layout == "" {
+			layout = DefaultLayout
+		}
+		namer = LayoutNamer(layout)
+	}
+	path, err := namer.FolderPath(f)
+	if err != nil {
+		return "", fmt.Errorf("unable to name the folder of %q: %v", f.Path, err)
+	}
+	if err := checkFolderPath(path); err != nil {
+		return "", fmt.Errorf("invalid folder for %q: %v", f.Path, err)
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// checkFolderPath checks that path is a clean, relative, slash separated path
+// that stays within the destination root.
+func checkFolderPath(path string) error {
+	if strings.HasPrefix(path, "/") {
+		return fmt.Errorf("%q is not a relative path", path)
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return fmt.Errorf("%q has an empty, . or .. path element", path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package organize
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFolderNamer(t *testing.T) {
+	skiTrip := time.Date(2021, 2, 20, 0, 0, 0, 0, time.UTC)
+	namer := FolderNamerFunc(func(f MediaFile) (string, error) {
+		switch {
+		case filepath.Ext(f.Path) == ".mp4":
+			return "", errors.New("no videos please")
+		case f.Date.Year() == 1999:
+			return "../" + f.Date.Format("2006"), nil
+		case !f.Date.Before(skiTrip) && f.Date.Before(skiTrip.AddDate(0, 0, 7)):
+			return "Events/Ski trip", nil
+		}
+		return LayoutNamer("2006/01").FolderPath(f)
+	})
+	o, err := New(WithExternalTools(false), WithFolderNamer(namer))
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+
+	tests := []struct {
+		fileName string
+		want     string
+		wantErr  bool
+	}{
+		{"IMG_20210222_213525.jpg", "Events/Ski trip", false},
+		{"IMG_20210322_213525.jpg", "2021/03", false},
+		{"VID_20210222_213525.mp4", "", true},
+		{"IMG_19990222_213525.jpg", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := o.FolderName(tt.fileName)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Expected error but received none for %s", tt.fileName)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+			continue
+		}
+		if want := filepath.FromSlash(tt.want); got != want {
+			t.Errorf("got %s, want %s (file name: %s)", got, want, tt.fileName)
+		}
+	}
+}
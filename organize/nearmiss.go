@@ -0,0 +1,80 @@
+package organize
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NearMisses counts the events of a run in which the safety features kept
+// files from harm, to show how much they matter.
+type NearMisses struct {
+	// Overwrites counts moves refused because the destination file existed.
+	Overwrites int
+	// InvalidDates counts file names that a matcher supports but whose date
+	// was rejected as not a valid calendar date.
+	InvalidDates int
+	// Duplicates counts files not moved, or removed, because identical copies
+	// were already archived.
+	Duplicates int
+	// Outliers counts files held back because their dates were suspected to
+	// be camera clock errors.
+	Outliers int
+}
+
+func (n *NearMisses) overwrite() {
+	if n != nil {
+		runMu.Lock()
+		n.Overwrites++
+		runMu.Unlock()
+	}
+}
+
+func (n *NearMisses) invalidDate() {
+	if n != nil {
+		runMu.Lock()
+		n.InvalidDates++
+		runMu.Unlock()
+	}
+}
+
+func (n *NearMisses) duplicate() {
+	if n != nil {
+		runMu.Lock()
+		n.Duplicates++
+		runMu.Unlock()
+	}
+}
+
+func (n *NearMisses) outlier() {
+	if n != nil {
+		runMu.Lock()
+		n.Outliers++
+		runMu.Unlock()
+	}
+}
+
+// Total returns the number of near misses.
+func (n NearMisses) Total() int {
+	return n.Overwrites + n.InvalidDates + n.Duplicates + n.Outliers
+}
+
+// String describes the near misses, e.g. "2 overwrites prevented, 1 invalid
+// date rejected".
+func (n NearMisses) String() string {
+	var parts []string
+	add := func(count int, singular, plural string) {
+		if count == 1 {
+			parts = append(parts, "1 "+singular)
+		} else if count > 1 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, plural))
+		}
+	}
+	add(n.Overwrites, "overwrite prevented", "overwrites prevented")
+	add(n.InvalidDates, "invalid date rejected", "invalid dates rejected")
+	add(n.Duplicates, "duplicate of an archived file", "duplicates of archived files")
+	add(n.Outliers, "suspected clock error held", "suspected clock errors held")
+	if len(parts) == 0 {
+		return "no near misses"
+	}
+	return strings.Join(parts, ", ")
+}
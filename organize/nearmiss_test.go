@@ -0,0 +1,53 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNearMissesString(t *testing.T) {
+	tests := []struct {
+		n    NearMisses
+		want string
+	}{
+		{NearMisses{}, "no near misses"},
+		{NearMisses{Overwrites: 1}, "1 overwrite prevented"},
+		{NearMisses{Overwrites: 2, InvalidDates: 1, Duplicates: 3}, "2 overwrites prevented, 1 invalid date rejected, 3 duplicates of archived files"},
+		{NearMisses{Outliers: 2}, "2 suspected clock errors held"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.n.String(); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestOrganizerNearMisses(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"IMG_20210222_213525.jpg":            "new",
+		"2021-02-22/IMG_20210222_213525.jpg": "existing",
+		"IMG_20211341_213525.jpg":            "",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithExternalTools(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Organize(dir); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if got, want := o.NearMisses(), (NearMisses{Overwrites: 1, InvalidDates: 1}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
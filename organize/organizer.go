@@ -0,0 +1,981 @@
+// Package organize files pictures and videos into a set of appropriately
+// named directories, corresponding to the date the pictures were taken. The
+// directories are named in the form YYYY-MM-DD, and are created as needed.
+// Dates are taken from file names where possible, and otherwise from the
+// files' metadata.
+//
+// Typical usage:
+//
+//	o, err := organize.New(organize.WithProtectDest(true))
+//	if err != nil {
+//	  // Handle err.
+//	}
+//	moved, err := o.Organize(dir)
+package organize
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// options holds the settings of an Organizer.
+type options struct {
+	// explainUnmatched reports, for each file that no matcher handles, the
+	// matchers that came close to handling it.
+	explainUnmatched bool
+	// fatTimestamps falls back to the FAT timestamp of files with 8.3 names
+	// (e.g. PICT0012.JPG) that no matcher handles.
+	fatTimestamps bool
+	// mtimeFallback falls back to the modification time of images and videos
+	// that can't be dated otherwise.
+	mtimeFallback bool
+	// previews generates small preview proxies of moved videos using ffmpeg.
+	previews bool
+	// protectDest guarantees that no existing file in the destination tree is
+	// ever overwritten or deleted, even if it appears while a file is moved.
+	protectDest bool
+	// classify routes screenshots and document scans into their own trees,
+	// separate from photos.
+	classify bool
+	// playlists maintains a per-date M3U playlist of the organized files.
+	playlists bool
+	// scanDates falls back to plausible dates found anywhere in file names
+	// that no matcher handles.
+	scanDates bool
+	// earliestDate, if set, is the earliest plausible date; earlier dates
+	// are rejected as invalid.
+	earliestDate time.Time
+	// rejectFuture rejects dates in the future as invalid.
+	rejectFuture bool
+	// preferredDateTag is the metadata tag preferred for capture dates, or
+	// empty for the default preference order.
+	preferredDateTag string
+	// multipleDates selects the date used for names with several dates.
+	multipleDates MultipleDatesPolicy
+	// copySuffix selects how files with a " (N)" duplicate suffix are
+	// handled.
+	copySuffix CopySuffixPolicy
+	// onConflict selects what happens to files whose destination is taken
+	// by a different file.
+	onConflict ConflictPolicy
+	// duplicates selects what happens to files already archived.
+	duplicates DuplicatePolicy
+	// paranoid hashes files of the same name as an archived one even if their
+	// size and modification time are equal.
+	paranoid bool
+	// layout is the Go time layout of the path, relative to the destination
+	// root, of the directory files of a date are moved into.
+	layout string
+	// folderNamer, if set, names the directories instead of layout.
+	folderNamer FolderNamer
+	// dest is the root of the tree the dated directories are created in, or
+	// empty to create them in the organized directory itself.
+	dest string
+	// recursive organizes the files in subdirectories too.
+	recursive bool
+	// skipDatedDirs leaves out dated directories from recursive scans.
+	skipDatedDirs bool
+	// holdOutliers leaves files whose dates are suspected clock errors in
+	// place.
+	holdOutliers bool
+	// recentDays, if positive, keeps links to the files imported in the last
+	// recentDays days in the Recent directory.
+	recentDays int
+	// maxRuntime and maxBytes, if positive, limit how long a run takes and
+	// how many bytes it moves.
+	maxRuntime time.Duration
+	maxBytes   int64
+	// maxMemory, if positive, is the memory the run should stay within, in
+	// bytes, e.g. on small NAS boxes.
+	maxMemory int64
+	// workers is the number of files dated, hashed and moved in parallel.
+	workers int
+	// verify hashes each file before and after it is moved, reverting moves
+	// that changed the file.
+	verify bool
+	// journal, if set, is the file every move is recorded in, so that the
+	// moves can be undone.
+	journal string
+	// build, if set, describes the binary in the journal entries.
+	build string
+	// stateFile, if set, is where a run that reaches its limits records the
+	// moves left undone, which the next run resumes.
+	stateFile string
+	// budget tracks the resources used by the current run against the
+	// limits.
+	budget *runBudget
+	// quarantine, if set, is the directory, relative to the destination
+	// root, that images and videos that can't be dated are moved into.
+	quarantine string
+	// unmatchedDir, if set, is the directory, relative to the destination
+	// root, that all other files that can't be dated are moved into.
+	unmatchedDir string
+	// include and exclude are glob patterns selecting the files considered
+	// for organizing.
+	include, exclude []string
+	// folderDate selects the time zone files are filed by.
+	folderDate FolderDatePolicy
+	// logSkipped logs each directory left out of a recursive scan, and why.
+	logSkipped bool
+	// dryRun logs the changes that would be made to the file system instead
+	// of making them.
+	dryRun bool
+	// nearMisses counts the events of the current run in which the safety
+	// features kept files from harm.
+	nearMisses *NearMisses
+	// progress, if set, is called as files are dated and moved.
+	progress func(Progress)
+	// report records the outcome of the current run for each file.
+	report *Report
+	// dryRunDirs records the directories a dry run has reported it would
+	// create, so each is reported once.
+	dryRunDirs map[string]bool
+	// anchoring controls where in file names the built-in matchers' patterns
+	// may be found.
+	anchoring AnchoringPolicy
+	// customMatchers are user supplied matchers, tried before the built-in
+	// ones.
+	customMatchers []*MediaFileMatcher
+	// matchers are the matchers dating files by their names, tried in order.
+	matchers []*MediaFileMatcher
+	// useExternalTools enables looking up external tools for metadata dates.
+	useExternalTools bool
+	// externalTools are the installed external tools consulted for metadata
+	// dates of files that no matcher handles.
+	externalTools []externalTool
+}
+
+// An Option configures an Organizer.
+type Option func(*options)
+
+// WithExplainUnmatched makes the Organizer report, for each file that no
+// matcher handles, the matchers that came close to handling it.
+func WithExplainUnmatched(enabled bool) Option {
+	return func(o *options) { o.explainUnmatched = enabled }
+}
+
+// WithMtimeFallback makes the Organizer date images and videos that can't be
+// dated by their name or metadata by their modification time. This is a last
+// resort, as the modification time may be when the file was copied rather than
+// captured.
+func WithMtimeFallback(enabled bool) Option {
+	return func(o *options) { o.mtimeFallback = enabled }
+}
+
+// WithFATTimestamps makes the Organizer date files with 8.3 names (e.g.
+// PICT0012.JPG) that no matcher handles by their FAT timestamp.
+func WithFATTimestamps(enabled bool) Option {
+	return func(o *options) { o.fatTimestamps = enabled }
+}
+
+// WithPreviews makes the Organizer generate small preview proxies of moved
+// videos using ffmpeg, which must be installed.
+func WithPreviews(enabled bool) Option {
+	return func(o *options) { o.previews = enabled }
+}
+
+// WithProtectDest guarantees that no existing file in the destination is ever
+// overwritten or deleted. Files are moved by hard linking them into place.
+func WithProtectDest(enabled bool) Option {
+	return func(o *options) { o.protectDest = enabled }
+}
+
+// WithClassify makes the Organizer move screenshots and document scans into
+// separate Screenshots and Documents trees.
+func WithClassify(enabled bool) Option {
+	return func(o *options) { o.classify = enabled }
+}
+
+// WithPlaylists makes the Organizer maintain a YYYY-MM-DD.m3u playlist per
+// date at the root of the organized directory.
+func WithPlaylists(enabled bool) Option {
+	return func(o *options) { o.playlists = enabled }
+}
+
+// WithScanDates makes the Organizer date files that no matcher handles by a
+// plausible date anywhere in their name.
+func WithScanDates(enabled bool) Option {
+	return func(o *options) { o.scanDates = enabled }
+}
+
+// WithEarliestDate makes the Organizer reject dates before earliest, such as
+// 1990-01-01, as invalid, so that misread dates don't create garbage
+// directories. Files are then dated from the next source, if any.
+func WithEarliestDate(earliest time.Time) Option {
+	return func(o *options) { o.earliestDate = earliest }
+}
+
+// WithRejectFuture makes the Organizer reject dates in the future as invalid.
+func WithRejectFuture(enabled bool) Option {
+	return func(o *options) { o.rejectFuture = enabled }
+}
+
+// WithPreferredDateTag sets the metadata tag preferred for capture dates, one
+// of DateTags.
+func WithPreferredDateTag(tag string) Option {
+	return func(o *options) { o.preferredDateTag = tag }
+}
+
+// WithMultipleDates sets the date used for file names with several dates.
+func WithMultipleDates(policy MultipleDatesPolicy) Option {
+	return func(o *options) { o.multipleDates = policy }
+}
+
+// WithCopySuffix sets how files with a " (N)" duplicate suffix are handled.
+func WithCopySuffix(policy CopySuffixPolicy) Option {
+	return func(o *options) { o.copySuffix = policy }
+}
+
+// WithOnConflict sets what happens when a file is to be moved onto an
+// existing file with different contents.
+func WithOnConflict(policy ConflictPolicy) Option {
+	return func(o *options) { o.onConflict = policy }
+}
+
+// WithDuplicates sets what happens to files whose identical copy is already
+// archived at the destination.
+func WithDuplicates(policy DuplicatePolicy) Option {
+	return func(o *options) { o.duplicates = policy }
+}
+
+// WithParanoid makes the Organizer always hash potential duplicates, rather
+// than assume a file of the same name, size and modification time as an
+// archived one is already archived. Files are hashed before they are deleted
+// regardless.
+func WithParanoid(enabled bool) Option {
+	return func(o *options) { o.paranoid = enabled }
+}
+
+// DefaultLayout is the layout of the dated directories unless configured
+// otherwise: a flat list of YYYY-MM-DD directories.
+const DefaultLayout = "2006-01-02"
+
+// WithLayout sets the Go time layout of the path of the directory files of a
+// date are moved into, such as "2006/01" or "2006/2006-01-02" for nested
+// year and month directories. Slashes separate directories.
+func WithLayout(layout string) Option {
+	return func(o *options) { o.layout = layout }
+}
+
+// WithFolderNamer sets how the directories files are organized into are
+// named, replacing the dated directories of the layout.
+func WithFolderNamer(namer FolderNamer) Option {
+	return func(o *options) { o.folderNamer = namer }
+}
+
+// WithDest makes the Organizer create the dated directories under dest, such
+// as a library on a NAS, rather than in the organized directory itself.
+func WithDest(dest string) Option {
+	return func(o *options) { o.dest = dest }
+}
+
+// WithRecursive makes the Organizer organize files in subdirectories (e.g.
+// DCIM/Camera) too, rather than only those at the top level. They are moved
+// into dated directories at the top level.
+func WithRecursive(enabled bool) Option {
+	return func(o *options) { o.recursive = enabled }
+}
+
+// WithSkipDatedDirs controls whether recursive scans leave out directories
+// named like dated directories (YYYY-MM-DD), which presumably have been
+// organized already. They are left out by default.
+func WithSkipDatedDirs(enabled bool) Option {
+	return func(o *options) { o.skipDatedDirs = enabled }
+}
+
+// WithHoldOutliers makes the Organizer leave files in place, for review, whose
+// dates are far from those of most other files being organized, as they are
+// likely due to a wrong camera clock (e.g. reset to 2000-01-01).
+func WithHoldOutliers(enabled bool) Option {
+	return func(o *options) { o.holdOutliers = enabled }
+}
+
+// WithRecent makes the Organizer keep symbolic links to the files it imported
+// in the last days days in the Recent directory at the root of the organized
+// directory. Older links are pruned after each run. Zero disables it.
+func WithRecent(days int) Option {
+	return func(o *options) { o.recentDays = days }
+}
+
+// WithMaxRuntime limits how long a run of the Organizer moves files, e.g. for
+// runs scheduled by cron. Once reached, the remaining files are left for the
+// next run. Zero means no limit.
+func WithMaxRuntime(d time.Duration) Option {
+	return func(o *options) { o.maxRuntime = d }
+}
+
+// WithMaxBytes limits the total size of the files a run of the Organizer
+// moves. Once reached, the remaining files are left for the next run. Zero
+// means no limit.
+func WithMaxBytes(n int64) Option {
+	return func(o *options) { o.maxBytes = n }
+}
+
+// WithMaxMemory makes the Organizer adapt how much memory it uses, i.e. the
+// number of workers, the copy buffers and the threads of ffmpeg generating
+// previews, to stay within n bytes. Zero means no limit.
+func WithMaxMemory(n int64) Option {
+	return func(o *options) { o.maxMemory = n }
+}
+
+// WithWorkers makes the Organizer date, hash and move up to n files in
+// parallel, which speeds up runs reading metadata or moving files to a NAS.
+// Files moved into the same directory are moved one at a time, in order. In
+// recursive mode, up to n directories are read at a time too. Values below 2
+// process one file at a time.
+func WithWorkers(n int) Option {
+	return func(o *options) { o.workers = n }
+}
+
+// WithVerify makes the Organizer hash each file before it is moved and again
+// after, reverting the move if they differ, for confidence that transfers to
+// e.g. flaky network mounts are lossless. Files copied to another file system
+// are checked before their source is removed.
+func WithVerify(enabled bool) Option {
+	return func(o *options) { o.verify = enabled }
+}
+
+// WithJournal makes the Organizer record every move it makes as a line of
+// JSON in the file at path, appending to it, so that the moves can be undone
+// with Undo.
+func WithJournal(path string) Option {
+	return func(o *options) { o.journal = path }
+}
+
+// WithBuild makes the Organizer record build, a description of the binary
+// using it such as "organizepics v1.2.0 (0123abc)", in the journal entries it
+// writes, so that past runs can be attributed to a binary.
+func WithBuild(build string) Option {
+	return func(o *options) { o.build = build }
+}
+
+// WithStateFile makes a run of the Organizer that reaches its limits record
+// the moves left undone in the file at path. The next run resumes them,
+// rather than scanning the directory again, and removes the file once done.
+func WithStateFile(path string) Option {
+	return func(o *options) { o.stateFile = path }
+}
+
+// WithQuarantine makes the Organizer move images and videos that it can't
+// date into dir, a slash separated path relative to the destination root such
+// as "Unsorted", rather than leave them in place. Recursive scans skip it.
+func WithQuarantine(dir string) Option {
+	return func(o *options) { o.quarantine = dir }
+}
+
+// WithUnmatchedDir makes the Organizer move the files that it can't date,
+// other than the images and videos quarantined by WithQuarantine, into dir, a
+// slash separated path relative to the destination root such as "_unsorted",
+// so that no file is left in the organized directory unhandled. Recursive
+// scans skip it.
+func WithUnmatchedDir(dir string) Option {
+	return func(o *options) { o.unmatchedDir = dir }
+}
+
+// WithLogSkipped makes the Organizer log each directory left out of a
+// recursive scan, and why. Otherwise only their number is logged.
+func WithLogSkipped(enabled bool) Option {
+	return func(o *options) { o.logSkipped = enabled }
+}
+
+// WithDryRun makes the Organizer log every move it would perform and every
+// directory it would create, without touching the file system.
+func WithDryRun(enabled bool) Option {
+	return func(o *options) { o.dryRun = enabled }
+}
+
+// WithProgress makes the Organizer call fn after each file it dates or moves,
+// e.g. to show a progress bar during long runs. fn is called often, so it
+// should return quickly.
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) { o.progress = fn }
+}
+
+// WithMatchers adds custom matchers, such as those created by
+// NewPatternMatcher or LoadMatchers, which are tried in order before the
+// built-in ones.
+func WithMatchers(matchers ...*MediaFileMatcher) Option {
+	return func(o *options) { o.customMatchers = append(o.customMatchers, matchers...) }
+}
+
+// WithAnchoring sets where in file names the built-in matchers' patterns may
+// be found.
+func WithAnchoring(policy AnchoringPolicy) Option {
+	return func(o *options) { o.anchoring = policy }
+}
+
+// WithExternalTools enables or disables reading metadata dates with the
+// external tools (exiftool, ffprobe) installed on the machine. They are
+// enabled by default; disabling them makes results independent of the
+// machine.
+func WithExternalTools(enabled bool) Option {
+	return func(o *options) { o.useExternalTools = enabled }
+}
+
+// An Organizer organizes directories of pictures and videos into dated
+// directories. Create one with New.
+type Organizer struct {
+	opts options
+}
+
+// New returns an Organizer configured with the given options. It returns an
+// error if the options are invalid or require tools that are not installed.
+func New(opts ...Option) (*Organizer, error) {
+	o := options{
+		multipleDates:    MultipleDatesFirst,
+		copySuffix:       CopySuffixKeep,
+		duplicates:       DuplicateSkip,
+		onConflict:       ConflictSkip,
+		anchoring:        DefaultAnchoring,
+		folderDate:       DefaultFolderDate,
+		layout:           DefaultLayout,
+		skipDatedDirs:    true,
+		useExternalTools: true,
+		nearMisses:       &NearMisses{},
+		report:           &Report{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.preferredDateTag != "" && !validDateTag(o.preferredDateTag) {
+		return nil, fmt.Errorf("unknown date tag %q, want one of %s", o.preferredDateTag, strings.Join(DateTags, ", "))
+	}
+	if err := validateLayout(o.layout); err != nil {
+		return nil, err
+	}
+	if o.quarantine != "" {
+		if err := checkFolderPath(o.quarantine); err != nil {
+			return nil, fmt.Errorf("invalid quarantine directory: %v", err)
+		}
+	}
+	if err := checkGlobs(o.include); err != nil {
+		return nil, err
+	}
+	if err := checkGlobs(o.exclude); err != nil {
+		return nil, err
+	}
+	if o.unmatchedDir != "" {
+		if err := checkFolderPath(o.unmatchedDir); err != nil {
+			return nil, fmt.Errorf("invalid unmatched directory: %v", err)
+		}
+	}
+	if o.recentDays < 0 {
+		return nil, fmt.Errorf("invalid number of recent days %d", o.recentDays)
+	}
+	if o.maxRuntime < 0 {
+		return nil, fmt.Errorf("invalid maximum runtime %s", o.maxRuntime)
+	}
+	if o.onConflict == ConflictOverwrite && o.protectDest {
+		return nil, fmt.Errorf("overwriting conflicting files contradicts protecting the destination")
+	}
+	if o.workers < 0 {
+		return nil, fmt.Errorf("invalid number of workers %d", o.workers)
+	}
+	if o.maxMemory < 0 {
+		return nil, fmt.Errorf("invalid maximum memory %d", o.maxMemory)
+	}
+	if o.maxBytes < 0 {
+		return nil, fmt.Errorf("invalid maximum number of bytes %d", o.maxBytes)
+	}
+	if o.previews {
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			return nil, fmt.Errorf("previews require ffmpeg: %v", err)
+		}
+	}
+	o.matchers = append(append([]*MediaFileMatcher(nil), o.customMatchers...), anchorMatchers(mediaMatchers, o.anchoring)...)
+	if o.useExternalTools {
+		o.externalTools = availableExternalTools()
+	}
+	return &Organizer{o}, nil
+}
+
+// Organize organizes all recognized files (images, videos) in dirName into
+// appropriate directories. It returns the number of files moved, or that
+// would have been moved in a dry run.
+func (o *Organizer) Organize(dirName string) (int, error) {
+	o.startRun(dirName)
+	var p Plan
+	resumed := false
+	if o.opts.stateFile != "" {
+		moves, ok, err := loadState(o.opts.stateFile, dirName)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			log.Printf("Resuming the %d moves left by the previous run", len(moves))
+			p, resumed = Plan{Moves: moves}, true
+		}
+	}
+	if !resumed {
+		var err error
+		if p, err = o.Plan(dirName); err != nil {
+			return 0, err
+		}
+	}
+	if o.opts.logSkipped {
+		for _, s := range p.Skipped {
+			log.Printf("Skipped directory %q: %s", s.Path, s.Reason)
+		}
+	} else if len(p.Skipped) > 0 {
+		log.Printf("Skipped %d directories", len(p.Skipped))
+	}
+	o.opts.report.Skipped = p.Skipped
+	for _, u := range p.Unmatched {
+		log.Print(u.Reason)
+		o.opts.report.add(u.Path, StatusUnmatched, "", u.Reason)
+		if o.opts.explainUnmatched {
+			for _, reason := range explainUnmatched(o.opts.matchers, filepath.Base(u.Path)) {
+				log.Printf("  %s", reason)
+			}
+		}
+	}
+	count := 0
+	for _, moved := range o.Execute(dirName, p) {
+		if moved {
+			count++
+		}
+	}
+	o.opts.report.addDates(p.Moves)
+	if !o.reportRemaining() {
+		if o.opts.quarantine != "" {
+			quarantineUnmatched(dirName, p, o.opts)
+		}
+		if o.opts.unmatchedDir != "" {
+			moveUnmatched(dirName, p, o.opts)
+		}
+	}
+	if o.opts.stateFile != "" && !o.opts.dryRun {
+		if err := saveState(o.opts.stateFile, dirName, o.opts.budget.remaining); err != nil {
+			log.Printf("unable to save the state of the run: %v", err)
+		}
+	}
+	if o.opts.recentDays > 0 && !o.opts.dryRun {
+		pruneRecent(destRoot(dirName, o.opts), o.opts.recentDays, time.Now())
+	}
+	return count, nil
+}
+
+// startRun resets the state the Organizer keeps of a run, for a run on
+// dirName.
+func (o *Organizer) startRun(dirName string) {
+	*o.opts.nearMisses = NearMisses{}
+	*o.opts.report = Report{Dir: dirName, DryRun: o.opts.dryRun, FolderDate: o.opts.folderDate}
+	if o.opts.dryRun {
+		o.opts.dryRunDirs = make(map[string]bool)
+	}
+	o.opts.budget = &runBudget{maxRuntime: o.opts.maxRuntime, maxBytes: o.opts.maxBytes, start: time.Now()}
+}
+
+// reportRemaining logs and reports the files left for the next run if the
+// run reached its limits, reporting whether it did.
+func (o *Organizer) reportRemaining() bool {
+	budget := o.opts.budget
+	if !budget.stopped {
+		return false
+	}
+	log.Printf("Reached the limits of the run, leaving %d files for the next run", len(budget.remaining))
+	for _, m := range budget.remaining {
+		o.opts.report.add(m.Src, StatusSkipped, "", "limits of the run reached, left for the next run")
+	}
+	return true
+}
+
+// NearMisses returns the counts of the events in which the safety features
+// kept files from harm during the last call to Organize.
+func (o *Organizer) NearMisses() NearMisses {
+	return *o.opts.nearMisses
+}
+
+// Report returns the outcome of the last call to Organize for each file.
+func (o *Organizer) Report() Report {
+	return *o.opts.report
+}
+
+// Plan determines where each file in dirName should be moved to, without
+// touching the file system.
+func (o *Organizer) Plan(dirName string) (Plan, error) {
+	return planOrganize(dirName, o.opts)
+}
+
+// Execute performs the moves of p, a plan for dirName, reporting for each
+// whether the file was moved.
+func (o *Organizer) Execute(dirName string, p Plan) []bool {
+	return executePlan(dirName, p, o.opts)
+}
+
+// Date returns the date encoded in fileName, according to the Organizer's
+// matchers.
+func (o *Organizer) Date(fileName string) (time.Time, error) {
+	return getDate(o.opts.matchers, fileName)
+}
+
+// FolderName returns the path, relative to the destination root, of the
+// dated directory that the file named fileName belongs in, according to the
+// Organizer's matchers and layout (or folder namer).
+func (o *Organizer) FolderName(fileName string) (string, error) {
+	date, err := o.Date(fileName)
+	if err != nil {
+		return "", err
+	}
+	return folderPath(MediaFile{fileName, date}, o.opts)
+}
+
+// MoveIntoDir moves the file at srcPath into the directory destDir, creating
+// the directory if needed. An existing file of the same name is never
+// overwritten. It reports whether the file was moved.
+func (o *Organizer) MoveIntoDir(srcPath, destDir string) bool {
+	return moveIntoDir(srcPath, destDir, o.opts)
+}
+
+// validateLayout checks that layout yields relative paths that tell dates
+// apart.
+func validateLayout(layout string) error {
+	a := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC).Format(layout)
+	b := time.Date(2002, 3, 4, 5, 6, 7, 0, time.UTC).Format(layout)
+	if a == b {
+		return fmt.Errorf("layout %q contains no date", layout)
+	}
+	if err := checkFolderPath(a); err != nil {
+		return fmt.Errorf("invalid layout %q: %v", layout, err)
+	}
+	return nil
+}
+
+// destRoot returns the root of the tree that files in dirName are organized
+// into.
+func destRoot(dirName string, opts options) string {
+	if opts.dest != "" {
+		return opts.dest
+	}
+	return dirName
+}
+
+// afterMove performs the optional follow-up work for a file of the given date
+// that has just been moved to destFilePath, in the organized tree at root.
+func afterMove(root, destFilePath string, date time.Time, opts options) {
+	if opts.dryRun {
+		return
+	}
+	if opts.playlists {
+		// Playlists and Recent links are shared by the workers of a run.
+		runMu.Lock()
+		err := addToPlaylist(root, date, destFilePath)
+		runMu.Unlock()
+		if err != nil {
+			log.Printf("unable to update playlist: %v", err)
+		}
+	}
+	if opts.previews && isVideo(destFilePath) {
+		if err := generatePreview(root, destFilePath, previewThreads(opts.maxMemory/int64(parallelism(opts)))); err != nil {
+			log.Printf("unable to generate preview: %v", err)
+		}
+	}
+	if opts.recentDays > 0 {
+		runMu.Lock()
+		err := linkRecent(root, destFilePath)
+		runMu.Unlock()
+		if err != nil {
+			log.Printf("unable to link recent file: %v", err)
+		}
+	}
+}
+
+// moveIntoDir moves the file at srcPath into the directory destPath, creating
+// the directory if it doesn't exist yet. An existing file of the same name in
+// destPath is never overwritten. It reports whether the file was moved.
+func moveIntoDir(srcPath, destPath string, opts options) bool {
+	return moveIntoDirAs(srcPath, destPath, filepath.Base(srcPath), opts)
+}
+
+// moveIntoDirAs is like moveIntoDir, but gives the moved file the name
+// fileName.
+func moveIntoDirAs(srcPath, destPath, fileName string, opts options) bool {
+	if opts.dryRun {
+		return dryRunMove(srcPath, destPath, fileName, opts)
+	}
+	// Make the dir if it doesn't exist. MkdirAll is safe for workers
+	// creating the same parent directories at the same time.
+	if err := os.MkdirAll(destPath, 0700); err != nil {
+		log.Printf("unable to mkdir %q: %v", destPath, err)
+		opts.report.addf(srcPath, StatusError, "", "unable to mkdir %q: %v", destPath, err)
+		return false
+	}
+
+	// Ensure intended path doesn't already exist.
+	destFilePath := filepath.Join(destPath, fileName)
+	if _, err := os.Stat(destFilePath); err == nil {
+		if !overwriteAllowed(srcPath, destFilePath, opts) {
+			// File exists, and that's not okay. Probably safer not to
+			// overwrite the existing file. Unless it is a duplicate, log a
+			// warning and continue to the next file; the user can decide
+			// what to do.
+			refuseOverwrite(srcPath, destFilePath, opts)
+			return false
+		}
+		log.Printf("Overwriting %q with %q", destFilePath, srcPath)
+	}
+	var srcHash string
+	if opts.verify {
+		hash, err := hashFile(srcPath)
+		if err != nil {
+			log.Printf("unable to move %q: %v", srcPath, err)
+			opts.report.add(srcPath, StatusError, destFilePath, err.Error())
+			return false
+		}
+		srcHash = hash
+	}
+	if !moveFile(srcPath, destFilePath, srcHash, opts) {
+		return false
+	}
+	if opts.verify && !verifyMove(srcPath, destFilePath, srcHash, opts) {
+		return false
+	}
+	if opts.journal != "" {
+		runMu.Lock()
+		err := recordMove(srcPath, destFilePath, opts)
+		runMu.Unlock()
+		if err != nil {
+			log.Printf("unable to record the move of %q in the journal: %v", srcPath, err)
+		}
+	}
+	opts.report.add(srcPath, StatusMoved, destFilePath, "")
+	return true
+}
+
+// moveFile moves the file at srcPath to destFilePath, without overwriting an
+// existing file if opts.protectDest is set. Files copied to another file
+// system must match srcHash, if not empty, before the source is removed. A
+// srcHash also means the move is to be checked by verifyMove, which is left to
+// remove the source of a file that was copied or linked. It reports whether
+// the file was moved.
+func moveFile(srcPath, destFilePath, srcHash string, opts options) bool {
+	if opts.protectDest {
+		return moveNoClobber(srcPath, destFilePath, srcHash, opts)
+	}
+	// Move file to new location, copying it if it is on another file
+	// system, such as a NAS.
+	src, _ := os.Lstat(srcPath)
+	err := os.Rename(srcPath, destFilePath)
+	if err != nil && !isCrossDevice(err) && renameSucceeded(srcPath, destFilePath, src, srcHash) {
+		log.Printf("moving %q reported an error but succeeded: %v", srcPath, err)
+		err = nil
+	}
+	if isCrossDevice(err) {
+		if err = copyAcrossDevices(srcPath, destFilePath, false, srcHash, copyBufferSizeFor(opts)); err == nil && srcHash == "" {
+			removeMoved(srcPath)
+		}
+	}
+	if err != nil {
+		log.Printf("unable to move %q: %v", srcPath, err)
+		opts.report.add(srcPath, StatusError, destFilePath, err.Error())
+		return false
+	}
+	return true
+}
+
+// overwriteAllowed reports whether the file at srcPath may replace the
+// existing file destFilePath: only with ConflictOverwrite, and only if they
+// differ, as identical files are handled as duplicates.
+func overwriteAllowed(srcPath, destFilePath string, opts options) bool {
+	if opts.onConflict != ConflictOverwrite {
+		return false
+	}
+	same, err := sameContents(srcPath, destFilePath)
+	return err == nil && !same
+}
+
+// refuseOverwrite handles the move of the file at srcPath onto the existing
+// file destFilePath, which is never overwritten. If both are identical, as
+// compared by hash, the file is handled as a duplicate; otherwise the refusal
+// is logged.
+func refuseOverwrite(srcPath, destFilePath string, opts options) {
+	if same, err := sameContents(srcPath, destFilePath); err == nil && same {
+		handleDuplicate(srcPath, destFilePath, nil, opts)
+		return
+	}
+	log.Printf("Destination file %q already exists in %q\n", filepath.Base(destFilePath), filepath.Dir(destFilePath))
+	opts.nearMisses.overwrite()
+	opts.report.add(srcPath, StatusConflict, destFilePath, "destination file already exists")
+}
+
+// dryRunMove logs what moveIntoDirAs would do, without doing it.
+func dryRunMove(srcPath, destPath, fileName string, opts options) bool {
+	runMu.Lock()
+	if _, err := os.Stat(destPath); os.IsNotExist(err) && !opts.dryRunDirs[destPath] {
+		log.Printf("Would create directory %q", destPath)
+		if opts.dryRunDirs != nil {
+			opts.dryRunDirs[destPath] = true
+		}
+	}
+	runMu.Unlock()
+	destFilePath := filepath.Join(destPath, fileName)
+	if _, err := os.Stat(destFilePath); err == nil {
+		if !overwriteAllowed(srcPath, destFilePath, opts) {
+			refuseOverwrite(srcPath, destFilePath, opts)
+			return false
+		}
+		log.Printf("Would overwrite %q with %q", destFilePath, srcPath)
+	} else {
+		log.Printf("Would move %q to %q", srcPath, destFilePath)
+	}
+	opts.report.add(srcPath, StatusMoved, destFilePath, "")
+	return true
+}
+
+// moveNoClobber moves srcPath to destFilePath by hard linking it into place and
+// then removing the source. Unlike os.Rename, which silently replaces a file
+// created at destFilePath after it was checked for, linking fails if the
+// destination exists, so an existing file can never be overwritten. Files
+// moved to another file system are copied and their copy linked into place.
+// File systems without hard link support can't be used this way; moves on
+// them fail rather than fall back to an unprotected rename. As for moveFile,
+// the source is left for verifyMove to remove if srcHash is set.
+func moveNoClobber(srcPath, destFilePath, srcHash string, opts options) bool {
+	err := os.Link(srcPath, destFilePath)
+	if err != nil && !isCrossDevice(err) && linkSucceeded(srcPath, destFilePath) {
+		log.Printf("linking %q reported an error but succeeded: %v", srcPath, err)
+		err = nil
+	}
+	if isCrossDevice(err) {
+		err = copyAcrossDevices(srcPath, destFilePath, true, srcHash, copyBufferSizeFor(opts))
+	}
+	if err != nil {
+		if os.IsExist(err) {
+			log.Printf("Destination file %q already exists, not overwriting it\n", destFilePath)
+			opts.nearMisses.overwrite()
+			opts.report.add(srcPath, StatusConflict, destFilePath, "destination file already exists")
+		} else {
+			log.Printf("unable to move %q without risking an overwrite: %v", srcPath, err)
+			opts.report.add(srcPath, StatusError, destFilePath, err.Error())
+		}
+		return false
+	}
+	if srcHash == "" {
+		removeMoved(srcPath)
+	}
+	return true
+}
+
+// verifyMove checks that the file moved from srcPath to destFilePath still has
+// the hash srcHash it had before the move. If not, the move is reverted and
+// reported as an error. Files that were copied or linked still have their
+// source, which is only removed once the move is verified, so reverting them,
+// even from another file system, is a matter of removing the destination. It
+// reports whether the file was moved intact.
+func verifyMove(srcPath, destFilePath, srcHash string, opts options) bool {
+	_, err := os.Lstat(srcPath)
+	kept := err == nil
+	hash, err := hashFile(destFilePath)
+	if err == nil && hash == srcHash {
+		if kept {
+			removeMoved(srcPath)
+		}
+		return true
+	}
+	if err == nil {
+		err = fmt.Errorf("hash %s after the move, want %s", hash, srcHash)
+	}
+	log.Printf("unable to verify the move of %q to %q: %v", srcPath, destFilePath, err)
+	opts.report.addf(srcPath, StatusError, destFilePath, "unable to verify the move: %v", err)
+	if kept {
+		err = os.Remove(destFilePath)
+	} else {
+		err = os.Rename(destFilePath, srcPath)
+	}
+	if err != nil {
+		log.Printf("unable to move %q back to %q: %v", destFilePath, srcPath, err)
+	}
+	return false
+}
+
+// removeMoved removes the original of a file that was moved by linking or
+// copying it.
+func removeMoved(srcPath string) {
+	if err := os.Remove(srcPath); err != nil && !removed(srcPath) {
+		log.Printf("moved %q but unable to remove the original: %v", srcPath, err)
+	}
+}
+
+// checkDateRange returns an *invalidDateError if date, found for the file
+// named fileName, is before the earliest date or, if rejected, in the future.
+func checkDateRange(fileName string, date time.Time, opts options) error {
+	if !opts.earliestDate.IsZero() && date.Before(opts.earliestDate) {
+		return &invalidDateError{fileName, fmt.Errorf("%s is before %s", date.Format("2006-01-02"), opts.earliestDate.Format("2006-01-02"))}
+	}
+	// Allow for the time zones of cameras ahead of the local one.
+	if opts.rejectFuture && date.After(time.Now().Add(24*time.Hour)) {
+		return &invalidDateError{fileName, fmt.Errorf("%s is in the future", date.Format("2006-01-02"))}
+	}
+	return nil
+}
+
+// fileDate determines the date to file the file at path under, first from its
+// name and then, if enabled in opts, from fallback sources. wallClock reports
+// whether the date is the wall clock time of the device, found in the name
+// without a time zone, rather than a capture time.
+func fileDate(path string, entry fs.DirEntry, opts options) (date time.Time, wallClock bool, err error) {
+	date, matcher, err := matchDate(opts.matchers, entry.Name())
+	if err == nil {
+		date = resolveMultipleDates(path, entry.Name(), date, opts)
+		if err = checkDateRange(entry.Name(), date, opts); err == nil {
+			return date, !matcher.zoned, nil
+		}
+	}
+	if _, ok := err.(*invalidDateError); ok {
+		opts.nearMisses.invalidDate()
+	}
+	if date, metaErr := metadataDate(path, opts); metaErr == nil && checkDateRange(entry.Name(), date, opts) == nil {
+		return date, false, nil
+	}
+	if opts.scanDates {
+		if date, score, scanErr := scanDate(entry.Name()); scanErr == nil && checkDateRange(entry.Name(), date, opts) == nil {
+			log.Printf("Using date %s found in the name of %q (confidence %d%%)", date.Format("2006-01-02"), entry.Name(), score)
+			return date, true, nil
+		}
+	}
+	if !opts.fatTimestamps && !opts.mtimeFallback {
+		return date, false, err
+	}
+	info, infoErr := entry.Info()
+	if infoErr != nil {
+		return date, false, err
+	}
+	if opts.fatTimestamps {
+		if date, fatErr := fatTimestampDate(info); fatErr == nil && checkDateRange(entry.Name(), date, opts) == nil {
+			log.Printf("Using FAT timestamp %s for %q; low confidence, check the camera clock was set", date.Format("2006-01-02 15:04:05"), entry.Name())
+			return date, false, nil
+		}
+	}
+	if opts.mtimeFallback && IsMedia(entry.Name()) && checkDateRange(entry.Name(), info.ModTime(), opts) == nil {
+		log.Printf("Using modification time %s for %q; low confidence, it may be when the file was copied", info.ModTime().Format("2006-01-02 15:04:05"), entry.Name())
+		return info.ModTime(), false, nil
+	}
+	return date, false, err
+}
+
+// metadataDate determines the capture date of the file at path from its
+// metadata, using external tools if available and the built-in readers
+// otherwise.
+func metadataDate(path string, opts options) (time.Time, error) {
+	tags := dateTagOrder(opts.preferredDateTag)
+	if date, err := externalToolDate(opts.externalTools, tags, path); err == nil {
+		return date, nil
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if exifExtensions[ext] {
+		return exifDate(path, tags)
+	}
+	if heifExtensions[ext] {
+		return heifDate(path, tags)
+	}
+	if rawExtensions[ext] {
+		return rawDate(path, tags)
+	}
+	return quickTimeDate(path)
+}
@@ -0,0 +1,313 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMoveNoClobber(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.jpg")
+	dest := filepath.Join(dir, "dest.jpg")
+	if err := os.WriteFile(src, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest, []byte("existing"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if moveNoClobber(src, dest, "", options{}) {
+		t.Error("moveNoClobber reported success despite an existing destination")
+	}
+	if got, _ := os.ReadFile(dest); string(got) != "existing" {
+		t.Errorf("destination was overwritten, got contents %q", got)
+	}
+
+	os.Remove(dest)
+	if !moveNoClobber(src, dest, "", options{}) {
+		t.Fatal("moveNoClobber failed with no existing destination")
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("source still exists after move: %v", err)
+	}
+}
+
+func TestOrganizerOrganize(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"IMG_20210222_213525.jpg", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithExternalTools(false), WithProtectDest(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := o.Organize(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if moved != 1 {
+		t.Errorf("got %d files moved, want 1", moved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2021-02-22", "IMG_20210222_213525.jpg")); err != nil {
+		t.Errorf("file was not moved into its dated directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("unmatched file was moved: %v", err)
+	}
+}
+
+func TestNewInvalidDateTag(t *testing.T) {
+	if _, err := New(WithExternalTools(false), WithPreferredDateTag("Bogus")); err == nil {
+		t.Error("Expected error but received none")
+	}
+}
+
+func TestOrganizerDryRun(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"IMG_20210222_213525.jpg", "IMG_20210222_213526.jpg"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithExternalTools(false), WithDryRun(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := o.Organize(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if moved != len(names) {
+		t.Errorf("got %d files that would be moved, want %d", moved, len(names))
+	}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("dry run moved %q: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2021-02-22")); !os.IsNotExist(err) {
+		t.Errorf("dry run created a directory: %v", err)
+	}
+}
+
+func TestOrganizerDest(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "library")
+	for _, name := range []string{"IMG_20210222_213525.jpg", "DCIM/IMG_20210223_101010.jpg", "library/2021-01-01/IMG_20210101_000000.jpg"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithExternalTools(false), WithDest(dest), WithRecursive(true), WithSkipDatedDirs(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := o.Organize(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if moved != 2 {
+		t.Errorf("got %d files moved, want 2", moved)
+	}
+	for _, name := range []string{"2021-02-22/IMG_20210222_213525.jpg", "2021-02-23/IMG_20210223_101010.jpg", "2021-01-01/IMG_20210101_000000.jpg"} {
+		if _, err := os.Stat(filepath.Join(dest, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s is not in the destination: %v", name, err)
+		}
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		dir, path string
+		want      string
+		wantOK    bool
+	}{
+		{"/photos", "/photos/library", "library", true},
+		{"/photos", "/photos/a/b", "a/b", true},
+		{"/photos", "/photos", "", false},
+		{"/photos", "/mnt/nas", "", false},
+		{"/photos", "/photos-old", "", false},
+		{"/photos", "/photos/..library", "..library", true},
+	}
+
+	for _, tt := range tests {
+		got, ok := relativePath(filepath.FromSlash(tt.dir), filepath.FromSlash(tt.path))
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("relativePath(%q, %q) = %q, %v, want %q, %v", tt.dir, tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFolderNameLayout(t *testing.T) {
+	tests := []struct {
+		layout string
+		want   string
+	}{
+		{DefaultLayout, "2021-02-22"},
+		{"2006/01", "2021/02"},
+		{"2006/2006-01-02", "2021/2021-02-22"},
+		{"2006/January", "2021/February"},
+	}
+
+	for _, tt := range tests {
+		o, err := New(WithExternalTools(false), WithLayout(tt.layout))
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		got, err := o.FolderName("IMG_20210222_213525.jpg")
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		if want := filepath.FromSlash(tt.want); got != want {
+			t.Errorf("got %s, want %s (layout: %q)", got, want, tt.layout)
+		}
+	}
+}
+
+func TestNewInvalidLayout(t *testing.T) {
+	for _, layout := range []string{"", "photos", "/2006/01", "2006//01", "2006/../01"} {
+		if _, err := New(WithExternalTools(false), WithLayout(layout)); err == nil {
+			t.Errorf("Expected error but received none (layout: %q)", layout)
+		}
+	}
+}
+
+func TestWithMatchers(t *testing.T) {
+	// The custom matcher reads the second date of the name, and takes
+	// precedence over the built-in one reading the first.
+	m, err := NewPatternMatcher(`_(?P<year>\d{4})(?P<month>\d\d)(?P<day>\d\d)\.`, "")
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	o, err := New(WithExternalTools(false), WithMatchers(m))
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+
+	tests := []struct {
+		fileName string
+		want     string
+	}{
+		{"IMG_20210222_20210101.jpg", "2021-01-01"},
+		{"IMG_20210222_213525.jpg", "2021-02-22"},
+		{"CAM_20210101.avi", "2021-01-01"},
+	}
+
+	for _, tt := range tests {
+		got, err := o.FolderName(tt.fileName)
+		if err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("got %s, want %s (file name: %s)", got, tt.want, tt.fileName)
+		}
+	}
+}
+
+func TestFileDateMtimeFallback(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2004, 7, 3, 13, 45, 11, 0, time.Local)
+	for _, name := range []string{"MOV00001.AVI", "notes.txt", "VID_20210222_213525.mp4"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"MOV00001.AVI":            "2004-07-03",
+		"notes.txt":               "",
+		"VID_20210222_213525.mp4": "2021-02-22",
+	}
+	for _, entry := range entries {
+		for _, enabled := range []bool{false, true} {
+			opts := options{matchers: mediaMatchers, mtimeFallback: enabled}
+			date, _, err := fileDate(filepath.Join(dir, entry.Name()), entry, opts)
+			wantDate := want[entry.Name()]
+			if !enabled && entry.Name() == "MOV00001.AVI" {
+				wantDate = ""
+			}
+			if wantDate == "" {
+				if err == nil {
+					t.Errorf("Expected error but received none (file name: %s, fallback: %v)", entry.Name(), enabled)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("Expected no error but received: %s", err)
+				continue
+			}
+			if got := date.Format("2006-01-02"); got != wantDate {
+				t.Errorf("got %s, want %s (file name: %s, fallback: %v)", got, wantDate, entry.Name(), enabled)
+			}
+		}
+	}
+}
+
+func TestFileDateRange(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2004, 7, 3, 13, 45, 11, 0, time.Local)
+	future := time.Now().AddDate(2, 0, 0).Format("20060102")
+	for _, name := range []string{"IMG_19800101_000000.jpg", "IMG_" + future + "_000000.jpg", "IMG_20210222_213525.jpg"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"IMG_19800101_000000.jpg":       "2004-07-03", // Falls back to the modification time.
+		"IMG_" + future + "_000000.jpg": "",
+		"IMG_20210222_213525.jpg":       "2021-02-22",
+	}
+	for _, entry := range entries {
+		opts := options{
+			matchers:      mediaMatchers,
+			earliestDate:  time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+			rejectFuture:  true,
+			mtimeFallback: entry.Name() == "IMG_19800101_000000.jpg",
+			nearMisses:    &NearMisses{},
+		}
+		date, _, err := fileDate(filepath.Join(dir, entry.Name()), entry, opts)
+		wantDate := want[entry.Name()]
+		if wantDate == "" {
+			if err == nil {
+				t.Errorf("Expected error but received none (file name: %s)", entry.Name())
+			}
+		} else if err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		} else if got := date.Format("2006-01-02"); got != wantDate {
+			t.Errorf("got %s, want %s (file name: %s)", got, wantDate, entry.Name())
+		}
+		if wantDate != "2021-02-22" && opts.nearMisses.InvalidDates != 1 {
+			t.Errorf("got %d invalid dates, want 1 (file name: %s)", opts.nearMisses.InvalidDates, entry.Name())
+		}
+	}
+}
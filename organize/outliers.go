@@ -0,0 +1,74 @@
+package organize
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// minOutlierBatch is the smallest number of files among which clock
+	// outliers are looked for. Smaller batches tell too little.
+	minOutlierBatch = 10
+	// outlierGap is the smallest gap between the dates of a batch that
+	// separates its groups of files.
+	outlierGap = 365 * 24 * time.Hour
+	// maxOutlierShare is the largest share of a batch that a group of files
+	// may hold to be considered outliers.
+	maxOutlierShare = 0.1
+)
+
+// clockOutliers reports, for each of dates, whether it is suspected to come
+// from a camera with a wrong clock (e.g. reset to 2000-01-01), because it is
+// far from the dates of most other files of the batch. Dates are split into
+// groups at gaps of more than outlierGap, and the dates of small groups are
+// outliers.
+func clockOutliers(dates []time.Time) []bool {
+	outliers := make([]bool, len(dates))
+	if len(dates) < minOutlierBatch {
+		return outliers
+	}
+	order := make([]int, len(dates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return dates[order[a]].Before(dates[order[b]]) })
+
+	var groups [][]int
+	for k, i := range order {
+		if k == 0 || dates[i].Sub(dates[order[k-1]]) > outlierGap {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], i)
+	}
+	for _, g := range groups {
+		if float64(len(g)) <= maxOutlierShare*float64(len(dates)) {
+			for _, i := range g {
+				outliers[i] = true
+			}
+		}
+	}
+	return outliers
+}
+
+// holdOutliers removes the moves of p whose dates are suspected clock errors,
+// as found by clockOutliers, and lists their files as unmatched instead, so
+// they can be reviewed.
+func holdOutliers(p *Plan, opts options) {
+	dates := make([]time.Time, len(p.Moves))
+	for i, m := range p.Moves {
+		dates[i] = m.Date
+	}
+	outliers := clockOutliers(dates)
+	moves := p.Moves[:0]
+	for i, m := range p.Moves {
+		if !outliers[i] {
+			moves = append(moves, m)
+			continue
+		}
+		reason := fmt.Sprintf("%q is dated %s, far from the other files; suspected camera clock error, left in place for review", m.Src, m.Date.Format("2006-01-02"))
+		p.Unmatched = append(p.Unmatched, UnmatchedFile{m.Src, reason, m.Sidecars, true})
+		opts.nearMisses.outlier()
+	}
+	p.Moves = moves
+}
@@ -0,0 +1,77 @@
+package organize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClockOutliers(t *testing.T) {
+	date := func(y, m, d int) time.Time { return time.Date(y, time.Month(m), d, 12, 0, 0, 0, time.UTC) }
+	var batch []time.Time
+	for d := 1; d <= 18; d++ {
+		batch = append(batch, date(2023, 3, d))
+	}
+	// Trips spanning the new year belong together.
+	spanning := []time.Time{date(2022, 12, 20), date(2022, 12, 28), date(2023, 1, 3)}
+	for i := 0; i < 8; i++ {
+		spanning = append(spanning, date(2023, 1, 5+i))
+	}
+
+	tests := []struct {
+		dates []time.Time
+		want  []int
+	}{
+		{append([]time.Time{date(2000, 1, 1), date(2000, 1, 1)}, batch...), []int{0, 1}},
+		{append(append([]time.Time{}, batch...), date(2038, 1, 19)), []int{18}},
+		{batch, nil},
+		{spanning, nil},
+		// Too few files to tell.
+		{[]time.Time{date(2000, 1, 1), date(2023, 3, 1), date(2023, 3, 2)}, nil},
+		// Two large groups are both legitimate.
+		{append(append([]time.Time{}, batch[:9]...), spanning[:9]...), nil},
+	}
+
+	for i, tt := range tests {
+		var got []int
+		for j, outlier := range clockOutliers(tt.dates) {
+			if outlier {
+				got = append(got, j)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got %v, want %v (test %d)", got, tt.want, i)
+		}
+	}
+}
+
+func TestPlanHoldOutliers(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"IMG_20000101_000001.jpg"}
+	for i := 1; i <= 12; i++ {
+		names = append(names, fmt.Sprintf("IMG_202303%02d_120000.jpg", i))
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n := &NearMisses{}
+	p, err := planOrganize(dir, options{matchers: mediaMatchers, holdOutliers: true, nearMisses: n})
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if len(p.Moves) != 12 || len(p.Unmatched) != 1 {
+		t.Fatalf("got %d moves and %d unmatched files, want 12 and 1", len(p.Moves), len(p.Unmatched))
+	}
+	if got, want := filepath.Base(p.Unmatched[0].Path), names[0]; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if n.Outliers != 1 {
+		t.Errorf("got %d outliers, want 1", n.Outliers)
+	}
+}
@@ -0,0 +1,202 @@
+package organize
+
+import (
+	"io/fs"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+// PlannedMove is the intended move of a recognized file into the dated
+// directory DestDir.
+type PlannedMove struct {
+	Src     string    `json:"src"`
+	DestDir string    `json:"dest_dir"`
+	Date    time.Time `json:"date"`
+	// WallClock is set if Date is the wall clock time of the device, found in
+	// the name without a time zone, which folder date policies leave alone.
+	WallClock bool `json:"wall_clock,omitempty"`
+	// Archived is the path of an identical file already in DestDir, if any.
+	// Such files are not moved.
+	Archived string `json:"archived,omitempty"`
+	// Name is the name the file is moved as, if it is renamed.
+	Name string `json:"name,omitempty"`
+	// Sidecars are the paths of the file's sidecar files (e.g. XMP metadata),
+	// which are moved along with it.
+	Sidecars []string `json:"sidecars,omitempty"`
+}
+
+// UnmatchedFile is a file for which no date could be determined.
+type UnmatchedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	// Sidecars are the paths of the file's sidecar files, which go where the
+	// file goes.
+	Sidecars []string `json:"sidecars,omitempty"`
+	// Held is set for files that could be dated but are held back for review,
+	// such as suspected camera clock errors, which stay where they are.
+	Held bool `json:"held,omitempty"`
+}
+
+// SkippedDir is a directory left out of a recursive scan.
+type SkippedDir struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Plan lists what organizing a directory would do, without doing it.
+type Plan struct {
+	Moves     []PlannedMove   `json:"moves"`
+	Unmatched []UnmatchedFile `json:"unmatched"`
+	Skipped   []SkippedDir    `json:"skipped,omitempty"`
+}
+
+// planOrganize determines where each file in dirName should be moved to,
+// without touching the file system.
+func planOrganize(dirName string, opts options) (Plan, error) {
+	var p Plan
+	root := destRoot(dirName, opts)
+	files, skipped, err := sourceFiles(dirName, opts)
+	if err != nil {
+		return p, err
+	}
+	p.Skipped = skipped
+	sidecars := findSidecars(files)
+	isSidecar := make(map[string]bool)
+	for _, paths := range sidecars {
+		for _, path := range paths {
+			isSidecar[path] = true
+		}
+	}
+	var dated []datedFile
+	for _, f := range files {
+		// Sidecars are moved along with their media file.
+		if !isSidecar[f.path] {
+			dated = append(dated, datedFile{sourceFile: f})
+		}
+	}
+	// Dating may read the files' metadata, which the workers do in parallel.
+	progress := newProgressCounter(PhaseDating, len(dated), opts)
+	forEach(len(dated), parallelism(opts), func(i int) {
+		f := &dated[i]
+		f.date, f.wallClock, f.err = fileDate(f.path, f.entry, opts)
+		if f.err == nil {
+			f.date = folderDate(f.date, f.wallClock, opts.folderDate)
+		}
+		progress.step(1, 0)
+	})
+	pairRAWFiles(dated)
+	pairLivePhotos(dated)
+	dated = append(dated, avchdClips(dirName, sidecars, opts)...)
+	claimed := make(map[string]bool)
+	var entries []fs.DirEntry
+	for _, f := range dated {
+		path, date := f.path, f.date
+		if f.err != nil {
+			p.Unmatched = append(p.Unmatched, UnmatchedFile{path, f.err.Error(), sidecars[path], false})
+			continue
+		}
+		destDirName, err := folderPath(MediaFile{path, date}, opts)
+		if err != nil {
+			p.Unmatched = append(p.Unmatched, UnmatchedFile{path, err.Error(), sidecars[path], false})
+			continue
+		}
+		destPath := filepath.Join(root, destDirName)
+		if opts.classify {
+			destPath = filepath.Join(root, classDirs[classify(path)], destDirName)
+		}
+		// Recursive scans may come across files that are already in place.
+		if filepath.Dir(path) == destPath {
+			continue
+		}
+		// Files renamed by a template are named once all moves are known.
+		if !isRenamed(path, opts) {
+			claimed[filepath.Join(destPath, filepath.Base(path))] = true
+		}
+		p.Moves = append(p.Moves, PlannedMove{path, destPath, date, f.wallClock, "", "", sidecars[path]})
+		entries = append(entries, f.entry)
+	}
+	// Looking for archived copies may hash files, which the workers do in
+	// parallel.
+	forEach(len(p.Moves), parallelism(opts), func(i int) {
+		m := &p.Moves[i]
+		archived, err := findArchivedCopy(m.Src, entries[i], m.DestDir, opts.paranoid)
+		if err != nil {
+			log.Printf("unable to check whether %q is already archived: %v", m.Src, err)
+		}
+		m.Archived = archived
+	})
+	renameMoves(p.Moves, claimed, opts)
+	if err := resolveConflicts(&p, opts); err != nil {
+		return p, err
+	}
+	shortenLongNames(&p)
+	if opts.holdOutliers {
+		holdOutliers(&p, opts)
+	}
+	return p, nil
+}
+
+// executePlan performs the moves of p, reporting for each whether the file was
+// moved. Moves into different directories are performed by up to opts.workers
+// workers in parallel, as many as opts.maxMemory allows.
+func executePlan(dirName string, p Plan, opts options) []bool {
+	moved := make([]bool, len(p.Moves))
+	archived := make([]bool, len(p.Moves))
+	progress := newProgressCounter(PhaseMoving, len(p.Moves), opts)
+	workers := parallelism(opts)
+	groups := moveGroups(p.Moves, workers)
+	forEach(len(groups), workers, func(g int) {
+		for _, i := range groups[g] {
+			var size int64
+			if opts.progress != nil {
+				size = fileSize(p.Moves[i].Src)
+			}
+			moved[i], archived[i] = executeMove(dirName, p.Moves[i], opts)
+			if !moved[i] {
+				size = 0
+			}
+			progress.step(1, size)
+		}
+	})
+	count := 0
+	for _, a := range archived {
+		if a {
+			count++
+		}
+	}
+	if count > 0 && opts.duplicates != DuplicateDelete {
+		log.Printf("Left %d files that are already archived in place", count)
+	}
+	return moved
+}
+
+// executeMove performs the move m of a plan for dirName, reporting whether the
+// file was moved and whether it was found to be archived already.
+func executeMove(dirName string, m PlannedMove, opts options) (moved, archived bool) {
+	fileName := filepath.Base(m.Src)
+	if m.Name != "" {
+		fileName = m.Name
+	}
+	// The copy suffix is resolved last, on the name the file was planned to
+	// move as.
+	fileName, ok := resolveCopySuffix(m.Src, fileName, m.DestDir, m.Sidecars, opts)
+	if !ok {
+		return false, false
+	}
+	if m.Archived != "" {
+		handleDuplicate(m.Src, m.Archived, m.Sidecars, opts)
+		return false, true
+	}
+	if opts.budget.limited() && !opts.budget.admit(m) {
+		return false, false
+	}
+	if !moveIntoDirAs(m.Src, m.DestDir, fileName, opts) {
+		return false, false
+	}
+	for _, sidecar := range m.Sidecars {
+		moveIntoDirAs(sidecar, m.DestDir, sidecarName(sidecar, filepath.Base(m.Src), fileName), opts)
+	}
+	afterMove(destRoot(dirName, opts), filepath.Join(m.DestDir, fileName), m.Date, opts)
+	return true, false
+}
@@ -0,0 +1,115 @@
+package organize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileSnapshot is the size and modification time of a file when a plan was
+// made.
+type FileSnapshot struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// PlanFile is a plan saved for review, to be performed later by Apply exactly
+// as made, provided the files it concerns haven't changed since.
+type PlanFile struct {
+	// Dir is the absolute path of the organized directory.
+	Dir string `json:"dir"`
+	Plan
+	// Files holds the state of the files the moves of the plan concern,
+	// including their destinations, by path. Files that didn't exist are
+	// null.
+	Files map[string]*FileSnapshot `json:"files"`
+}
+
+// PlanFile plans organizing dirName like Plan, recording the state of the
+// files concerned for Apply to check.
+func (o *Organizer) PlanFile(dirName string) (PlanFile, error) {
+	abs, err := filepath.Abs(dirName)
+	if err != nil {
+		return PlanFile{}, err
+	}
+	p, err := o.Plan(abs)
+	if err != nil {
+		return PlanFile{}, err
+	}
+	pf := PlanFile{Dir: abs, Plan: p, Files: make(map[string]*FileSnapshot)}
+	for _, path := range planPaths(p) {
+		pf.Files[path] = snapshotFile(path)
+	}
+	return pf, nil
+}
+
+// Changed returns the paths of the files of pf that changed, appeared or
+// disappeared since it was made, sorted.
+func (pf PlanFile) Changed() []string {
+	var changed []string
+	for path, was := range pf.Files {
+		is := snapshotFile(path)
+		if (was == nil) != (is == nil) || was != nil && (was.Size != is.Size || !was.ModTime.Equal(is.ModTime)) {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Apply performs the moves of pf, failing without moving anything if any of
+// the files it concerns changed since it was made. It returns the number of
+// files moved, or that would have been moved in a dry run. Unlike Organize,
+// it does nothing beyond the moves of the plan.
+func (o *Organizer) Apply(pf PlanFile) (int, error) {
+	if changed := pf.Changed(); len(changed) > 0 {
+		return 0, fmt.Errorf("%d files changed since the plan was made: %s", len(changed), strings.Join(changed, ", "))
+	}
+	o.startRun(pf.Dir)
+	o.opts.report.Skipped = pf.Skipped
+	for _, u := range pf.Unmatched {
+		o.opts.report.add(u.Path, StatusUnmatched, "", u.Reason)
+	}
+	count := 0
+	for _, moved := range o.Execute(pf.Dir, pf.Plan) {
+		if moved {
+			count++
+		}
+	}
+	o.opts.report.addDates(pf.Moves)
+	o.reportRemaining()
+	return count, nil
+}
+
+// planPaths returns the paths of the files the moves of p concern: the files
+// moved, their sidecars, the archived copies and the destinations.
+func planPaths(p Plan) []string {
+	var paths []string
+	for _, m := range p.Moves {
+		name := filepath.Base(m.Src)
+		if m.Name != "" {
+			name = m.Name
+		}
+		paths = append(paths, m.Src, filepath.Join(m.DestDir, name))
+		if m.Archived != "" {
+			paths = append(paths, m.Archived)
+		}
+		for _, sidecar := range m.Sidecars {
+			paths = append(paths, sidecar, filepath.Join(m.DestDir, sidecarName(sidecar, filepath.Base(m.Src), name)))
+		}
+	}
+	return paths
+}
+
+// snapshotFile returns the state of the file at path, or nil if there is
+// none.
+func snapshotFile(path string) *FileSnapshot {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil
+	}
+	return &FileSnapshot{info.Size(), info.ModTime()}
+}
@@ -0,0 +1,84 @@
+package organize
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name string
+		// change changes the directory after planning.
+		change      func(dir string) error
+		errExpected bool
+	}{
+		{"unchanged", func(dir string) error { return nil }, false},
+		{"source modified", func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "IMG_20210222_213525.jpg"), []byte("edited"), 0600)
+		}, true},
+		{"source removed", func(dir string) error {
+			return os.Remove(filepath.Join(dir, "VID_20210223_101010.mp4"))
+		}, true},
+		{"destination taken", func(dir string) error {
+			if err := os.Mkdir(filepath.Join(dir, "2021-02-22"), 0700); err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(dir, "2021-02-22", "IMG_20210222_213525.jpg"), []byte("other"), 0600)
+		}, true},
+		{"unrelated file added", func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0600)
+		}, false},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for _, name := range []string{"IMG_20210222_213525.jpg", "VID_20210223_101010.mp4"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		o, err := New(WithExternalTools(false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pf, err := o.PlanFile(dir)
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s (%s)", err, tt.name)
+		}
+		// Plans are reviewed as JSON.
+		data, err := json.Marshal(pf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loaded PlanFile
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			t.Fatal(err)
+		}
+		if err := tt.change(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		moved, err := o.Apply(loaded)
+		if err != nil && !tt.errExpected {
+			t.Errorf("Expected no error but received: %s (%s)", err, tt.name)
+		}
+		if tt.errExpected {
+			if err == nil {
+				t.Errorf("Expected error but received none (%s)", tt.name)
+			}
+			if moved != 0 {
+				t.Errorf("got %d moved, want 0 (%s)", moved, tt.name)
+			}
+			continue
+		}
+		if moved != 2 {
+			t.Errorf("got %d moved, want 2 (%s)", moved, tt.name)
+		}
+		for _, path := range []string{"2021-02-22/IMG_20210222_213525.jpg", "2021-02-23/VID_20210223_101010.mp4"} {
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path))); err != nil {
+				t.Errorf("Expected no error but received: %s (%s)", err, tt.name)
+			}
+		}
+	}
+}
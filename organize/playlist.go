@@ -0,0 +1,55 @@
+package organize
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// playlistPath returns the path of the M3U playlist listing the files of the
+// given date. Playlists are kept at the root of the organized directory so the
+// paths in them, which are relative to the playlist, are relative to the root.
+func playlistPath(root string, date time.Time) string {
+	return filepath.Join(root, date.Format("2006-01-02")+".m3u")
+}
+
+// addToPlaylist adds the file at filePath to the playlist of the given date,
+// creating the playlist if needed. Files already listed are not added again,
+// so re-running on a partially organized directory is harmless.
+func addToPlaylist(root string, date time.Time, filePath string) error {
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil {
+		return err
+	}
+	entry := filepath.ToSlash(rel)
+	path := playlistPath(root, date)
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	empty := true
+	for scanner.Scan() {
+		empty = false
+		if scanner.Text() == entry {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// The scanner consumed the whole file, so writes append to it.
+	if empty {
+		if _, err := fmt.Fprintln(f, "#EXTM3U"); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintln(f, entry); err != nil {
+		return err
+	}
+	return f.Close()
+}
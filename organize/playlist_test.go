@@ -0,0 +1,32 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAddToPlaylist(t *testing.T) {
+	root := t.TempDir()
+	date := time.Date(2021, 2, 22, 0, 0, 0, 0, time.UTC)
+	files := []string{
+		filepath.Join(root, "2021-02-22", "IMG_20210222_213525.jpg"),
+		filepath.Join(root, "2021-02-22", "VID_20210222_124124.mp4"),
+		filepath.Join(root, "2021-02-22", "IMG_20210222_213525.jpg"), // Already listed.
+	}
+	for _, f := range files {
+		if err := addToPlaylist(root, date, f); err != nil {
+			t.Fatalf("addToPlaylist(%q) returned error: %v", f, err)
+		}
+	}
+
+	got, err := os.ReadFile(filepath.Join(root, "2021-02-22.m3u"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "#EXTM3U\n2021-02-22/IMG_20210222_213525.jpg\n2021-02-22/VID_20210222_124124.mp4\n"
+	if string(got) != want {
+		t.Errorf("got playlist %q, want %q", got, want)
+	}
+}
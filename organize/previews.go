@@ -0,0 +1,93 @@
+package organize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+// PreviewsDirName is the directory, at the root of the organized directory,
+// holding the small preview proxies generated for videos.
+const PreviewsDirName = ".previews"
+
+// previewPath returns the path of the preview proxy of the video with the
+// given content hash. Previews are sharded by the first two hex digits of the
+// hash to keep directories small.
+func previewPath(root, hash string) string {
+	return filepath.Join(root, PreviewsDirName, hash[:2], hash+".mp4")
+}
+
+// hashFile returns the hex encoded SHA-256 hash of the file's contents.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// memoryPerFFmpegThread is roughly how much memory each ffmpeg thread takes to
+// decode high resolution videos.
+const memoryPerFFmpegThread = 128 << 20
+
+// previewThreads returns the number of threads ffmpeg may use for a preview
+// within maxMemory bytes, leaving half of it to the rest of the run, or 0 if
+// there is no limit.
+func previewThreads(maxMemory int64) int {
+	if maxMemory <= 0 {
+		return 0
+	}
+	threads := int(maxMemory / 2 / memoryPerFFmpegThread)
+	if threads < 1 {
+		threads = 1
+	}
+	return threads
+}
+
+// generatePreview creates a small, low bitrate copy of the video at
+// videoPath in the previews tree under root using ffmpeg, so the video can be
+// previewed without reading the original (e.g. from a slow NAS). Previews
+// are keyed by content hash, so a preview is only generated once per video.
+// ffmpeg uses up to threads threads for decoding and encoding each, or as many
+// as it sees fit if threads is 0.
+func generatePreview(root, videoPath string, threads int) error {
+	hash, err := hashFile(videoPath)
+	if err != nil {
+		return err
+	}
+	dest := previewPath(root, hash)
+	if _, err := os.Stat(dest); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return err
+	}
+	// Encode to a temporary name so an interrupted run never leaves a
+	// truncated preview behind under the final name.
+	tmp := dest + ".tmp.mp4"
+	var threadArgs []string
+	if threads > 0 {
+		threadArgs = []string{"-threads", strconv.Itoa(threads)}
+	}
+	args := append([]string{"-y", "-loglevel", "error"}, threadArgs...)
+	args = append(args, "-i", videoPath)
+	args = append(args, threadArgs...)
+	args = append(args, "-vf", "scale=-2:360", "-c:v", "libx264", "-preset", "veryfast", "-crf", "30",
+		"-c:a", "aac", "-b:a", "64k", "-movflags", "+faststart", tmp)
+	cmd := exec.Command("ffmpeg", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("ffmpeg failed for %q: %v: %s", videoPath, err, out)
+	}
+	return os.Rename(tmp, dest)
+}
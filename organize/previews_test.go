@@ -0,0 +1,31 @@
+package organize
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPreviewPath(t *testing.T) {
+	got := previewPath("archive", "ab12cd")
+	want := filepath.Join("archive", ".previews", "ab", "ab12cd.mp4")
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestPreviewThreads(t *testing.T) {
+	tests := []struct {
+		maxMemory int64
+		want      int
+	}{
+		{0, 0},
+		{64 << 20, 1},
+		{512 << 20, 2},
+		{4 << 30, 16},
+	}
+	for _, tt := range tests {
+		if got := previewThreads(tt.maxMemory); got != tt.want {
+			t.Errorf("got %d, want %d (max memory: %d)", got, tt.want, tt.maxMemory)
+		}
+	}
+}
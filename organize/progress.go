@@ -0,0 +1,58 @@
+package organize
+
+import "os"
+
+// The phases of a run reported by Progress.
+const (
+	// PhaseDating is the phase in which the dates of the files are
+	// determined, which may read their metadata.
+	PhaseDating = "dating"
+	// PhaseMoving is the phase in which the files are moved.
+	PhaseMoving = "moving"
+)
+
+// Progress describes how far a run has got through a phase.
+type Progress struct {
+	Phase string
+	// Done is the number of files handled so far in the phase, out of
+	// Total.
+	Done, Total int
+	// Bytes is the size of the files moved so far.
+	Bytes int64
+}
+
+// progressCounter counts the progress of the workers of a phase.
+type progressCounter struct {
+	p    Progress
+	opts options
+}
+
+// newProgressCounter returns a progressCounter for the phase of total files,
+// and reports the start of the phase.
+func newProgressCounter(phase string, total int, opts options) *progressCounter {
+	c := &progressCounter{Progress{Phase: phase, Total: total}, opts}
+	c.step(0, 0)
+	return c
+}
+
+// step counts done more files handled and bytes more bytes moved, and calls
+// opts.progress, if set, with the progress so far. Calls are serialized.
+func (c *progressCounter) step(done int, bytes int64) {
+	if c.opts.progress == nil {
+		return
+	}
+	runMu.Lock()
+	defer runMu.Unlock()
+	c.p.Done += done
+	c.p.Bytes += bytes
+	c.opts.progress(c.p)
+}
+
+// fileSize returns the size of the file at path, or zero if unknown.
+func fileSize(path string) int64 {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,34 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrganizeProgress(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"IMG_20210222_213525.jpg", "IMG_20210223_080000.jpg", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []Progress
+	o, err := New(WithExternalTools(false), WithProgress(func(p Progress) { got = append(got, p) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Organize(dir); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if len(got) == 0 {
+		t.Fatalf("got no progress, want some")
+	}
+	if got[0].Phase != PhaseDating || got[0].Total != 3 {
+		t.Errorf("got %+v, want dating of 3 files", got[0])
+	}
+	want := Progress{PhaseMoving, 2, 2, int64(2 * len("IMG_20210222_213525.jpg"))}
+	if last := got[len(got)-1]; last != want {
+		t.Errorf("got %+v, want %+v", last, want)
+	}
+}
@@ -0,0 +1,99 @@
+package organize
+
+import (
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// playlistNameRegexp matches the names of the playlists kept at the root of
+// the organized directory, as named by playlistPath.
+var playlistNameRegexp = regexp.MustCompile(`^\d{4}-\d\d-\d\d\.m3u$`)
+
+// quarantineUnmatched moves the images and videos of p that could not be
+// dated, and their sidecar files, into the quarantine directory under the
+// destination root, so they can be reviewed in one place. Files held back for
+// review are left in place. It returns the number of files moved.
+func quarantineUnmatched(dirName string, p Plan, opts options) int {
+	dir := filepath.Join(destRoot(dirName, opts), filepath.FromSlash(opts.quarantine))
+	count := 0
+	for _, u := range p.Unmatched {
+		if !IsMedia(u.Path) || u.Held || filepath.Dir(u.Path) == dir {
+			continue
+		}
+		if moveUnmatchedFile(u, dir, opts) {
+			count++
+		}
+	}
+	if count > 0 && !opts.dryRun {
+		log.Printf("Moved %d files that could not be dated to %q", count, dir)
+	}
+	return count
+}
+
+// moveUnmatched moves the files of p that could not be dated, other than
+// those quarantined, and their sidecar files into the unmatched directory
+// under the destination root, in the subdirectories they were found in, so
+// that nothing is left in dirName unhandled. Hidden files, such as .nomedia,
+// belong to their directory and are left in place, as are files held back for
+// review and the files the organizer writes itself. It returns the number of
+// files moved.
+func moveUnmatched(dirName string, p Plan, opts options) int {
+	organized := destRoot(dirName, opts)
+	root := filepath.Join(organized, filepath.FromSlash(opts.unmatchedDir))
+	count := 0
+	for _, u := range p.Unmatched {
+		if strings.HasPrefix(filepath.Base(u.Path), ".") || u.Held || isArtifact(u.Path, organized, opts) {
+			continue
+		}
+		if opts.quarantine != "" && IsMedia(u.Path) {
+			continue
+		}
+		dir := root
+		if rel, ok := relativePath(dirName, filepath.Dir(u.Path)); ok {
+			dir = filepath.Join(root, filepath.FromSlash(rel))
+		}
+		if moveUnmatchedFile(u, dir, opts) {
+			count++
+		}
+	}
+	if count > 0 && !opts.dryRun {
+		log.Printf("Moved %d files that could not be dated to %q", count, root)
+	}
+	return count
+}
+
+// moveUnmatchedFile moves the file u and its sidecar files into dir,
+// reporting whether the file was moved.
+func moveUnmatchedFile(u UnmatchedFile, dir string, opts options) bool {
+	if !moveIntoDir(u.Path, dir, opts) {
+		return false
+	}
+	for _, sidecar := range u.Sidecars {
+		moveIntoDir(sidecar, dir, opts)
+	}
+	return true
+}
+
+// isArtifact reports whether the file at path is one the organizer writes
+// itself, rather than one to organize: a playlist at the root of the
+// organized directory, the journal or the state file.
+func isArtifact(path, root string, opts options) bool {
+	if filepath.Dir(path) == root && playlistNameRegexp.MatchString(filepath.Base(path)) {
+		return true
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, p := range []string{opts.journal, opts.stateFile} {
+		if p == "" {
+			continue
+		}
+		if p, err := filepath.Abs(p); err == nil && p == abs {
+			return true
+		}
+	}
+	return false
+}
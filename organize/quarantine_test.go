@@ -0,0 +1,125 @@
+package organize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrganizeQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"IMG_20210222_213525.jpg", "image (3).jpg", "report.pdf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithExternalTools(false), WithQuarantine("Unsorted"), WithRecursive(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for run := 0; run < 2; run++ {
+		if _, err := o.Organize(dir); err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+	}
+	for _, path := range []string{"2021-02-22/IMG_20210222_213525.jpg", "Unsorted/image (3).jpg", "report.pdf"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path))); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+}
+
+func TestNewInvalidQuarantine(t *testing.T) {
+	for _, dir := range []string{"/tmp/Unsorted", "../Unsorted"} {
+		if _, err := New(WithQuarantine(dir)); err == nil {
+			t.Errorf("Expected error but received none (dir: %s)", dir)
+		}
+	}
+}
+
+func TestOrganizeUnmatchedDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"IMG_20210222_213525.jpg", "image (3).jpg", "image (3).xmp", "report.pdf", "DCIM/notes.txt", ".nomedia"} {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithExternalTools(false), WithQuarantine("Unsorted"), WithUnmatchedDir("_unsorted"), WithRecursive(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for run := 0; run < 2; run++ {
+		if _, err := o.Organize(dir); err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+	}
+	for _, path := range []string{"2021-02-22/IMG_20210222_213525.jpg", "Unsorted/image (3).jpg", "Unsorted/image (3).xmp", "_unsorted/report.pdf", "_unsorted/DCIM/notes.txt", ".nomedia"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path))); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+}
+
+func TestOrganizeUnmatchedDirLeavesHeldFiles(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"IMG_20000101_000001.jpg"}
+	for i := 1; i <= 12; i++ {
+		names = append(names, fmt.Sprintf("IMG_202303%02d_120000.jpg", i))
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithExternalTools(false), WithUnmatchedDir("_unsorted"), WithHoldOutliers(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Organize(dir); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	// The suspected clock error is left in place for review.
+	if _, err := os.Stat(filepath.Join(dir, names[0])); err != nil {
+		t.Errorf("Expected no error but received: %s", err)
+	}
+}
+
+func TestOrganizeUnmatchedDirLeavesOwnFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "IMG_20210222_213525.jpg"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := New(WithExternalTools(false), WithUnmatchedDir("_unsorted"), WithPlaylists(true), WithJournal(filepath.Join(dir, "journal.jsonl")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The second run comes across the playlist and journal written by the
+	// first, which are not taken for files to organize.
+	for run := 0; run < 2; run++ {
+		if _, err := o.Organize(dir); err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+	}
+	for _, path := range []string{"2021-02-22.m3u", "journal.jsonl"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+}
+
+func TestNewInvalidUnmatchedDir(t *testing.T) {
+	for _, dir := range []string{"/tmp/_unsorted", "../_unsorted", "."} {
+		if _, err := New(WithUnmatchedDir(dir)); err == nil {
+			t.Errorf("Expected error but received none (dir: %s)", dir)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package organize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// quickTimeExtensions lists the (lower case) extensions of files in the
+// QuickTime / ISO base media file format read by quickTimeDate.
+var quickTimeExtensions = map[string]bool{
+	".mov": true, ".mp4": true, ".m4v": true, ".3gp": true,
+}
+
+// xmpUUID identifies the top level uuid box holding XMP metadata.
+var xmpUUID = []byte{0xBE, 0x7A, 0xCF, 0xCB, 0x97, 0xA9, 0x42, 0xE8, 0x9C, 0x71, 0x99, 0x94, 0x91, 0xE3, 0xAF, 0xAC}
+
+// Limits on the size of metadata boxes read into memory, to protect against
+// corrupt files. Real world moov boxes of long videos are a few megabytes.
+const (
+	maxMoovSize = 64 << 20
+	maxXMPSize  = 4 << 20
+)
+
+// quickTimeEpoch is the epoch of the timestamps in mvhd boxes.
+var quickTimeEpoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// quickTimeDate reads the capture date from the metadata of the QuickTime or
+// MP4 file at path. In order of preference it uses the
+// com.apple.quicktime.creationdate metadata key (which, unlike the others,
+// records the time zone of the capture), the XMP packet and finally the
+// creation time of the movie header, which is in UTC and often reflects when
+// the file was last re-encoded rather than captured.
+func quickTimeDate(path string) (time.Time, error) {
+	moov, xmp, err := readQuickTimeMetadata(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date, err := quickTimeKeysDate(moov); err == nil {
+		return date, nil
+	}
+	if xmp == nil {
+		xmp = findBox(findBox(moov, "udta"), "XMP_")
+	}
+	if date, err := xmpDate(xmp); err == nil {
+		return date, nil
+	}
+	return movieHeaderDate(findBox(moov, "mvhd"))
+}
+
+// readQuickTimeMetadata returns the payloads of the moov box and, if present,
+// of the top level XMP uuid box of the QuickTime or MP4 file at path.
+func readQuickTimeMetadata(path string) (moov, xmp []byte, err error) {
+	if !quickTimeExtensions[strings.ToLower(filepath.Ext(path))] {
+		return nil, nil, fmt.Errorf("%q is not a QuickTime file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	for {
+		typ, size, err := readBoxHeader(f)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case size < 0:
+			// The box extends to the end of the file.
+			_, err = f.Seek(0, io.SeekEnd)
+		case typ == "moov" && size <= maxMoovSize:
+			moov = make([]byte, size)
+			_, err = io.ReadFull(f, moov)
+		case typ == "uuid" && size <= maxXMPSize:
+			payload := make([]byte, size)
+			if _, err = io.ReadFull(f, payload); err == nil && bytes.HasPrefix(payload, xmpUUID) {
+				xmp = payload[len(xmpUUID):]
+			}
+		default:
+			_, err = f.Seek(size, io.SeekCurrent)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if moov == nil {
+		return nil, nil, fmt.Errorf("no movie metadata found in %q", path)
+	}
+	return moov, xmp, nil
+}
+
+// readBoxHeader reads the header of the next box from r, returning its type
+// and the size of its payload. A negative size means the box extends to the
+// end of the file.
+func readBoxHeader(r io.Reader) (typ string, size int64, err error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errors.New("truncated box header")
+		}
+		return "", 0, err
+	}
+	typ = string(header[4:])
+	switch boxSize := binary.BigEndian.Uint32(header[:4]); boxSize {
+	case 0:
+		return typ, -1, nil
+	case 1:
+		var largeSize [8]byte
+		if _, err := io.ReadFull(r, largeSize[:]); err != nil {
+			return "", 0, errors.New("truncated box header")
+		}
+		size = int64(binary.BigEndian.Uint64(largeSize[:])) - 16
+	default:
+		size = int64(boxSize) - 8
+	}
+	if size < 0 {
+		return "", 0, fmt.Errorf("invalid size of %q box", typ)
+	}
+	return typ, size, nil
+}
+
+// boxes splits b into the payloads of the boxes it contains, keyed by type.
+// Only the first box of each type is kept.
+func boxes(b []byte) map[string][]byte {
+	children := make(map[string][]byte)
+	for len(b) >= 8 {
+		size := int(binary.BigEndian.Uint32(b))
+		typ := string(b[4:8])
+		if size < 8 || size > len(b) {
+			break
+		}
+		if _, ok := children[typ]; !ok {
+			children[typ] = b[8:size]
+		}
+		b = b[size:]
+	}
+	return children
+}
+
+// findBox returns the payload of the first box of type typ in b, or nil.
+func findBox(b []byte, typ string) []byte {
+	return boxes(b)[typ]
+}
+
+// metaChildren returns the boxes within the payload of a meta box. Meta boxes
+// written by Apple are plain containers, while ISO ones start with a version
+// and flags field, which is skipped.
+func metaChildren(meta []byte) map[string][]byte {
+	if len(meta) >= 12 && string(meta[4:8]) != "hdlr" && string(meta[8:12]) == "hdlr" {
+		meta = meta[4:]
+	}
+	return boxes(meta)
+}
+
+// quickTimeKeysDate returns the value of the com.apple.quicktime.creationdate
+// metadata key in moov.
+func quickTimeKeysDate(moov []byte) (time.Time, error) {
+	value, ok := quickTimeKeyValue(moov, "com.apple.quicktime.creationdate")
+	if !ok {
+		return time.Time{}, errors.New("no creation date metadata key")
+	}
+	return parseMetadataDate(value)
+}
+
+// quickTimeKeyValue returns the value of the given metadata key in moov, and
+// whether it is present.
+func quickTimeKeyValue(moov []byte, key string) (string, bool) {
+	for _, meta := range [][]byte{findBox(moov, "meta"), findBox(findBox(moov, "udta"), "meta")} {
+		children := metaChildren(meta)
+		keys, ilst := children["keys"], children["ilst"]
+		if len(keys) < 8 {
+			continue
+		}
+		// Find the 1-based index of the creation date key.
+		index, count := 0, int(binary.BigEndian.Uint32(keys[4:]))
+		entries := keys[8:]
+		for i := 1; i <= count && len(entries) >= 8; i++ {
+			size := int(binary.BigEndian.Uint32(entries))
+			if size < 8 || size > len(entries) {
+				break
+			}
+			if string(entries[8:size]) == key {
+				index = i
+				break
+			}
+			entries = entries[size:]
+		}
+		if index == 0 {
+			continue
+		}
+		var indexType [4]byte
+		binary.BigEndian.PutUint32(indexType[:], uint32(index))
+		// The data box holds a type indicator and locale before the value.
+		data := findBox(findBox(ilst, string(indexType[:])), "data")
+		if len(data) < 8 {
+			continue
+		}
+		return string(data[8:]), true
+	}
+	return "", false
+}
+
+// xmpDateRegexps extract the capture date from an XMP packet, in order of
+// preference. XMP allows properties as either attributes or elements.
+var xmpDateRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`exif:DateTimeOriginal(?:="|>)([^"<]+)`),
+	regexp.MustCompile(`photoshop:DateCreated(?:="|>)([^"<]+)`),
+	regexp.MustCompile(`xmp:CreateDate(?:="|>)([^"<]+)`),
+}
+
+// xmpDate returns the capture date recorded in an XMP packet.
+func xmpDate(xmp []byte) (time.Time, error) {
+	for _, re := range xmpDateRegexps {
+		if m := re.FindSubmatch(xmp); m != nil {
+			if date, err := parseMetadataDate(string(m[1])); err == nil {
+				return date, nil
+			}
+		}
+	}
+	return time.Time{}, errors.New("no date in XMP metadata")
+}
+
+// metadataDateLayouts are the layouts of dates found in video metadata. Dates
+// without a time zone are interpreted as local time.
+var metadataDateLayouts = []string{
+	"2006-01-02T15:04:05-0700",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseMetadataDate parses a date from video metadata, keeping the time zone
+// of the capture if it is recorded.
+func parseMetadataDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range metadataDateLayouts {
+		if date, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+// movieHeaderDate returns the creation time recorded in an mvhd box.
+func movieHeaderDate(mvhd []byte) (time.Time, error) {
+	var seconds uint64
+	switch {
+	case len(mvhd) >= 8 && mvhd[0] == 0:
+		seconds = uint64(binary.BigEndian.Uint32(mvhd[4:]))
+	case len(mvhd) >= 12 && mvhd[0] == 1:
+		seconds = binary.BigEndian.Uint64(mvhd[4:])
+	default:
+		return time.Time{}, errors.New("no movie header")
+	}
+	// Timestamps past the year 2100 are garbage and would overflow.
+	if seconds == 0 || seconds > 1<<32 {
+		return time.Time{}, errors.New("movie header has no valid creation time")
+	}
+	return quickTimeEpoch.Add(time.Duration(seconds) * time.Second).Local(), nil
+}
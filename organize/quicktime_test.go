@@ -0,0 +1,98 @@
+package organize
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// mkbox returns a QuickTime box of the given type holding the concatenation
+// of payloads.
+func mkbox(typ string, payloads ...[]byte) []byte {
+	var payload []byte
+	for _, p := range payloads {
+		payload = append(payload, p...)
+	}
+	b := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(b, uint32(8+len(payload)))
+	copy(b[4:], typ)
+	return append(b, payload...)
+}
+
+func u32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+// mvhd returns a version 0 movie header box with the given creation time.
+func mvhd(created time.Time) []byte {
+	return mkbox("mvhd", u32(0), u32(uint32(created.Sub(quickTimeEpoch)/time.Second)), make([]byte, 92))
+}
+
+func TestQuickTimeDate(t *testing.T) {
+	headerTime := time.Date(2023, 3, 16, 1, 0, 0, 0, time.UTC)
+	ftyp := mkbox("ftyp", []byte("qt  "), u32(0))
+	keys := mkbox("keys", u32(0), u32(2),
+		mkbox("mdta", []byte("com.apple.quicktime.make")),
+		mkbox("mdta", []byte("com.apple.quicktime.creationdate")))
+	ilst := mkbox("ilst",
+		mkbox(string(u32(1)), mkbox("data", u32(1), u32(0), []byte("Apple"))),
+		mkbox(string(u32(2)), mkbox("data", u32(1), u32(0), []byte("2023-03-15T23:22:33+0200"))))
+	meta := mkbox("meta", mkbox("hdlr", make([]byte, 25)), keys, ilst)
+	xmp := mkbox("uuid", xmpUUID, []byte(`<x:xmpmeta><rdf:Description xmp:CreateDate="2022-12-24T18:00:00"/></x:xmpmeta>`))
+
+	tests := []struct {
+		name  string
+		boxes [][]byte
+		want  time.Time
+	}{
+		{"keys.mov", [][]byte{ftyp, mkbox("moov", mvhd(headerTime), meta), xmp, mkbox("mdat")},
+			time.Date(2023, 3, 15, 23, 22, 33, 0, time.FixedZone("", 2*60*60))},
+		{"xmp.mp4", [][]byte{ftyp, mkbox("moov", mvhd(headerTime)), xmp},
+			time.Date(2022, 12, 24, 18, 0, 0, 0, time.Local)},
+		{"header.MP4", [][]byte{ftyp, mkbox("mdat", make([]byte, 100)), mkbox("moov", mvhd(headerTime))},
+			headerTime},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		var contents []byte
+		for _, b := range tt.boxes {
+			contents = append(contents, b...)
+		}
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := quickTimeDate(path)
+		if err != nil {
+			t.Errorf("Expected no error but received: %s (file: %s)", err, tt.name)
+			continue
+		}
+		if !got.Equal(tt.want) || got.Format("2006-01-02") != tt.want.Format("2006-01-02") {
+			t.Errorf("got %s, want %s (file: %s)", got, tt.want, tt.name)
+		}
+	}
+}
+
+func TestQuickTimeDateErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string][]byte{
+		"empty.mov":     nil,
+		"nomoov.mp4":    mkbox("ftyp", []byte("isom")),
+		"zeroed.mov":    mkbox("moov", mkbox("mvhd", make([]byte, 100))),
+		"truncated.mov": mkbox("moov")[:6],
+		"notvideo.jpg":  mkbox("moov"),
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if date, err := quickTimeDate(path); err == nil {
+			t.Errorf("Expected error but received date %s (file: %s)", date, name)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package organize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// rawExtensions lists the (lower case) extensions of camera RAW files whose
+// EXIF metadata is read by rawDate. All but RAF files are TIFF based.
+var rawExtensions = map[string]bool{
+	".cr2": true, ".nef": true, ".nrw": true, ".arw": true, ".dng": true,
+	".pef": true, ".raf": true,
+}
+
+// maxRAWHeaderSize bounds how much of a TIFF based RAW file is read for its
+// metadata. The IFDs holding the dates precede the image data.
+const maxRAWHeaderSize = 4 << 20
+
+// rafMagic starts Fujifilm RAF files, which embed a JPEG preview carrying
+// the EXIF metadata.
+const rafMagic = "FUJIFILMCCD-RAW "
+
+// rawDate reads the capture date of the camera RAW file at path from its EXIF
+// metadata, using the first of tags that is present, as exifDate does for
+// JPEG files.
+func rawDate(path string, tags []string) (time.Time, error) {
+	values, err := rawExifValues(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date, ok := exifTagsDate(values, tags); ok {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("no EXIF date in %q", path)
+}
+
+// rawExifValues returns the ASCII values of the EXIF metadata of the camera
+// RAW file at path, by tag.
+func rawExifValues(path string) (map[uint16]string, error) {
+	if !rawExtensions[strings.ToLower(filepath.Ext(path))] {
+		return nil, fmt.Errorf("%q is not a RAW file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	header, err := io.ReadAll(io.LimitReader(f, maxRAWHeaderSize))
+	if err != nil {
+		return nil, err
+	}
+	tiff := header
+	if bytes.HasPrefix(header, []byte(rafMagic)) {
+		if tiff, err = rafExif(f, header); err != nil {
+			return nil, fmt.Errorf("no EXIF metadata in %q: %v", path, err)
+		}
+	}
+	values, err := exifValues(tiff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EXIF metadata in %q: %v", path, err)
+	}
+	return values, nil
+}
+
+// rafExif returns the TIFF structure of the EXIF segment of the JPEG preview
+// embedded in the RAF file r, whose header is given.
+func rafExif(r io.ReaderAt, header []byte) ([]byte, error) {
+	// The offset and length of the JPEG preview follow the format and
+	// camera identification.
+	if len(header) < 92 {
+		return nil, fmt.Errorf("truncated RAF header")
+	}
+	offset := binary.BigEndian.Uint32(header[84:])
+	length := binary.BigEndian.Uint32(header[88:])
+	segment, ok := jpegExifSegment(io.NewSectionReader(r, int64(offset), int64(length)))
+	if !ok {
+		return nil, fmt.Errorf("no EXIF segment in the JPEG preview")
+	}
+	return segment[len("Exif\x00\x00"):], nil
+}
+
+// pairExtensions lists the (lower case) extensions of the processed images
+// cameras save alongside RAW files, under the same base name.
+var pairExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".heic": true, ".heif": true, ".hif": true,
+}
+
+// datedFile is a file considered for organizing and the result of dating it.
+type datedFile struct {
+	sourceFile
+	date time.Time
+	// wallClock is set if date is the wall clock time of the device, found
+	// in the name without a time zone, rather than a capture time.
+	wallClock bool
+	err       error
+}
+
+// pairRAWFiles makes RAW+JPEG pairs, files in the same directory with the
+// same base name, such as IMG_0001.CR2 and IMG_0001.JPG, share a date, so
+// that they end up in the same directory even if only one of them could be
+// dated. The date of the RAW file wins, as the JPEG may have been edited.
+func pairRAWFiles(files []datedFile) {
+	pairKey := func(path string) string {
+		return strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	raws := make(map[string]int)
+	for i, f := range files {
+		if rawExtensions[strings.ToLower(filepath.Ext(f.path))] {
+			raws[pairKey(f.path)] = i
+		}
+	}
+	if len(raws) == 0 {
+		return
+	}
+	for i := range files {
+		f := &files[i]
+		if !pairExtensions[strings.ToLower(filepath.Ext(f.path))] {
+			continue
+		}
+		j, ok := raws[pairKey(f.path)]
+		if !ok {
+			continue
+		}
+		pairDates(&files[j], f)
+	}
+}
+
+// pairDates gives the files of a pair the same date: that of leader if it
+// could be dated and that of follower otherwise. Dates on the same day are
+// left alone, as they lead to the same directory.
+func pairDates(leader, follower *datedFile) {
+	switch {
+	case leader.err == nil && (follower.err != nil || !sameDay(follower.date, leader.date)):
+		log.Printf("Dating %q like %q", follower.path, leader.path)
+		follower.date, follower.wallClock, follower.err = leader.date, leader.wallClock, nil
+	case leader.err != nil && follower.err == nil:
+		log.Printf("Dating %q like %q", leader.path, follower.path)
+		leader.date, leader.wallClock, leader.err = follower.date, follower.wallClock, nil
+	}
+}
+
+// sameDay reports whether a and b fall on the same calendar day.
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
@@ -0,0 +1,87 @@
+package organize
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRAWDate(t *testing.T) {
+	exif := []tiffEntry{{0x9003, "2023:03:15 14:22:33"}}
+	want := time.Date(2023, 3, 15, 14, 22, 33, 0, time.Local)
+
+	// RAF files start with a header pointing to a JPEG preview.
+	jpeg := exifJPEG(nil, exif)
+	raf := make([]byte, 100)
+	copy(raf, rafMagic)
+	binary.BigEndian.PutUint32(raf[84:], uint32(len(raf)))
+	binary.BigEndian.PutUint32(raf[88:], uint32(len(jpeg)))
+	raf = append(raf, jpeg...)
+
+	tests := []struct {
+		fileName    string
+		contents    []byte
+		errExpected bool
+	}{
+		{"DSC_0001.NEF", exifTIFF(nil, exif), false},
+		{"IMG_0001.dng", exifTIFF(nil, exif), false},
+		{"DSCF0001.RAF", raf, false},
+		{"DSCF0002.RAF", raf[:90], true},
+		{"DSC_0002.NEF", exifTIFF(nil, nil), true},
+		{"DSC_0003.NEF", []byte("not a TIFF file"), true},
+		{"DSC_0004.JPG", exifTIFF(nil, exif), true},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.fileName)
+		if err := os.WriteFile(path, tt.contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := rawDate(path, dateTagOrder(""))
+		if tt.errExpected {
+			if err == nil {
+				t.Errorf("Expected error but received none (file name: %s)", tt.fileName)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("got %s, want %s (file name: %s)", got, want, tt.fileName)
+		}
+	}
+}
+
+func TestPairRAWFiles(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2023, 3, d, 12, 0, 0, 0, time.UTC) }
+	errNoDate := errors.New("no date")
+	file := func(path string) sourceFile { return sourceFile{path: path} }
+	files := []datedFile{
+		{file("a/IMG_0001.CR2"), day(15), false, nil},
+		{file("a/IMG_0001.JPG"), time.Time{}, false, errNoDate}, // Follows the RAW file.
+		{file("a/IMG_0002.CR2"), time.Time{}, false, errNoDate}, // Follows the JPEG.
+		{file("a/IMG_0002.jpg"), day(16), false, nil},
+		{file("a/IMG_0003.NEF"), day(17), false, nil},
+		{file("a/IMG_0003.JPG"), day(20), false, nil}, // Edited; follows the RAW file.
+		{file("b/IMG_0003.JPG"), day(21), false, nil}, // Different directory.
+		{file("a/IMG_0004.MOV"), time.Time{}, false, errNoDate},
+		{file("a/IMG_0004.DNG"), day(18), false, nil},
+	}
+	pairRAWFiles(files)
+
+	want := []time.Time{day(15), day(15), day(16), day(16), day(17), day(17), day(21), {}, day(18)}
+	for i, f := range files {
+		if !f.date.Equal(want[i]) {
+			t.Errorf("got %s, want %s (path: %s)", f.date, want[i], f.path)
+		}
+		if (f.err == nil) != !want[i].IsZero() {
+			t.Errorf("got error %v for %s", f.err, f.path)
+		}
+	}
+}
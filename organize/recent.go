@@ -0,0 +1,70 @@
+package organize
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RecentDirName is the directory, at the root of the organized directory,
+// holding symbolic links to the recently imported files.
+const RecentDirName = "Recent"
+
+// linkRecent adds a symbolic link to the file at destFilePath, which was just
+// moved into the organized directory root, to the Recent directory. Links are
+// relative, so the organized directory can be moved as a whole. They are
+// named after the file, or after its path relative to root if that name is
+// already taken.
+func linkRecent(root, destFilePath string) error {
+	dir := filepath.Join(root, RecentDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	target, err := filepath.Rel(dir, destFilePath)
+	if err != nil {
+		return err
+	}
+	link := filepath.Join(dir, filepath.Base(destFilePath))
+	if existing, err := os.Readlink(link); err == nil && existing != target {
+		rel, err := filepath.Rel(root, destFilePath)
+		if err != nil {
+			return err
+		}
+		link = filepath.Join(dir, strings.ReplaceAll(rel, string(filepath.Separator), "_"))
+	}
+	if existing, err := os.Readlink(link); err == nil && existing == target {
+		return nil
+	}
+	return os.Symlink(target, link)
+}
+
+// pruneRecent removes the links in the Recent directory of root that were
+// created more than days days before now, or whose file is gone. Other files
+// are left alone.
+func pruneRecent(root string, days int, now time.Time) {
+	dir := filepath.Join(root, RecentDirName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := now.AddDate(0, 0, -days)
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		link := filepath.Join(dir, entry.Name())
+		info, err := os.Lstat(link)
+		if err != nil {
+			continue
+		}
+		_, statErr := os.Stat(link)
+		if info.ModTime().After(cutoff) && statErr == nil {
+			continue
+		}
+		if err := os.Remove(link); err != nil {
+			log.Printf("unable to prune %q: %v", link, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLinkRecent(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		filepath.Join(root, "2021-02-22", "IMG_0001.jpg"),
+		filepath.Join(root, "2021-02-23", "IMG_0001.jpg"),
+		filepath.Join(root, "2021-02-23", "IMG_0002.jpg"),
+	}
+	for _, path := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(path), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := linkRecent(root, path); err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+	}
+	// Linking a file twice is harmless.
+	if err := linkRecent(root, files[0]); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+
+	want := map[string]string{
+		"IMG_0001.jpg":            files[0],
+		"2021-02-23_IMG_0001.jpg": files[1],
+		"IMG_0002.jpg":            files[2],
+	}
+	entries, err := os.ReadDir(filepath.Join(root, RecentDirName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("got %d links, want %d", len(entries), len(want))
+	}
+	for name, target := range want {
+		contents, err := os.ReadFile(filepath.Join(root, RecentDirName, name))
+		if err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+			continue
+		}
+		if string(contents) != target {
+			t.Errorf("got %s, want %s (link: %s)", contents, target, name)
+		}
+	}
+
+	// Links to removed files are pruned right away, the others once they
+	// are old enough.
+	if err := os.Remove(files[2]); err != nil {
+		t.Fatal(err)
+	}
+	pruneRecent(root, 7, time.Now())
+	if entries, _ := os.ReadDir(filepath.Join(root, RecentDirName)); len(entries) != 2 {
+		t.Errorf("got %d links after pruning dangling links, want 2", len(entries))
+	}
+	pruneRecent(root, 7, time.Now().AddDate(0, 0, 8))
+	if entries, _ := os.ReadDir(filepath.Join(root, RecentDirName)); len(entries) != 0 {
+		t.Errorf("got %d links after pruning old links, want 0", len(entries))
+	}
+}
@@ -0,0 +1,179 @@
+package organize
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Relocate moves the file at filePath, within the organized tree at root,
+// into the directory of date, along with its sidecar files, to correct a file
+// filed under the wrong date. The records kept of the file follow it: the
+// move is appended to the journal, if any, and the playlists and Recent links
+// listing the file are updated. Views are refreshed by building them again.
+// Files from outside of root are filed into it like newly organized files.
+// It returns the new path of the file.
+func (o *Organizer) Relocate(root, filePath string, date time.Time) (string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	if filePath, err = filepath.Abs(filePath); err != nil {
+		return "", err
+	}
+	var class string
+	rel, inRoot := relativePath(root, filePath)
+	if inRoot {
+		class, _ = splitClassDir(path.Dir(rel))
+	}
+	folder, err := folderPath(MediaFile{filePath, date}, o.opts)
+	if err != nil {
+		return "", err
+	}
+	srcDir := filepath.Dir(filePath)
+	destDir := filepath.Join(root, filepath.FromSlash(class), folder)
+	destFilePath := filepath.Join(destDir, filepath.Base(filePath))
+	if srcDir == destDir {
+		return filePath, nil
+	}
+
+	files, err := dirFiles(srcDir)
+	if err != nil {
+		return "", err
+	}
+	paths := append([]string{filePath}, findSidecars(files)[filePath]...)
+	for _, p := range paths {
+		if _, err := os.Lstat(filepath.Join(destDir, filepath.Base(p))); err == nil {
+			return "", fmt.Errorf("%q already exists in %q", filepath.Base(p), destDir)
+		}
+	}
+	if err := os.MkdirAll(destDir, 0700); err != nil {
+		return "", err
+	}
+	for _, p := range paths {
+		dest := filepath.Join(destDir, filepath.Base(p))
+		if !moveFile(p, dest, "", o.opts) {
+			return "", fmt.Errorf("unable to move %q", p)
+		}
+		if o.opts.journal != "" {
+			if err := recordMove(p, dest, o.opts); err != nil {
+				return "", fmt.Errorf("moved %q but unable to record it in the journal: %v", p, err)
+			}
+		}
+	}
+	if !inRoot {
+		if o.opts.playlists {
+			return destFilePath, addToPlaylist(root, date, destFilePath)
+		}
+		return destFilePath, nil
+	}
+	// Removing a directory that isn't empty fails harmlessly.
+	os.Remove(srcDir)
+
+	listed, err := removeFromPlaylists(root, filePath)
+	if err != nil {
+		return destFilePath, err
+	}
+	if listed || o.opts.playlists {
+		if err := addToPlaylist(root, date, destFilePath); err != nil {
+			return destFilePath, err
+		}
+	}
+	return destFilePath, relinkRecent(root, filePath, destFilePath)
+}
+
+// dirFiles lists the files of the directory dir.
+func dirFiles(dir string) ([]sourceFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []sourceFile
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, sourceFile{filepath.Join(dir, entry.Name()), entry})
+		}
+	}
+	return files, nil
+}
+
+// removeFromPlaylists removes the file at filePath from the playlists at the
+// root of the organized directory, reporting whether any listed it.
+func removeFromPlaylists(root, filePath string) (bool, error) {
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil {
+		return false, err
+	}
+	entry := filepath.ToSlash(rel)
+	playlists, err := filepath.Glob(filepath.Join(root, "*.m3u"))
+	if err != nil {
+		return false, err
+	}
+	listed := false
+	for _, playlist := range playlists {
+		f, err := os.Open(playlist)
+		if err != nil {
+			return listed, err
+		}
+		var lines []string
+		found := false
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if scanner.Text() == entry {
+				found = true
+				continue
+			}
+			lines = append(lines, scanner.Text())
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return listed, err
+		}
+		if !found {
+			continue
+		}
+		listed = true
+		if err := os.WriteFile(playlist, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+			return listed, err
+		}
+	}
+	return listed, nil
+}
+
+// relinkRecent points the links of the Recent directory of root to the file
+// at oldPath, if any, to newPath instead.
+func relinkRecent(root, oldPath, newPath string) error {
+	dir := filepath.Join(root, RecentDirName)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	oldTarget, err := filepath.Rel(dir, oldPath)
+	if err != nil {
+		return err
+	}
+	newTarget, err := filepath.Rel(dir, newPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		link := filepath.Join(dir, entry.Name())
+		if target, err := os.Readlink(link); err != nil || target != oldTarget {
+			continue
+		}
+		if err := os.Remove(link); err != nil {
+			return err
+		}
+		if err := os.Symlink(newTarget, link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRelocate(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"IMG_0001.jpg", "IMG_0001.xmp", "IMG_0002.jpg"} {
+		path := filepath.Join(root, "2021-02-22", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := addToPlaylist(root, time.Date(2021, 2, 22, 0, 0, 0, 0, time.Local), filepath.Join(root, "2021-02-22", "IMG_0001.jpg")); err != nil {
+		t.Fatal(err)
+	}
+	if err := linkRecent(root, filepath.Join(root, "2021-02-22", "IMG_0001.jpg")); err != nil {
+		t.Fatal(err)
+	}
+
+	journal := filepath.Join(t.TempDir(), "journal.jsonl")
+	o, err := New(WithExternalTools(false), WithJournal(journal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2021, 2, 21, 12, 0, 0, 0, time.Local)
+	got, err := o.Relocate(root, filepath.Join(root, "2021-02-22", "IMG_0001.jpg"), date)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if want := filepath.Join(root, "2021-02-21", "IMG_0001.jpg"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	for _, path := range []string{"2021-02-21/IMG_0001.jpg", "2021-02-21/IMG_0001.xmp", "2021-02-22/IMG_0002.jpg"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+
+	playlist, err := os.ReadFile(playlistPath(root, date))
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if got, want := string(playlist), "#EXTM3U\n2021-02-21/IMG_0001.jpg\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	old, err := os.ReadFile(filepath.Join(root, "2021-02-22.m3u"))
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if got, want := string(old), "#EXTM3U\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(root, RecentDirName, "IMG_0001.jpg")); err != nil {
+		t.Errorf("Expected no error but received: %s", err)
+	}
+	entries, err := ReadJournal(journal)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if got, want := len(entries), 2; got != want {
+		t.Errorf("got %d journal entries, want %d", got, want)
+	}
+
+	// The second file may not take the name of an existing file.
+	if err := os.WriteFile(filepath.Join(root, "2021-02-21", "IMG_0002.jpg"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Relocate(root, filepath.Join(root, "2021-02-22", "IMG_0002.jpg"), date); err == nil {
+		t.Errorf("Expected error but received none")
+	}
+}
+
+func TestRelocateIntoRoot(t *testing.T) {
+	root, incoming := t.TempDir(), t.TempDir()
+	src := filepath.Join(incoming, "scan_0001.jpg")
+	if err := os.WriteFile(src, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	journal := filepath.Join(t.TempDir(), "journal.jsonl")
+	o, err := New(WithExternalTools(false), WithLayout("2006/2006-01-02"), WithJournal(journal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := o.Relocate(root, src, time.Date(1998, 12, 25, 12, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if want := filepath.Join(root, "1998", "1998-12-25", "scan_0001.jpg"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if _, err := os.Stat(incoming); err != nil {
+		t.Errorf("Expected no error but received: %s", err)
+	}
+	entries, err := ReadJournal(journal)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if len(entries) != 1 || entries[0].Src != src || entries[0].Dst != got {
+		t.Errorf("got journal entries %+v, want the move of %q to %q", entries, src, got)
+	}
+}
@@ -0,0 +1,102 @@
+package organize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TrashDirName is the hidden directory, at the root of the organized
+// directory, that files removed with Remove are moved into if trashed.
+const TrashDirName = ".trash"
+
+// Remove removes the file at filePath, within the organized tree at root,
+// along with its sidecar files, and records each removal with the file's
+// hash and the reason in the journal, which is required, so the history of
+// the archive stays auditable. If trash is set, the files are moved into the
+// trash directory at the same path, from where Undo can restore them, rather
+// than deleted. The playlist entries and Recent links of the file are
+// removed.
+func (o *Organizer) Remove(root, filePath, reason string, trash bool) error {
+	if o.opts.journal == "" {
+		return errors.New("removing files requires a journal to record them in")
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	if filePath, err = filepath.Abs(filePath); err != nil {
+		return err
+	}
+	rel, ok := relativePath(root, filePath)
+	if !ok {
+		return fmt.Errorf("%q is not in %q", filePath, root)
+	}
+	files, err := dirFiles(filepath.Dir(filePath))
+	if err != nil {
+		return err
+	}
+	trashDir := filepath.Join(root, TrashDirName, filepath.Dir(filepath.FromSlash(rel)))
+	paths := append([]string{filePath}, findSidecars(files)[filePath]...)
+	if trash {
+		for _, p := range paths {
+			if _, err := os.Lstat(filepath.Join(trashDir, filepath.Base(p))); err == nil {
+				return fmt.Errorf("%q is already in the trash", filepath.Base(p))
+			}
+		}
+		if err := os.MkdirAll(trashDir, 0700); err != nil {
+			return err
+		}
+	}
+	for _, p := range paths {
+		hash, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		e := JournalEntry{Src: p, Time: time.Now(), Hash: hash, Op: RemoveDelete, Reason: reason}
+		if trash {
+			e.Op, e.Dst = RemoveTrash, filepath.Join(trashDir, filepath.Base(p))
+			err = os.Rename(p, e.Dst)
+		} else {
+			err = os.Remove(p)
+		}
+		if err != nil {
+			return err
+		}
+		if err := appendJournal(e, o.opts); err != nil {
+			return fmt.Errorf("removed %q but unable to record it in the journal: %v", p, err)
+		}
+	}
+	if _, err := removeFromPlaylists(root, filePath); err != nil {
+		return err
+	}
+	return unlinkRecent(root, filePath)
+}
+
+// unlinkRecent removes the links of the Recent directory of root to the file
+// at filePath, if any.
+func unlinkRecent(root, filePath string) error {
+	dir := filepath.Join(root, RecentDirName)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	target, err := filepath.Rel(dir, filePath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		link := filepath.Join(dir, entry.Name())
+		if existing, err := os.Readlink(link); err == nil && existing == target {
+			if err := os.Remove(link); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
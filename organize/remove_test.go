@@ -0,0 +1,70 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemove(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"IMG_0001.jpg", "IMG_0001.xmp", "IMG_0002.jpg"} {
+		path := filepath.Join(root, "2021-02-22", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	journal := filepath.Join(t.TempDir(), "journal.jsonl")
+	o, err := New(WithExternalTools(false), WithJournal(journal))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Remove(root, filepath.Join(root, "2021-02-22", "IMG_0001.jpg"), "blurry", true); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if err := o.Remove(root, filepath.Join(root, "2021-02-22", "IMG_0002.jpg"), "duplicate", false); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	for _, path := range []string{".trash/2021-02-22/IMG_0001.jpg", ".trash/2021-02-22/IMG_0001.xmp"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+	entries, err := ReadJournal(journal)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	want := []string{RemoveTrash, RemoveTrash, RemoveDelete}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d journal entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if e.Op != want[i] || e.Hash == "" || e.Reason == "" {
+			t.Errorf("got %+v, want a %s entry with a hash and reason", e, want[i])
+		}
+	}
+
+	// Trashed files are restored, deleted ones can't be.
+	n, err := Undo(journal, false)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if n != 2 {
+		t.Errorf("got %d files restored, want 2", n)
+	}
+	if _, err := os.Stat(filepath.Join(root, "2021-02-22", "IMG_0001.jpg")); err != nil {
+		t.Errorf("Expected no error but received: %s", err)
+	}
+
+	o, err = New(WithExternalTools(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Remove(root, filepath.Join(root, "2021-02-22", "IMG_0001.jpg"), "", false); err == nil {
+		t.Errorf("Expected error but received none")
+	}
+}
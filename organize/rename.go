@@ -0,0 +1,199 @@
+package organize
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// maxRenameSeq bounds the sequence numbers tried for a rename template.
+const maxRenameSeq = 100000
+
+// RenameData is the data available to rename templates.
+type RenameData struct {
+	// Date is the date of the file, formatted as YYYY-MM-DD.
+	Date string
+	// Time is the date of the file, for custom formatting, e.g.
+	// {{.Time.Format "20060102"}}.
+	Time time.Time
+	// Name is the original name of the file, without its extension.
+	Name string
+	// Ext is the extension of the original name, including the dot.
+	Ext string
+	// Seq is the smallest number, starting at 1, that makes the new name
+	// unique in the destination directory. Files are numbered in order of
+	// capture, so that the files of a burst taken within the same second keep
+	// their order.
+	Seq int
+}
+
+// SetRename sets the template of the names that files dated by m are given
+// when they are moved, such as "Screenshot_{{.Date}}_{{.Seq}}{{.Ext}}". The
+// template is a text/template executed with a RenameData. An empty tmpl keeps
+// the original names.
+func (m *MediaFileMatcher) SetRename(tmpl string) error {
+	if tmpl == "" {
+		m.rename = nil
+		return nil
+	}
+	t, err := template.New(m.name).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("invalid rename template %q: %v", tmpl, err)
+	}
+	sample := RenameData{"2021-02-22", time.Date(2021, 2, 22, 21, 35, 25, 0, time.UTC), "IMG_0001", ".jpg", 1}
+	if _, err := renderName(t, sample); err != nil {
+		return fmt.Errorf("invalid rename template %q: %v", tmpl, err)
+	}
+	m.rename = t
+	return nil
+}
+
+// renderName executes the rename template t with data, checking that the
+// result is a plain file name.
+func renderName(t *template.Template, data RenameData) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	name := b.String()
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("%q is not a valid file name", name)
+	}
+	return name, nil
+}
+
+// isRenamed reports whether the file at path is given a new name by the
+// rename template of the matcher dating its name.
+func isRenamed(path string, opts options) bool {
+	m := nameMatcher(opts.matchers, filepath.Base(path))
+	return m != nil && m.rename != nil
+}
+
+// renameMoves sets the name of the moves whose file is renamed by a template,
+// claiming it. The files are named in order of their date and, within a burst
+// of files dated the same second, of the file counter in their original names,
+// so that sequence numbers follow the order of capture even when the dates
+// lack sub-second precision. Moves that cannot be renamed keep their name.
+func renameMoves(moves []PlannedMove, claimed map[string]bool, opts options) {
+	var order []int
+	for i, m := range moves {
+		if isRenamed(m.Src, opts) {
+			order = append(order, i)
+		}
+	}
+	counters := burstCounters(moves, order)
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		si, sj := moves[i].Date.Truncate(time.Second), moves[j].Date.Truncate(time.Second)
+		if !si.Equal(sj) {
+			return si.Before(sj)
+		}
+		return counters[i] < counters[j]
+	})
+	for _, i := range order {
+		m := &moves[i]
+		name, err := renamedFileName(m.Src, m.DestDir, m.Date, claimed, opts)
+		if err != nil {
+			log.Print(err)
+		}
+		m.Name = name
+		if name == "" {
+			name = filepath.Base(m.Src)
+		}
+		claimed[filepath.Join(m.DestDir, name)] = true
+	}
+}
+
+// fileCounterRegexp matches the file counter that cameras put in file names,
+// taken to be the last run of digits of the name without its extension.
+var fileCounterRegexp = regexp.MustCompile(`(\d+)\D*$`)
+
+// burstCounters returns, for the moves at the given indices, the file counter
+// in their original names, keyed by index. Names without a counter get -1.
+// Within a burst of files dated the same second, counters that have rolled
+// over (e.g. IMG_9999.JPG followed by IMG_0001.JPG) are counted on from the
+// largest.
+func burstCounters(moves []PlannedMove, indices []int) map[int]int {
+	counters := make(map[int]int)
+	bursts := make(map[time.Time][]int)
+	digits := make(map[int]int)
+	for _, i := range indices {
+		counters[i] = -1
+		name := filepath.Base(moves[i].Src)
+		m := fileCounterRegexp.FindStringSubmatch(strings.TrimSuffix(name, filepath.Ext(name)))
+		if m == nil || len(m[1]) > 9 {
+			continue
+		}
+		counters[i], _ = strconv.Atoi(m[1])
+		digits[i] = len(m[1])
+		second := moves[i].Date.Truncate(time.Second).UTC()
+		bursts[second] = append(bursts[second], i)
+	}
+	for _, burst := range bursts {
+		min, max := -1, -1
+		for _, i := range burst {
+			if min < 0 || counters[i] < min {
+				min = counters[i]
+			}
+			if counters[i] > max {
+				max = counters[i]
+			}
+		}
+		for _, i := range burst {
+			// A burst spanning more than half the counter's range has rolled
+			// over: its lowest counters come after its highest.
+			limit := 1
+			for d := 0; d < digits[i]; d++ {
+				limit *= 10
+			}
+			if max-min > limit/2 && counters[i] < limit/2 {
+				counters[i] += limit
+			}
+		}
+	}
+	return counters
+}
+
+// renamedFileName returns the name that the file at srcPath, of the given date
+// and moving into destDir, is given by the rename template of the matcher
+// dating its name, or an empty string if there is none. The sequence number is
+// chosen so that the name is neither taken in destDir nor claimed, a set of
+// paths already planned as destinations.
+func renamedFileName(srcPath, destDir string, date time.Time, claimed map[string]bool, opts options) (string, error) {
+	fileName := filepath.Base(srcPath)
+	m := nameMatcher(opts.matchers, fileName)
+	if m == nil || m.rename == nil {
+		return "", nil
+	}
+	ext := filepath.Ext(fileName)
+	data := RenameData{date.Format("2006-01-02"), date, strings.TrimSuffix(fileName, ext), ext, 1}
+	first := ""
+	for ; data.Seq <= maxRenameSeq; data.Seq++ {
+		name, err := renderName(m.rename, data)
+		if err != nil {
+			return "", fmt.Errorf("unable to rename %q: %v", srcPath, err)
+		}
+		// Templates without a sequence number yield the same name every time;
+		// moving will refuse to overwrite an existing file.
+		if name == first {
+			return name, nil
+		}
+		if first == "" {
+			first = name
+		}
+		path := filepath.Join(destDir, name)
+		if _, err := os.Lstat(path); err == nil || claimed[path] {
+			continue
+		}
+		return name, nil
+	}
+	return "", fmt.Errorf("no free sequence number to rename %q", srcPath)
+}
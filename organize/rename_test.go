@@ -0,0 +1,112 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSetRenameInvalid(t *testing.T) {
+	m, err := NewPatternMatcher(`^Screenshot_(\d{8})`, "20060102")
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	for _, tmpl := range []string{
+		"{{.Date",
+		"{{.Missing}}",
+		"{{.Date}}/{{.Name}}{{.Ext}}",
+		"{{if false}}x{{end}}",
+	} {
+		if err := m.SetRename(tmpl); err == nil {
+			t.Errorf("Expected error but received none (template: %q)", tmpl)
+		}
+	}
+}
+
+func TestPlanRename(t *testing.T) {
+	m, err := NewPatternMatcher(`^Screenshot_(\d{8})`, "20060102")
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if err := m.SetRename(`Screenshot_{{.Date}}_{{.Seq}}{{.Ext}}`); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{
+		"Screenshot_20210222-213525.png",
+		"Screenshot_20210222-213600.png",
+		"IMG_20210222_213525.jpg",
+		"2021-02-22/Screenshot_2021-02-22_1.png",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := options{matchers: append([]*MediaFileMatcher{m}, mediaMatchers...)}
+	p, err := planOrganize(dir, opts)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	var got []string
+	for _, move := range p.Moves {
+		got = append(got, move.Name)
+	}
+	sort.Strings(got)
+	want := []string{"", "Screenshot_2021-02-22_2.png", "Screenshot_2021-02-22_3.png"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, want %q", got, want)
+			break
+		}
+	}
+
+	executePlan(dir, p, opts)
+	for _, name := range want[1:] {
+		if _, err := os.Stat(filepath.Join(dir, "2021-02-22", name)); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+}
+
+func TestRenameMovesBurst(t *testing.T) {
+	m, err := NewPatternMatcher(`^SHOT_(\d{8})_\d+`, "20060102")
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if err := m.SetRename(`{{.Time.Format "20060102_150405"}}_{{.Seq}}{{.Ext}}`); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+
+	dir := t.TempDir()
+	burst := time.Date(2021, 2, 22, 21, 35, 25, 0, time.UTC)
+	moves := []PlannedMove{
+		{Src: "SHOT_20210222_0001.jpg", DestDir: dir, Date: burst.Add(300 * time.Millisecond)},
+		{Src: "SHOT_20210222_9998.jpg", DestDir: dir, Date: burst},
+		{Src: "SHOT_20210222_0002.jpg", DestDir: dir, Date: burst.Add(-time.Second)},
+		{Src: "SHOT_20210222_9999.jpg", DestDir: dir, Date: burst},
+	}
+	renameMoves(moves, make(map[string]bool), options{matchers: []*MediaFileMatcher{m}})
+
+	want := []string{
+		"20210222_213525_3.jpg",
+		"20210222_213525_1.jpg",
+		"20210222_213524_1.jpg",
+		"20210222_213525_2.jpg",
+	}
+	for i, move := range moves {
+		if move.Name != want[i] {
+			t.Errorf("got %s, want %s (file: %s)", move.Name, want[i], move.Src)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package organize
+
+import "fmt"
+
+// FileStatus is the outcome of a run for a file.
+type FileStatus string
+
+const (
+	// StatusMoved is a file moved into the organized tree, or that would be in
+	// a dry run.
+	StatusMoved FileStatus = "moved"
+	// StatusSkipped is a file deliberately left in place, such as a duplicate
+	// of an archived file or a file left for the next run.
+	StatusSkipped FileStatus = "skipped"
+	// StatusRemoved is a file removed as a duplicate of an archived file.
+	StatusRemoved FileStatus = "removed"
+	// StatusUnmatched is a file for which no date could be determined.
+	StatusUnmatched FileStatus = "unmatched"
+	// StatusConflict is a file not moved because a different file already
+	// has its name at the destination.
+	StatusConflict FileStatus = "conflict"
+	// StatusError is a file that could not be moved because of an error.
+	StatusError FileStatus = "error"
+)
+
+// FileResult is what a run did with a file.
+type FileResult struct {
+	Path   string     `json:"path"`
+	Status FileStatus `json:"status"`
+	// Dest is the path the file was moved to or, for conflicts and
+	// duplicates, the existing file in the way.
+	Dest string `json:"dest,omitempty"`
+	// Detail explains the status, e.g. the error or why no date was found.
+	Detail string `json:"detail,omitempty"`
+	// Date is the capture time the file was filed by, as read by the folder
+	// date policy: with its time zone, or without one for a date found in
+	// the name.
+	Date string `json:"date,omitempty"`
+}
+
+// Report records the outcome of a run for each file it considered, in the
+// order they were handled. A file may appear more than once, e.g. when it
+// could not be dated and was then moved into the quarantine.
+type Report struct {
+	Dir        string           `json:"dir"`
+	DryRun     bool             `json:"dry_run"`
+	FolderDate FolderDatePolicy `json:"folder_date"`
+	Files      []FileResult     `json:"files"`
+	Skipped    []SkippedDir     `json:"skipped_dirs,omitempty"`
+}
+
+func (r *Report) add(path string, status FileStatus, dest, detail string) {
+	if r != nil {
+		runMu.Lock()
+		r.Files = append(r.Files, FileResult{path, status, dest, detail, ""})
+		runMu.Unlock()
+	}
+}
+
+func (r *Report) addf(path string, status FileStatus, dest, format string, args ...interface{}) {
+	r.add(path, status, dest, fmt.Sprintf(format, args...))
+}
+
+// addDates sets the dates of the files of the report that moves concern.
+func (r *Report) addDates(moves []PlannedMove) {
+	dates := make(map[string]string)
+	for _, m := range moves {
+		dates[m.Src] = formatFolderDate(m.Date, m.WallClock)
+	}
+	for i := range r.Files {
+		r.Files[i].Date = dates[r.Files[i].Path]
+	}
+}
+
+// Counts returns the number of files of the report by status.
+func (r Report) Counts() map[FileStatus]int {
+	counts := make(map[FileStatus]int)
+	for _, f := range r.Files {
+		counts[f.Status]++
+	}
+	return counts
+}
@@ -0,0 +1,59 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrganizeReport(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"IMG_20210222_213525.jpg", "IMG_20210223_080000.jpg", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A different file already has the name of the second one.
+	taken := filepath.Join(dir, "2021-02-23", "IMG_20210223_080000.jpg")
+	if err := os.MkdirAll(filepath.Dir(taken), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(taken, []byte("different"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	o, err := New(WithExternalTools(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Organize(dir); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	report := o.Report()
+	want := map[string]FileStatus{
+		"IMG_20210222_213525.jpg": StatusMoved,
+		"IMG_20210223_080000.jpg": StatusConflict,
+		"notes.txt":               StatusUnmatched,
+	}
+	if len(report.Files) != len(want) {
+		t.Fatalf("got %d files, want %d: %+v", len(report.Files), len(want), report.Files)
+	}
+	wantDates := map[string]string{
+		"IMG_20210222_213525.jpg": "2021-02-22T00:00:00",
+		"IMG_20210223_080000.jpg": "2021-02-23T00:00:00",
+	}
+	for _, f := range report.Files {
+		if got := f.Status; got != want[filepath.Base(f.Path)] {
+			t.Errorf("got %s, want %s (file: %s)", got, want[filepath.Base(f.Path)], f.Path)
+		}
+		if got := f.Date; got != wantDates[filepath.Base(f.Path)] {
+			t.Errorf("got date %q, want %q (file: %s)", got, wantDates[filepath.Base(f.Path)], f.Path)
+		}
+	}
+	if got, want := report.FolderDate, DefaultFolderDate; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := report.Counts()[StatusMoved], 1; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,217 @@
+package organize
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// dateDirRegexp matches the names of the dated directories files are
+// organized into.
+var dateDirRegexp = regexp.MustCompile(`^\d{4}-\d\d-\d\d$`)
+
+// IsDateDirName reports whether name is the name of a dated directory, as
+// created when organizing.
+func IsDateDirName(name string) bool {
+	return dateDirRegexp.MatchString(name)
+}
+
+// sourceFile is a file considered for organizing.
+type sourceFile struct {
+	path  string
+	entry fs.DirEntry
+}
+
+// sourceFiles lists the files to consider when organizing dirName: those at
+// its top level or, if opts.recursive is set, those anywhere below it except
+// in the destination tree. It also returns the directories it skipped.
+func sourceFiles(dirName string, opts options) ([]sourceFile, []SkippedDir, error) {
+	var exclude string
+	if opts.dest != "" {
+		if rel, ok := relativePath(dirName, opts.dest); ok {
+			exclude = rel
+		}
+	}
+	files, skipped, err := scanFS(os.DirFS(dirName), opts, exclude)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read %q: %v", dirName, err)
+	}
+	for i := range files {
+		files[i].path = filepath.Join(dirName, filepath.FromSlash(files[i].path))
+	}
+	for i := range skipped {
+		skipped[i].Path = filepath.Join(dirName, filepath.FromSlash(skipped[i].Path))
+	}
+	return files, skipped, nil
+}
+
+// relativePath returns the slash separated path of path relative to the
+// directory dir, if path is below dir.
+func relativePath(dir, path string) (string, bool) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// scanFS lists the files of fsys to consider for organizing, as described for
+// sourceFiles and selected by the include and exclude patterns of opts, by
+// their slash separated path within fsys, and the directories it skipped.
+// The directory at the path exclude, if not empty, is skipped.
+// Only directory entries are read; files are not stat'ed. In recursive mode,
+// the directories are read by as many workers as files are processed at a
+// time, and listed in the order of a sequential walk.
+func scanFS(fsys fs.FS, opts options, exclude string) ([]sourceFile, []SkippedDir, error) {
+	var (
+		files   []sourceFile
+		skipped []SkippedDir
+	)
+	if !opts.recursive {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && !filtered(entry.Name(), opts) {
+				files = append(files, sourceFile{entry.Name(), entry})
+			}
+		}
+		return files, nil, nil
+	}
+	reason := func(dir string) string {
+		if dir == exclude {
+			return "destination tree"
+		}
+		return skipReason(dir, opts)
+	}
+	listings := readTree(fsys, parallelism(opts), func(dir string) bool { return reason(dir) == "" })
+	if err := listings["."].err; err != nil {
+		return nil, nil, err
+	}
+	var walk func(dir string)
+	walk = func(dir string) {
+		listing := listings[dir]
+		if listing.err != nil {
+			skipped = append(skipped, SkippedDir{dir, fmt.Sprintf("unreadable: %v", listing.err)})
+		}
+		for _, entry := range listing.entries {
+			p := path.Join(dir, entry.Name())
+			if !entry.IsDir() {
+				if !filtered(p, opts) {
+					files = append(files, sourceFile{p, entry})
+				}
+				continue
+			}
+			if reason := reason(p); reason != "" {
+				skipped = append(skipped, SkippedDir{p, reason})
+				continue
+			}
+			walk(p)
+		}
+	}
+	walk(".")
+	return files, skipped, nil
+}
+
+// dirListing holds the entries of a directory, or the error reading it.
+type dirListing struct {
+	entries []fs.DirEntry
+	err     error
+}
+
+// readTree reads the directory "." of fsys and, recursively, its
+// subdirectories that descend accepts, from up to workers goroutines, so that
+// large trees on network shares are not read one directory at a time. It
+// returns the listings by slash separated path. The directories waiting to be
+// read are taken deepest first, which keeps their number down.
+func readTree(fsys fs.FS, workers int, descend func(dir string) bool) map[string]dirListing {
+	var (
+		mu       sync.Mutex
+		ready    = sync.NewCond(&mu)
+		listings = make(map[string]dirListing)
+		queue    = []string{"."}
+		// pending counts the directories queued or being read.
+		pending = 1
+		wg      sync.WaitGroup
+	)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			defer mu.Unlock()
+			for {
+				for len(queue) == 0 && pending > 0 {
+					ready.Wait()
+				}
+				if pending == 0 {
+					return
+				}
+				dir := queue[len(queue)-1]
+				queue = queue[:len(queue)-1]
+				mu.Unlock()
+				entries, err := fs.ReadDir(fsys, dir)
+				mu.Lock()
+				listings[dir] = dirListing{entries, err}
+				for _, entry := range entries {
+					if p := path.Join(dir, entry.Name()); entry.IsDir() && descend(p) {
+						queue = append(queue, p)
+						pending++
+					}
+				}
+				pending--
+				ready.Broadcast()
+			}
+		}()
+	}
+	wg.Wait()
+	return listings
+}
+
+// skipReason returns why a recursive scan should skip dir, a slash separated
+// path relative to the directory being organized, or an empty string if it
+// should not. Hidden directories (including the previews), the alternate
+// views and the links to recent files are skipped, as are AVCHD structures,
+// which are organized separately, and, if opts.skipDatedDirs is set, dated
+// directories.
+func skipReason(dir string, opts options) string {
+	name := path.Base(dir)
+	if strings.HasPrefix(name, ".") {
+		return "hidden"
+	}
+	if dir == ViewsDirName {
+		return "alternate views"
+	}
+	if dir == RecentDirName {
+		return "recently imported files"
+	}
+	if opts.quarantine != "" && dir == opts.quarantine {
+		return "quarantined files"
+	}
+	if opts.unmatchedDir != "" && dir == opts.unmatchedDir {
+		return "unmatched files"
+	}
+	if opts.skipDatedDirs && IsDateDirName(name) {
+		return "dated directory, presumably organized already"
+	}
+	for _, avchdRoot := range avchdRoots {
+		if dir == filepath.ToSlash(avchdRoot) {
+			return "AVCHD structure, organized separately"
+		}
+	}
+	return ""
+}
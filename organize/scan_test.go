@@ -0,0 +1,211 @@
+package organize
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"IMG_20210222_213525.jpg":                  {},
+		"DCIM/Camera/IMG_20210223_101010.jpg":      {},
+		"DCIM/100GOPRO/GOPR0001.MP4":               {},
+		"2021-01-01/IMG_20210101_000000.jpg":       {},
+		"DCIM/.thumbnails/IMG_20210223_101010.jpg": {},
+		"PRIVATE/AVCHD/BDMV/STREAM/00001.MTS":      {},
+	}
+
+	tests := []struct {
+		opts options
+		want []string
+	}{
+		{options{}, []string{"IMG_20210222_213525.jpg"}},
+		{options{recursive: true, skipDatedDirs: true}, []string{
+			"DCIM/100GOPRO/GOPR0001.MP4",
+			"DCIM/Camera/IMG_20210223_101010.jpg",
+			"IMG_20210222_213525.jpg",
+		}},
+		{options{recursive: true}, []string{
+			"2021-01-01/IMG_20210101_000000.jpg",
+			"DCIM/100GOPRO/GOPR0001.MP4",
+			"DCIM/Camera/IMG_20210223_101010.jpg",
+			"IMG_20210222_213525.jpg",
+		}},
+	}
+
+	for _, tt := range tests {
+		files, _, err := scanFS(fsys, tt.opts, "")
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		var got []string
+		for _, f := range files {
+			got = append(got, f.path)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got %q, want %q (recursive %v, skip dated %v)", got, tt.want, tt.opts.recursive, tt.opts.skipDatedDirs)
+		}
+	}
+}
+
+func TestScanFSFilters(t *testing.T) {
+	fsys := fstest.MapFS{
+		"IMG_20210222_213525.jpg":             {},
+		"IMG_20210222_213526.jpg.tmp":         {},
+		".trashed-1613000000-IMG_0001.jpg":    {},
+		"VID_20210222_213525.mp4":             {},
+		"DCIM/Camera/IMG_20210223_101010.jpg": {},
+		"DCIM/Camera/thumb_0001.jpg":          {},
+	}
+
+	tests := []struct {
+		include, exclude []string
+		want             []string
+	}{
+		{nil, []string{"*.tmp", ".trashed-*"}, []string{
+			"DCIM/Camera/IMG_20210223_101010.jpg",
+			"DCIM/Camera/thumb_0001.jpg",
+			"IMG_20210222_213525.jpg",
+			"VID_20210222_213525.mp4",
+		}},
+		{[]string{"*.jpg"}, []string{"thumb_*"}, []string{
+			".trashed-1613000000-IMG_0001.jpg",
+			"DCIM/Camera/IMG_20210223_101010.jpg",
+			"IMG_20210222_213525.jpg",
+		}},
+		{[]string{"DCIM/*/*"}, nil, []string{
+			"DCIM/Camera/IMG_20210223_101010.jpg",
+			"DCIM/Camera/thumb_0001.jpg",
+		}},
+	}
+
+	for _, tt := range tests {
+		files, _, err := scanFS(fsys, options{recursive: true, include: tt.include, exclude: tt.exclude}, "")
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		var got []string
+		for _, f := range files {
+			got = append(got, f.path)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got %q, want %q (include %q, exclude %q)", got, tt.want, tt.include, tt.exclude)
+		}
+	}
+}
+
+func TestNewInvalidGlob(t *testing.T) {
+	if _, err := New(WithExclude("[")); err == nil {
+		t.Errorf("Expected error but received none")
+	}
+}
+
+func TestScanFSSkipped(t *testing.T) {
+	fsys := fstest.MapFS{
+		"DCIM/Camera/IMG_20210223_101010.jpg":        {},
+		"2021-01-01/IMG_20210101_000000.jpg":         {},
+		"DCIM/.thumbnails/IMG_20210223_101010.jpg":   {},
+		"PRIVATE/AVCHD/BDMV/STREAM/00001.MTS":        {},
+		"library/2021-02-22/IMG_20210222_213525.jpg": {},
+		// Left over from a run with recent links enabled.
+		"Recent/IMG_20210222_213525.jpg": {},
+	}
+
+	_, got, err := scanFS(fsys, options{recursive: true, skipDatedDirs: true}, "library")
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	want := []SkippedDir{
+		{"2021-01-01", "dated directory, presumably organized already"},
+		{"DCIM/.thumbnails", "hidden"},
+		{"PRIVATE/AVCHD/BDMV", "AVCHD structure, organized separately"},
+		{"Recent", "recently imported files"},
+		{"library", "destination tree"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestScanFSParallel(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 5; j++ {
+			fsys[fmt.Sprintf("DCIM/%03d/sub/IMG_2021022%d_%06d.jpg", i, j, i)] = &fstest.MapFile{}
+		}
+		fsys[fmt.Sprintf("DCIM/%03d.jpg", i)] = &fstest.MapFile{}
+		fsys[fmt.Sprintf("DCIM/%03d/.thumbnails/thumb.jpg", i)] = &fstest.MapFile{}
+	}
+
+	var want []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".thumbnails" {
+			return fs.SkipDir
+		}
+		if !d.IsDir() {
+			want = append(want, p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{1, 8} {
+		files, skipped, err := scanFS(fsys, options{recursive: true, workers: workers}, "")
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		var got []string
+		for _, f := range files {
+			got = append(got, f.path)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("with %d workers, got %q, want %q", workers, got, want)
+		}
+		if len(skipped) != 20 {
+			t.Errorf("with %d workers, got %d skipped directories, want 20", workers, len(skipped))
+		}
+	}
+}
+
+func TestPlanRecursive(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"IMG_20210222_213525.jpg",
+		"DCIM/Camera/IMG_20210223_101010.jpg",
+		"2021-01-01/IMG_20210101_000000.jpg",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Files already in their dated directory are not moved again.
+	p, err := planOrganize(dir, options{recursive: true, matchers: mediaMatchers})
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if len(p.Moves) != 2 {
+		t.Errorf("got %d planned moves, want 2: %+v", len(p.Moves), p.Moves)
+	}
+	for _, m := range p.Moves {
+		if filepath.Dir(m.DestDir) != dir {
+			t.Errorf("%q planned to move into %q, want a dated directory of %q", m.Src, m.DestDir, dir)
+		}
+	}
+}
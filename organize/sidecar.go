@@ -0,0 +1,64 @@
+package organize
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// sidecarSuffixes are the (lower case) suffixes that, appended to the name of
+// a media file, name its sidecar files: XMP metadata written by photo editors
+// and Google Takeout's JSON metadata.
+var sidecarSuffixes = []string{".xmp", ".json"}
+
+// sidecarExtensions are the (lower case) extensions that, replacing the
+// extension of a media file, name its sidecar files: XMP metadata, Apple's
+// AAE edit instructions and the THM thumbnails of older cameras.
+var sidecarExtensions = []string{".xmp", ".aae", ".thm", ".json"}
+
+// findSidecars maps the paths of the media files among files to the paths of
+// their sidecar files among files, which share their name. Each sidecar is
+// assigned to a single media file.
+func findSidecars(files []sourceFile) map[string][]string {
+	byLowerPath := make(map[string]string, len(files))
+	for _, f := range files {
+		byLowerPath[strings.ToLower(f.path)] = f.path
+	}
+	sidecars := make(map[string][]string)
+	assigned := make(map[string]bool)
+	for _, f := range files {
+		if !IsMedia(f.path) {
+			continue
+		}
+		lower := strings.ToLower(f.path)
+		stem := strings.TrimSuffix(lower, filepath.Ext(lower))
+		var candidates []string
+		for _, suffix := range sidecarSuffixes {
+			candidates = append(candidates, lower+suffix)
+		}
+		for _, ext := range sidecarExtensions {
+			candidates = append(candidates, stem+ext)
+		}
+		for _, c := range candidates {
+			if path, ok := byLowerPath[c]; ok && !assigned[path] {
+				assigned[path] = true
+				sidecars[f.path] = append(sidecars[f.path], path)
+			}
+		}
+	}
+	return sidecars
+}
+
+// sidecarName returns the name that the sidecar file at sidecarPath gets when
+// its media file, originally named mediaName, is moved as newName.
+func sidecarName(sidecarPath, mediaName, newName string) string {
+	name := filepath.Base(sidecarPath)
+	if mediaName == newName {
+		return name
+	}
+	if strings.HasPrefix(strings.ToLower(name), strings.ToLower(mediaName)) {
+		return newName + name[len(mediaName):]
+	}
+	stem := strings.TrimSuffix(mediaName, filepath.Ext(mediaName))
+	newStem := strings.TrimSuffix(newName, filepath.Ext(newName))
+	return newStem + name[len(stem):]
+}
@@ -0,0 +1,77 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindSidecars(t *testing.T) {
+	var files []sourceFile
+	for _, path := range []string{
+		"a/IMG_20210222_213525.jpg",
+		"a/IMG_20210222_213525.jpg.xmp",
+		"a/IMG_20210222_213525.jpg.json",
+		"a/IMG_0001.HEIC",
+		"a/IMG_0001.AAE",
+		"a/MVI_0001.AVI",
+		"a/MVI_0001.THM",
+		"b/IMG_0001.xmp", // Different directory.
+		"a/notes.txt",
+		"a/notes.xmp", // Not a media file's.
+	} {
+		files = append(files, sourceFile{path: path})
+	}
+
+	got := findSidecars(files)
+	want := map[string][]string{
+		"a/IMG_20210222_213525.jpg": {"a/IMG_20210222_213525.jpg.xmp", "a/IMG_20210222_213525.jpg.json"},
+		"a/IMG_0001.HEIC":           {"a/IMG_0001.AAE"},
+		"a/MVI_0001.AVI":            {"a/MVI_0001.THM"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSidecarName(t *testing.T) {
+	tests := []struct {
+		sidecar, mediaName, newName string
+		want                        string
+	}{
+		{"a/IMG_0001.jpg.xmp", "IMG_0001.jpg", "IMG_0001.jpg", "IMG_0001.jpg.xmp"},
+		{"a/IMG_0001.jpg.xmp", "IMG_0001.jpg", "Photo_1.jpg", "Photo_1.jpg.xmp"},
+		{"a/IMG_0001.AAE", "IMG_0001.HEIC", "Photo_1.HEIC", "Photo_1.AAE"},
+	}
+
+	for _, tt := range tests {
+		if got := sidecarName(tt.sidecar, tt.mediaName, tt.newName); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestPlanMovesSidecars(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"IMG_20210222_213525.jpg", "IMG_20210222_213525.jpg.xmp", "IMG_20210222_213525.jpg.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := options{matchers: mediaMatchers}
+	p, err := planOrganize(dir, opts)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if len(p.Moves) != 1 || len(p.Unmatched) != 0 {
+		t.Fatalf("got %d moves and %d unmatched files, want 1 and 0", len(p.Moves), len(p.Unmatched))
+	}
+	executePlan(dir, p, opts)
+	for _, name := range []string{"IMG_20210222_213525.jpg", "IMG_20210222_213525.jpg.xmp", "IMG_20210222_213525.jpg.json"} {
+		if _, err := os.Stat(filepath.Join(dir, "2021-02-22", name)); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+}
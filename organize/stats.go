@@ -0,0 +1,72 @@
+package organize
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Count is a number of files and their total size in bytes.
+type Count struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+func (c *Count) add(size int64) {
+	c.Files++
+	c.Bytes += size
+}
+
+// Stats summarizes the contents of an organized tree.
+type Stats struct {
+	Total Count `json:"total"`
+	// Years holds the files of the dated directories by year.
+	Years map[int]Count `json:"years"`
+	// Undated holds the files outside the dated directories.
+	Undated Count `json:"undated"`
+}
+
+// Stats counts the files of the organized tree at root, by the year of the
+// dated directory they are in according to the Organizer's layout. Hidden
+// directories and the Recent and Views directories, which only hold links,
+// are not counted.
+func (o *Organizer) Stats(root string) (Stats, error) {
+	if o.opts.folderNamer != nil {
+		return Stats{}, errors.New("only trees organized by a layout can be summarized")
+	}
+	topLayout := strings.SplitN(o.opts.layout, "/", 2)[0]
+	stats := Stats{Years: make(map[int]Count)}
+	err := fs.WalkDir(os.DirFS(root), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && (strings.HasPrefix(d.Name(), ".") || p == RecentDirName || p == ViewsDirName) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stats.Total.add(info.Size())
+		_, dated := splitClassDir(path.Dir(p))
+		date, err := time.ParseInLocation(topLayout, strings.SplitN(dated, "/", 2)[0], time.Local)
+		if dated == "." || err != nil {
+			stats.Undated.add(info.Size())
+			return nil
+		}
+		year := stats.Years[date.Year()]
+		year.add(info.Size())
+		stats.Years[date.Year()] = year
+		return nil
+	})
+	return stats, err
+}
@@ -0,0 +1,45 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	root := t.TempDir()
+	for name, size := range map[string]int{
+		"2020/2020-12-31/IMG_20201231_235959.jpg":                    1,
+		"2021/2021-02-22/IMG_20210222_213525.jpg":                    2,
+		"2021/2021-02-22/IMG_20210222_213525.xmp":                    3,
+		"Screenshots/2021/2021-02-22/Screenshot_20210222-213525.png": 4,
+		"Holidays/IMG_20210222_213525.jpg":                           5, // Outside the dated tree.
+		".previews/2021/IMG_20200101_000000.jpg":                     6, // Hidden.
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithLayout("2006/2006-01-02"), WithExternalTools(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := o.Stats(root)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	want := Stats{
+		Total:   Count{5, 15},
+		Years:   map[int]Count{2020: {1, 1}, 2021: {3, 9}},
+		Undated: Count{1, 5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package organize
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// zonedTimestampRegexp matches timestamps with a UTC offset or "Z" suffix, as
+// written by some action cameras, e.g. "2023-03-15T14-22-33+0200.jpg".
+var zonedTimestampRegexp = regexp.MustCompile(`^(\d{4}-\d\d-\d\d)[T _](\d\d)[-.:](\d\d)[-.:](\d\d)(Z|[+-]\d\d:?\d\d)` + `(?:[^\d:].*)?` + extensionPattern)
+
+// parseZonedTimestamp parses a name matched by zonedTimestampRegexp. Names with
+// an explicit offset are dated in that offset, which is the local time where
+// they were captured. Names in UTC ("Z") carry no hint of the local time, and
+// are dated in the local time zone of this machine.
+func parseZonedTimestamp(s string) (time.Time, error) {
+	m := zonedTimestampRegexp.FindStringSubmatch(s)
+	offset := strings.Replace(m[5], ":", "", 1)
+	if offset == "Z" {
+		offset = "+0000"
+	}
+	date, err := time.Parse("2006-01-02T15:04:05-0700", m[1]+"T"+m[2]+":"+m[3]+":"+m[4]+offset)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if m[5] == "Z" {
+		date = date.Local()
+	}
+	return date, nil
+}
+
+// isoTimestampRegexps match the ISO 8601 style timestamps, without a UTC
+// offset, used by various export tools and screen recorders.
+var isoTimestampRegexps = []*regexp.Regexp{
+	// 2023-03-15 14.22.33.jpg
+	regexp.MustCompile(`^\d{4}-\d\d-\d\d[ T]\d\d\.\d\d\.\d\d(?:\D.*)?` + extensionPattern),
+	// 2023-03-15T142233.mp4
+	regexp.MustCompile(`^\d{4}-\d\d-\d\dT\d{6}(?:\D.*)?` + extensionPattern),
+	// 20230315T142233Z.jpg
+	regexp.MustCompile(`^\d{8}T\d{6}(?:\D.*)?` + extensionPattern),
+}
+
+// parseISOTimestamp parses a name matched by one of isoTimestampRegexps. The
+// timestamp is in local time, unless followed by "Z" for UTC in which case it
+// is converted to the local time zone of this machine.
+func parseISOTimestamp(s string) (time.Time, error) {
+	var digits []byte
+	i := 0
+	for ; i < len(s) && len(digits) < 14; i++ {
+		if isDigit(s[i]) {
+			digits = append(digits, s[i])
+		}
+	}
+	if i < len(s) && s[i] == 'Z' {
+		date, err := time.Parse("20060102150405", string(digits))
+		return date.Local(), err
+	}
+	return time.ParseInLocation("20060102150405", string(digits), time.Local)
+}
@@ -0,0 +1,28 @@
+package organize
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseZonedTimestampUTC(t *testing.T) {
+	got, err := parseZonedTimestamp("2023-03-15T23-30-00Z.jpg")
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	want := time.Date(2023, 3, 15, 23, 30, 0, 0, time.UTC)
+	if !got.Equal(want) || got.Location() != time.Local {
+		t.Errorf("got %s, want %s in local time", got, want)
+	}
+}
+
+func TestParseISOTimestampUTC(t *testing.T) {
+	got, err := parseISOTimestamp("20230315T233000Z.jpg")
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	want := time.Date(2023, 3, 15, 23, 30, 0, 0, time.UTC)
+	if !got.Equal(want) || got.Location() != time.Local {
+		t.Errorf("got %s, want %s in local time", got, want)
+	}
+}
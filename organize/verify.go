@@ -0,0 +1,82 @@
+package organize
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MisplacedFile is a file of an organized tree that is not in the directory
+// its date belongs in, e.g. after a manual drag and drop.
+type MisplacedFile struct {
+	Path string `json:"path"`
+	// Want is the path of the directory the file belongs in, if known.
+	Want   string `json:"want,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Verify checks the dated directories of the organized tree at root against
+// the Organizer's layout. It returns the files in a directory whose nesting
+// levels disagree (e.g. 2021/2020-02-22), and those whose name dates them to
+// a different directory. Files whose name carries no date are only checked
+// for the former, as their metadata may be in another time zone than the
+// organizing was done in.
+func (o *Organizer) Verify(root string) ([]MisplacedFile, error) {
+	if o.opts.folderNamer != nil {
+		return nil, errors.New("only trees organized by a layout can be verified")
+	}
+	layout := o.opts.layout
+	topLayout := strings.SplitN(layout, "/", 2)[0]
+	var misplaced []MisplacedFile
+	err := fs.WalkDir(os.DirFS(root), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		dir := path.Dir(p)
+		class, dated := splitClassDir(dir)
+		// Only files within the dated tree are checked.
+		top := strings.SplitN(dated, "/", 2)[0]
+		if dated == "." || !d.Type().IsRegular() {
+			return nil
+		}
+		if _, err := time.ParseInLocation(topLayout, top, time.Local); err != nil {
+			return nil
+		}
+		var want, reason string
+		if date, err := getDate(o.opts.matchers, d.Name()); err == nil {
+			want = path.Join(class, date.Format(layout))
+			reason = fmt.Sprintf("named for %s", date.Format("2006-01-02"))
+		}
+		if dirDate, err := time.ParseInLocation(layout, dated, time.Local); err != nil || dirDate.Format(layout) != dated {
+			reason = fmt.Sprintf("%q does not match the layout %q", dir, layout)
+		} else if want == "" || want == dir {
+			return nil
+		}
+		misplaced = append(misplaced, MisplacedFile{filepath.Join(root, filepath.FromSlash(p)), filepath.FromSlash(want), reason})
+		return nil
+	})
+	return misplaced, err
+}
+
+// splitClassDir splits the slash separated directory dir of an organized tree
+// into the tree of its media class (e.g. "Screenshots", or empty for photos)
+// and its path within that tree.
+func splitClassDir(dir string) (class, rest string) {
+	for _, classDir := range classDirs {
+		if classDir != "" && strings.HasPrefix(dir, classDir+"/") {
+			return classDir, strings.TrimPrefix(dir, classDir+"/")
+		}
+	}
+	return "", dir
+}
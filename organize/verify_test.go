@@ -0,0 +1,62 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"2021/2021-02-22/IMG_20210222_213525.jpg",
+		"2021/2021-02-22/PICT0012.JPG",
+		"2021/2021-02-22/IMG_20210223_080000.jpg", // Dropped into the wrong day.
+		"2021/2020-12-31/PICT0013.JPG",            // Nesting levels disagree.
+		"2021/IMG_20210301_120000.jpg",            // Dropped into the year.
+		"Screenshots/2021/2021-02-22/Screenshot_20210222-213525.png",
+		"Holidays/IMG_20210222_213525.jpg",       // Outside the dated tree.
+		".previews/2021/IMG_20200101_000000.jpg", // Hidden.
+		"IMG_20210222_213525.jpg",                // Not organized yet.
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o, err := New(WithLayout("2006/2006-01-02"), WithExternalTools(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	misplaced, err := o.Verify(root)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	var got []string
+	for _, m := range misplaced {
+		rel, err := filepath.Rel(root, m.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.ToSlash(rel)+" -> "+filepath.ToSlash(m.Want))
+	}
+	sort.Strings(got)
+	want := []string{
+		"2021/2020-12-31/PICT0013.JPG -> ",
+		"2021/2021-02-22/IMG_20210223_080000.jpg -> 2021/2021-02-23",
+		"2021/IMG_20210301_120000.jpg -> 2021/2021-03-01",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %s, want %s", got[i], want[i])
+		}
+	}
+}
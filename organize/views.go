@@ -0,0 +1,192 @@
+package organize
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ViewsDirName is the directory, at the root of the organized directory,
+// holding the alternate views built by BuildView.
+const ViewsDirName = "Views"
+
+// View is an alternate way of grouping the files of an organized directory.
+type View string
+
+const (
+	// ViewYear groups files by the year of their dated directory.
+	ViewYear View = "year"
+	// ViewCamera groups files by the camera recorded in their EXIF metadata.
+	ViewCamera View = "camera"
+	// ViewType groups files into photos, RAW files and videos.
+	ViewType View = "type"
+)
+
+// AllViews lists the supported views.
+var AllViews = []View{ViewYear, ViewCamera, ViewType}
+
+// viewDirs maps each view to its directory in the views directory.
+var viewDirs = map[View]string{
+	ViewYear:   "By year",
+	ViewCamera: "By camera",
+	ViewType:   "By type",
+}
+
+// String implements flag.Value.
+func (v *View) String() string {
+	return string(*v)
+}
+
+// Set implements flag.Value.
+func (v *View) Set(s string) error {
+	if _, ok := viewDirs[View(s)]; !ok {
+		return fmt.Errorf("unknown view %q, want %q, %q or %q", s, ViewYear, ViewCamera, ViewType)
+	}
+	*v = View(s)
+	return nil
+}
+
+// yearRegexp matches path elements starting with a year.
+var yearRegexp = regexp.MustCompile(`^(?:19|20)\d\d`)
+
+// BuildView builds or refreshes the given view of the organized directory
+// root: a directory of symbolic links to the media files of root, grouped by
+// year, camera or type, so they can be browsed that way without duplicating
+// them. Links left over from a previous build are replaced. It returns the
+// number of links created.
+func BuildView(root string, view View) (int, error) {
+	dir, ok := viewDirs[view]
+	if !ok {
+		return 0, fmt.Errorf("unknown view %q", view)
+	}
+	dir = filepath.Join(root, ViewsDirName, dir)
+	if err := clearLinks(dir); err != nil {
+		return 0, err
+	}
+	files, err := archivedFiles(root)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, rel := range files {
+		group := viewGroup(root, rel, view)
+		if group == "" {
+			continue
+		}
+		groupDir := filepath.Join(dir, group)
+		if err := os.MkdirAll(groupDir, 0755); err != nil {
+			return count, err
+		}
+		target, err := filepath.Rel(groupDir, filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return count, err
+		}
+		link := filepath.Join(groupDir, strings.ReplaceAll(rel, "/", "_"))
+		if err := os.Symlink(target, link); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// archivedFiles lists the media files of the organized directory root, by
+// their slash separated path relative to root, in lexical order. Hidden
+// directories and those holding links are left out.
+func archivedFiles(root string) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(os.DirFS(root), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && (strings.HasPrefix(d.Name(), ".") || p == ViewsDirName || p == RecentDirName) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && IsMedia(d.Name()) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// viewGroup returns the name of the group of the given view that the file at
+// rel, relative to root, belongs to, or an empty string if it belongs to none.
+func viewGroup(root, rel string, view View) string {
+	switch view {
+	case ViewYear:
+		for _, elem := range strings.Split(path.Dir(rel), "/") {
+			if year := yearRegexp.FindString(elem); year != "" {
+				return year
+			}
+		}
+	case ViewCamera:
+		values, err := exifMetadata(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return "Unknown camera"
+		}
+		return cameraName(values[0x010F], values[0x0110])
+	case ViewType:
+		switch {
+		case rawExtensions[strings.ToLower(path.Ext(rel))]:
+			return "RAW"
+		case isVideo(rel):
+			return "Videos"
+		default:
+			return "Photos"
+		}
+	}
+	return ""
+}
+
+// cameraName returns a directory name for the camera of the given EXIF make
+// and model. Models usually, but not always, include the make.
+func cameraName(maker, model string) string {
+	name := model
+	if !strings.HasPrefix(strings.ToLower(model), strings.ToLower(maker)) {
+		name = strings.TrimSpace(maker + " " + model)
+	}
+	name = strings.NewReplacer("/", "-", `\`, "-").Replace(name)
+	if name == "" || name == "." || name == ".." {
+		return "Unknown camera"
+	}
+	return name
+}
+
+// clearLinks removes the symbolic links under dir, and the directories left
+// empty, so that a view can be rebuilt. Other files are left alone.
+func clearLinks(dir string) error {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) && p == dir {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			dirs = append(dirs, p)
+		case d.Type()&os.ModeSymlink != 0:
+			return os.Remove(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		// Directories still holding other files stay.
+		os.Remove(dirs[i])
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestBuildView(t *testing.T) {
+	root := t.TempDir()
+	files := map[string][]byte{
+		"2021-02-22/IMG_0001.jpg":            exifJPEG([]tiffEntry{{0x010F, "Canon"}, {0x0110, "Canon EOS R5"}}, nil),
+		"2021-02-22/IMG_0001.CR2":            exifTIFF([]tiffEntry{{0x010F, "Canon"}, {0x0110, "Canon EOS R5"}}, nil),
+		"2022/03/PXL_20220315_101010.jpg":    exifJPEG([]tiffEntry{{0x010F, "Google"}, {0x0110, "Pixel 7"}}, nil),
+		"2022/03/VID_20220315_101010.mp4":    nil,
+		"Screenshots/2023-01-01/shot.png":    nil,
+		"2021-02-22/notes.txt":               nil,
+		".previews/ab/abcdef.mp4":            nil,
+		"Views/By type/Photos/unrelated.txt": nil,
+	}
+	for name, contents := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, contents, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		view View
+		want []string
+	}{
+		{ViewYear, []string{
+			"2021/2021-02-22_IMG_0001.CR2",
+			"2021/2021-02-22_IMG_0001.jpg",
+			"2022/2022_03_PXL_20220315_101010.jpg",
+			"2022/2022_03_VID_20220315_101010.mp4",
+			"2023/Screenshots_2023-01-01_shot.png",
+		}},
+		{ViewCamera, []string{
+			"Canon EOS R5/2021-02-22_IMG_0001.CR2",
+			"Canon EOS R5/2021-02-22_IMG_0001.jpg",
+			"Google Pixel 7/2022_03_PXL_20220315_101010.jpg",
+			"Unknown camera/2022_03_VID_20220315_101010.mp4",
+			"Unknown camera/Screenshots_2023-01-01_shot.png",
+		}},
+		{ViewType, []string{
+			"Photos/2021-02-22_IMG_0001.jpg",
+			"Photos/2022_03_PXL_20220315_101010.jpg",
+			"Photos/Screenshots_2023-01-01_shot.png",
+			"RAW/2021-02-22_IMG_0001.CR2",
+			"Videos/2022_03_VID_20220315_101010.mp4",
+		}},
+	}
+
+	for _, tt := range tests {
+		// Building twice refreshes the view.
+		for i := 0; i < 2; i++ {
+			n, err := BuildView(root, tt.view)
+			if err != nil {
+				t.Fatalf("Expected no error but received: %s", err)
+			}
+			if n != len(tt.want) {
+				t.Errorf("got %d links, want %d (view: %s)", n, len(tt.want), tt.view)
+			}
+		}
+		dir := filepath.Join(root, ViewsDirName, viewDirs[tt.view])
+		var got []string
+		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.Type()&os.ModeSymlink == 0 {
+				return err
+			}
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("Expected no error but received: %s", err)
+			}
+			rel, _ := filepath.Rel(dir, path)
+			got = append(got, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got %q, want %q (view: %s)", got, tt.want, tt.view)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, ViewsDirName, "By type", "Photos", "unrelated.txt")); err != nil {
+		t.Errorf("Expected no error but received: %s", err)
+	}
+}
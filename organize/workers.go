@@ -0,0 +1,85 @@
+package organize
+
+import "sync"
+
+// runMu serializes the updates of the state shared by the workers of a run:
+// the near misses, the report, the progress, the budget and the files
+// recording the moves (journal, playlists, Recent links).
+var runMu sync.Mutex
+
+// memoryPerWorker is about the most memory a worker needs at a time, for the
+// metadata, hashes and copy buffers of the files it handles.
+const memoryPerWorker = 32 << 20
+
+// parallelism returns the number of files processed at a time with opts, e.g.
+// to share the memory of the run between the workers. With opts.maxMemory,
+// there are no more workers than half of it can keep busy.
+func parallelism(opts options) int {
+	workers := opts.workers
+	if opts.maxMemory > 0 {
+		if n := int(opts.maxMemory / 2 / memoryPerWorker); n < workers {
+			workers = n
+		}
+	}
+	if workers < 1 {
+		return 1
+	}
+	return workers
+}
+
+// forEach calls fn with each index below n, from up to workers goroutines,
+// and returns once all calls have returned. With a single worker, the calls
+// are made in order.
+func forEach(n, workers int, fn func(i int)) {
+	if workers <= 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	if workers > n {
+		workers = n
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// moveGroups partitions the indexes of moves into groups that may be executed
+// concurrently: the moves into the same directory, which may compete for the
+// same names, are grouped in plan order. With a single worker, all moves form
+// one group, keeping the plan order.
+func moveGroups(moves []PlannedMove, workers int) [][]int {
+	if workers <= 1 {
+		group := make([]int, len(moves))
+		for i := range moves {
+			group[i] = i
+		}
+		return [][]int{group}
+	}
+	var groups [][]int
+	byDir := make(map[string]int)
+	for i, m := range moves {
+		g, ok := byDir[m.DestDir]
+		if !ok {
+			g = len(groups)
+			byDir[m.DestDir] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
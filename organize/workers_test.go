@@ -0,0 +1,83 @@
+package organize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrganizeWorkers(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for day := 1; day <= 5; day++ {
+		for n := 0; n < 10; n++ {
+			name := fmt.Sprintf("IMG_202102%02d_%06d.jpg", day, n)
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0600); err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, name)
+		}
+	}
+	journal := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	o, err := New(WithExternalTools(false), WithWorkers(4), WithLayout("2006/2006-01-02"), WithPlaylists(true), WithJournal(journal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := o.Organize(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if moved != len(names) {
+		t.Errorf("got %d files moved, want %d", moved, len(names))
+	}
+	if got := len(o.Report().Files); got != len(names) {
+		t.Errorf("got %d files reported, want %d", got, len(names))
+	}
+	entries, err := ReadJournal(journal)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if len(entries) != len(names) {
+		t.Errorf("got %d journal entries, want %d", len(entries), len(names))
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, "2021", "2021-02-"+name[10:12], name)
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+}
+
+func TestMoveGroups(t *testing.T) {
+	moves := []PlannedMove{{DestDir: "a"}, {DestDir: "b"}, {DestDir: "a"}, {DestDir: "c"}}
+	got := fmt.Sprint(moveGroups(moves, 4))
+	if want := "[[0 2] [1] [3]]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	got = fmt.Sprint(moveGroups(moves, 1))
+	if want := "[[0 1 2 3]]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestParallelism(t *testing.T) {
+	tests := []struct {
+		workers   int
+		maxMemory int64
+		want      int
+	}{
+		{0, 0, 1},
+		{8, 0, 8},
+		{8, 1 << 30, 8},
+		{8, 256 << 20, 4},
+		{8, 16 << 20, 1},
+	}
+
+	for _, tt := range tests {
+		if got := parallelism(options{workers: tt.workers, maxMemory: tt.maxMemory}); got != tt.want {
+			t.Errorf("got %d, want %d (workers: %d, max memory: %d)", got, tt.want, tt.workers, tt.maxMemory)
+		}
+	}
+}
@@ -2,197 +2,364 @@
 // of appropriately named directories, corresponding to the date the pictures
 // were taken. The directories are named in the form YYYY-MM-DD, and are created
 // as needed. This tool makes the assumption that the appropriate date is
-// encoded in the file name.
+// encoded in the file name. The work is done by the organize package; this
+// file holds the command line interface.
 //
 // Usage:
-//  $ organizepics [path_to_directory_with_pictures]
-
+//
+//	$ organizepics [organize] [path_to_directory_with_pictures]
+//	$ organizepics <command> [arguments]
 package main
 
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
-	"regexp"
 	"strings"
+	"time"
+
+	"github.com/cvanderw/organizepics/organize"
 )
 
+// command is a subcommand of organizepics. It is run with the arguments
+// following its name and returns the process exit code.
+type command struct {
+	name string
+	// args describes the flags and arguments of the command for the usage.
+	args string
+	run  func(args []string) int
+}
+
+// commands returns the subcommands of organizepics, in the order of the usage.
+func commands() []command {
+	return []command{
+		{"organize", "[flags] <picture directory>", func(args []string) int { return organizeDir(args, modeOrganize) }},
+		{"watch", "[flags] [--poll-interval <duration>] [--settle <duration>] <picture directory>", func(args []string) int { return organizeDir(args, modeWatch) }},
+		{"plan", "[flags] <picture directory> > <plan file>", func(args []string) int { return organizeDir(args, modePlan) }},
+		{"apply", "[flags] <plan file>", func(args []string) int { return organizeDir(args, modeApply) }},
+		{"test-matcher", "--pattern <regex> [--layout <date layout>] [file names...]", testMatcher},
+		{"set-date", "--date <date> [--root <dir>] [--layout <date layout>] [--journal <journal>] <files...>", setDate},
+		{"views", "[--view <view>] <organized directory>", buildViews},
+		{"undo", "[--dry-run] <journal>", undo},
+		{"rm", "--journal <journal> [--reason <reason>] [--trash] [--root <dir>] <files...>", rm},
+		{"verify", "[--layout <date layout>] <organized directory>", verify},
+		{"dedupe", "[--journal <journal>] [--trash] [--dry-run] <organized directory>", dedupe},
+		{"stats", "[--layout <date layout>] <organized directory>", stats},
+		{"mv", "[--root <dir>] [--layout <date layout>] [--journal <journal>] <file> <date>", mv},
+		{"merge-folders", "[--rule descriptive|plain] [--layout <date layout>] [--journal <journal>] [--dry-run] <organized directory>", mergeFolders},
+		{"version", "", printVersion},
+		{"self-update", "[--check] [--force]", selfUpdate},
+	}
+}
+
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "  %s [path to picture directory]\n", os.Args[0])
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "  %s\n", strings.TrimSpace(os.Args[0]+" "+c.name+" "+c.args))
+	}
+	fmt.Fprintf(os.Stderr, "The command may be left out to organize: %s [flags] <picture directory>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Run %s <command> --help for the flags of a command.\n", os.Args[0])
 }
 
-// TODO: Consider pulling a lot of this out into a Go library (which can be
-// separately tested).
-
-// MediaFileMatcher represents an element capable of parsing date information
-// for a given file name. Each MediaFileMatcher is specifically intended to
-// handle certain file types and is capable of parsing date information from
-// those applicable file names. For example, a MediaFileMatcher intended to
-// match image files of format "IMG_YYYYMMDD_*.jpg" is capable of parsing out
-// the intended date in format YYYY-MM-DD but is unable to reliably do so for
-// other file formats it is not designed for.
-type MediaFileMatcher struct {
-	supportedRegexps []*regexp.Regexp
-	parseDate        func(s string) (year, month, day string)
+func main() {
+	os.Exit(run(os.Args[1:]))
 }
 
-// MatchFileName determines whether or not the MediaFileMatcher supports the
-// file with name givey by the parameter s.
-func (m *MediaFileMatcher) MatchFileName(s string) bool {
-	for _, re := range m.supportedRegexps {
-		if re.MatchString(s) {
-			return true
+// run runs the subcommand named by the first of args, or the organize one if
+// args start with a flag or a directory, and returns the process exit code.
+func run(args []string) int {
+	if len(args) > 0 {
+		for _, c := range commands() {
+			if args[0] == c.name {
+				return c.run(args[1:])
+			}
+		}
+		if !strings.HasPrefix(args[0], "-") {
+			if _, err := os.Stat(args[0]); os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "%q is neither a command nor a directory\n", args[0])
+				usage()
+				return 2
+			}
 		}
 	}
-	return false
+	return organizeDir(args, modeOrganize)
 }
 
-// ParseFormattedDate parses the provided string `s` into a string of format
-// YYYY-MM-DD. Note that calling ParseFormattedDate on file name for which
-// MatchFileName returns false is not deterministic and would most likely not
-// provide meaningful results.
-//
-// Suggested usage pattern:
-//   if (matcher.MatchFileName(s)) {
-//     formattedDate := matcher.ParseFormattedDate(s)
-//     // Do somethign with `formattedDate`.
-//   }
-func (m *MediaFileMatcher) ParseFormattedDate(s string) string {
-	year, month, day := m.parseDate(s)
-	return fmt.Sprintf("%s-%s-%s", year, month, day)
-}
+// runMode selects what organizeDir does, for the subcommands sharing the
+// flags of the organize subcommand.
+type runMode int
 
-var mediaMatchers = []*MediaFileMatcher{
-	{
-		// Intended to match files of format
-		//  - IMG_YYYYMMDD_NUMBER.jpg
-		//  - VID_YYYYMMDD_NUMBER.mp4
-		//  - PXL_YYYYMMDD_NUMBER.{jpg,mp4}
-		supportedRegexps: []*regexp.Regexp{
-			regexp.MustCompile(`IMG_\d{8}_.+jpg$`),
-			regexp.MustCompile(`VID_\d{8}_.+mp4$`),
-			regexp.MustCompile(`PXL_\d{8}_.+jpg$`),
-			regexp.MustCompile(`PXL_\d{8}_.+mp4$`),
-		},
-		parseDate: func(s string) (year, month, day string) {
-			date := strings.Split(s, "_")[1]
-			year = date[:4]
-			month = date[4:6]
-			day = date[6:]
-			return
-		},
-	},
-	{
-		// Intended to match C360_YYYY-MM-DD-hh-mm-ss-mmm.jpg.
-		supportedRegexps: []*regexp.Regexp{
-			regexp.MustCompile(`C360_\d{4}-\d\d-\d\d-\d\d-\d\d-\d\d-\d{3}\.jpg`),
-		},
-		parseDate: func(s string) (year, month, day string) {
-			date := strings.Split(s, "_")[1]
-			dateVals := strings.Split(date, "-")
-			year = dateVals[0]
-			month = dateVals[1]
-			day = dateVals[2]
-			return
-		},
-	},
-	{
-		// Intended to match files of format
-		//	- YYYYMMDD_NUMBER.jpg
-		//	- YYYYMMDD_NUMBER.mp4
-		supportedRegexps: []*regexp.Regexp{
-			regexp.MustCompile(`\d{8}_.+jpg$`),
-			regexp.MustCompile(`\d{8}_.+mp4$`),
-		},
-		parseDate: func(s string) (year, month, day string) {
-			date := strings.Split(s, "_")[0]
-			year = date[:4]
-			month = date[4:6]
-			day = date[6:]
-			return
-		},
-	},
-}
+const (
+	// modeOrganize organizes a directory once.
+	modeOrganize runMode = iota
+	// modeWatch organizes a directory whenever new files have settled in it.
+	modeWatch
+	// modePlan writes the plan of organizing a directory to standard output.
+	modePlan
+	// modeApply performs a plan written by modePlan.
+	modeApply
+)
 
-// organizePics accepts a directory name and organizes all recognized files
-// (images, videos) into appropriate directories.
-// TODO: Consider accepting a slice of os.FileInfo to reduce dependency on file
-// system and make it easier to test (although that might not be entirely
-// easy).
-func organizePics(dirName string) {
-	files, err := ioutil.ReadDir(dirName)
-	if err != nil {
-		log.Fatal(err)
+// organizeDir implements the organize subcommand, the default one, which
+// organizes a picture directory, and its variants selected by mode. It
+// returns the process exit code.
+func organizeDir(args []string, mode runMode) int {
+	fs := flag.NewFlagSet("organize", flag.ContinueOnError)
+	explainUnmatched := fs.Bool("explain-unmatched", false, "report which matchers came close for files that could not be matched")
+	mtimeFallback := fs.Bool("mtime-fallback", false, "date images and videos that can't be dated otherwise (e.g. old camcorder AVIs) by their modification time")
+	fatTimestamps := fs.Bool("fat-timestamps", false, "date 8.3 named files (e.g. PICT0012.JPG) that no matcher handles by their FAT timestamp")
+	previews := fs.Bool("previews", false, "generate small preview proxies of videos in "+organize.PreviewsDirName+" (requires ffmpeg)")
+	protectDest := fs.Bool("protect-dest", false, "never overwrite or delete existing files in the destination (requires hard link support)")
+	classify := fs.Bool("classify", false, "move screenshots and document scans into separate Screenshots and Documents trees")
+	playlists := fs.Bool("playlists", false, "maintain a YYYY-MM-DD.m3u playlist per date at the root of the directory")
+	scanDates := fs.Bool("scan-dates", false, "date files that no matcher handles by a plausible date anywhere in their name")
+	anchoring := organize.DefaultAnchoring
+	fs.Var(&anchoring, "anchoring", "where built-in matchers' patterns may occur in file names: prefix (start of the name only) or substring (anywhere)")
+	folderDate := organize.DefaultFolderDate
+	fs.Var(&folderDate, "folder-date", "time zone capture times are read in to pick the day files are filed under: camera (the device's, where recorded), local (this machine's) or utc")
+	multipleDates := organize.MultipleDatesFirst
+	fs.Var(&multipleDates, "multiple-dates", "date to use for file names with several dates: first, last or metadata (the one agreeing with the file's metadata)")
+	copySuffix := organize.CopySuffixKeep
+	fs.Var(&copySuffix, "copy-suffix", "handling of files like \"IMG_0001 (1).jpeg\": keep, collapse (remove if identical to IMG_0001.jpeg at the destination) or rename (also drop the suffix if the name is free)")
+	onConflict := organize.ConflictSkip
+	fs.Var(&onConflict, "on-conflict", "handling of files whose destination name is taken by a different file: skip, rename (add a _N suffix), overwrite or fail (before moving anything)")
+	duplicates := organize.DuplicateSkip
+	fs.Var(&duplicates, "duplicates", "handling of files whose identical copy is already archived: skip (leave in place) or delete")
+	paranoid := fs.Bool("paranoid", false, "hash already archived files even if their name, size and modification time are equal")
+	earliestDate := fs.String("earliest-date", "", "reject dates before this one (YYYY-MM-DD, e.g. 1990-01-01) as invalid")
+	rejectFuture := fs.Bool("reject-future", false, "reject dates in the future as invalid")
+	dateTag := fs.String("date-tag", "", "metadata tag to prefer for capture dates: "+strings.Join(organize.DateTags, ", "))
+	jsonRPC := fs.Bool("json-rpc", false, "serve JSON-RPC requests (match, plan, organize, apply) on stdin/stdout instead of organizing a directory")
+	var notifier indexNotifier
+	fs.StringVar(&notifier.touchPath, "touch-after", "", "touch this marker file after runs that moved files")
+	fs.StringVar(&notifier.url, "notify-url", "", "request this URL after runs that moved files (e.g. to trigger a photo app's library scan)")
+	fs.StringVar(&notifier.method, "notify-method", "POST", "HTTP method used for --notify-url")
+	fs.Var((*stringsFlag)(&notifier.headers), "notify-header", "header (\"Name: value\") sent with --notify-url; may be repeated")
+	layout := fs.String("layout", organize.DefaultLayout, "Go time layout of the dated directories' paths, e.g. 2006/01 or 2006/2006-01-02 for nested year and month directories")
+	dest := fs.String("dest", "", "create the dated directories under this directory (e.g. a library on a NAS) instead of in the organized directory")
+	holdOutliers := fs.Bool("hold-outliers", false, "leave files whose dates are far from most others (e.g. a camera clock reset to 2000-01-01) in place for review")
+	recentDays := fs.Int("recent-days", 0, "keep links to the files imported in the last this many days in Recent/ (0 disables)")
+	maxRuntime := fs.Duration("max-runtime", 0, "stop moving files after this long (e.g. 30m), leaving the rest for the next run (0 disables)")
+	var maxBytes, maxMemory byteSize
+	fs.Var(&maxBytes, "max-bytes", "stop moving files after this many bytes (e.g. 10G), leaving the rest for the next run (0 disables)")
+	fs.Var(&maxMemory, "max-memory", "adapt memory use (workers, copy buffers, threads of ffmpeg for previews) to stay within this many bytes (e.g. 512M) on small NAS boxes (0 disables)")
+	verifyMoves := fs.Bool("verify", false, "hash each file before and after moving it, reverting moves that changed it (e.g. over flaky network mounts)")
+	workers := fs.Int("workers", 1, "number of files dated, hashed and moved in parallel (e.g. 4 when moving to a NAS)")
+	journal := fs.String("journal", "", "record every move in this file (JSON lines), so that the run can be reverted with the undo subcommand")
+	var report reportFormat
+	fs.Var(&report, "report", "write a report of what happened to each file at the end of the run: json or csv")
+	reportFile := fs.String("report-file", "", "write the --report to this file instead of standard output")
+	stateFile := fs.String("state-file", "", "record the moves left undone by --max-runtime or --max-bytes in this file, for the next run to resume")
+	recursive := fs.Bool("recursive", false, "also organize files in subdirectories (e.g. DCIM/Camera), into dated directories at the top level")
+	fs.BoolVar(recursive, "r", false, "shorthand for --recursive")
+	skipDatedDirs := fs.Bool("skip-dated-dirs", true, "with --recursive, leave out directories named YYYY-MM-DD, which are presumably organized already")
+	logSkipped := fs.Bool("log-skipped", false, "with --recursive, log each directory that was skipped and why")
+	lang := fs.String("lang", "", "language of the progress and summary messages, e.g. de (default: from the locale, e.g. LANG); one of en, "+strings.Join(languages(), ", "))
+	progress := fs.Bool("progress", isTerminal(os.Stderr), "show the progress of the run (files done out of the total, bytes moved and time left); on by default on a terminal")
+	pollInterval := fs.Duration("poll-interval", 10*time.Second, "with watch, how often to look for new files in minimal builds or where file system notifications are unavailable")
+	settle := fs.Duration("settle", time.Minute, "with watch, how long no file must be written to the directory before organizing it, so that files still being transferred aren't grabbed")
+	dryRun := fs.Bool("dry-run", false, "log the moves that would be made and the directories that would be created, without changing anything")
+	var patterns []string
+	fs.Var((*stringsFlag)(&patterns), "pattern", "regular expression with (?P<year>...), (?P<month>...) and (?P<day>...) groups matching file names the built-in matchers don't recognize; may be repeated")
+	var include, exclude []string
+	fs.Var((*stringsFlag)(&include), "include", "only consider files whose name matches this glob pattern (e.g. '*.jpg'), or whose path does if it has a slash; may be repeated")
+	fs.Var((*stringsFlag)(&exclude), "exclude", "never consider files whose name matches this glob pattern (e.g. '*.tmp' or '.trashed-*'), or whose path does if it has a slash; may be repeated")
+	configPath := fs.String("config", "", "YAML or TOML file declaring custom matchers, tried before the built-in ones")
+	backgroundPriority := fs.Bool("background-priority", false, "run with a lower CPU and I/O priority, so as not to interfere with other workloads")
+	profileName := fs.String("profile", "", "apply the settings of a built-in profile, which flags given explicitly override: "+strings.Join(profileNames(), ", "))
+	quarantine := fs.String("quarantine", "", "move images and videos that can't be dated into this directory (e.g. Unsorted), relative to the destination")
+	unmatchedDir := fs.String("unmatched-dir", "", "move all other files that can't be dated into this directory (e.g. _unsorted), relative to the destination, so that nothing is left behind")
+	force := fs.Bool("force", false, "organize the directory even if it doesn't look like a picture directory")
+	noExternalTools := fs.Bool("no-external-tools", false, "never run external tools (exiftool, ffprobe) to read metadata dates")
+	fs.Usage = func() {
+		usage()
+		fmt.Fprintf(fs.Output(), "Flags of organize, watch, plan and apply:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	var prof profile
+	if *profileName != "" {
+		var err error
+		if prof, err = applyProfile(fs, *profileName); err != nil {
+			log.Fatal(err)
+		}
 	}
-	for _, file := range files {
-		if !file.IsDir() {
-			fileName := file.Name()
-
-			destDirName, err := getFolderName(fileName)
-			if err != nil {
-				log.Print(err)
-				continue
-			}
-			destPath := filepath.Join(dirName, destDirName)
-
-			// Check if dir exists, making it if it doesn't.
-			if _, err := os.Stat(destPath); os.IsNotExist(err) {
-				// Now create it.
-				err := os.Mkdir(destPath, 0700)
-				if err != nil {
-					log.Fatalf("unable to mkdir %q: %v", destPath, err)
-				}
-			}
 
-			// Ensure intended path doesn't already exist.
-			destFilePath := filepath.Join(destPath, fileName)
-			if _, err := os.Stat(destFilePath); err == nil {
-				// File exists, and that's not okay. Probably safer not to
-				// overwrite the existing file. Log a warning and continue to
-				// the next file; the user can decide what to do.
-				log.Printf("Destination file %q already exists in %q\n", fileName, destPath)
-				continue
-			}
-			// Move file to new location.
-			os.Rename(filepath.Join(dirName, fileName), destFilePath)
+	setLanguage(*lang)
+	limitMemory(maxMemory)
+	if *backgroundPriority {
+		if err := lowerPriority(); err != nil {
+			log.Printf("unable to lower the priority of the process: %v", err)
 		}
 	}
-}
 
-// getFolderName accepts a file name and returns name that would be appropriate
-// to store that given file. If no such folder name can be determined then this
-// function returns a non-nil error.
-func getFolderName(fileName string) (string, error) {
-	for _, matcher := range mediaMatchers {
-		if matcher.MatchFileName(fileName) {
-			return matcher.ParseFormattedDate(fileName), nil
+	var matchers []*organize.MediaFileMatcher
+	for _, pattern := range patterns {
+		matcher, err := organize.NewPatternMatcher(pattern, "")
+		if err != nil {
+			log.Fatal(err)
 		}
+		matchers = append(matchers, matcher)
+	}
+	if *configPath != "" {
+		configMatchers, err := organize.LoadMatchers(*configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		matchers = append(matchers, configMatchers...)
 	}
-	return "", fmt.Errorf("no matcher found for %q", fileName)
-}
 
-func main() {
-	flag.Usage = usage
-	flag.Parse()
+	var earliest time.Time
+	if *earliestDate != "" {
+		var err error
+		if earliest, err = time.ParseInLocation("2006-01-02", *earliestDate, time.Local); err != nil {
+			log.Fatalf("Invalid --earliest-date: %v", err)
+		}
+	}
 
-	if flag.NArg() != 1 {
-		fmt.Fprintf(os.Stderr, "Incorrect number of args to program. Expected 1, received %d\n", flag.NArg())
-		usage()
-		os.Exit(1)
+	var onProgress func(organize.Progress)
+	if *progress {
+		onProgress = newProgressLine(os.Stderr).update
 	}
 
-	dirName := flag.Arg(0)
-	dir, err := os.Stat(dirName)
+	organizer, err := organize.New(
+		organize.WithMatchers(matchers...),
+		organize.WithExplainUnmatched(*explainUnmatched),
+		organize.WithFATTimestamps(*fatTimestamps),
+		organize.WithMtimeFallback(*mtimeFallback),
+		organize.WithPreviews(*previews),
+		organize.WithProtectDest(*protectDest),
+		organize.WithClassify(*classify),
+		organize.WithPlaylists(*playlists),
+		organize.WithScanDates(*scanDates),
+		organize.WithAnchoring(anchoring),
+		organize.WithMultipleDates(multipleDates),
+		organize.WithFolderDate(folderDate),
+		organize.WithCopySuffix(copySuffix),
+		organize.WithOnConflict(onConflict),
+		organize.WithDuplicates(duplicates),
+		organize.WithParanoid(*paranoid),
+		organize.WithLayout(*layout),
+		organize.WithDest(*dest),
+		organize.WithRecent(*recentDays),
+		organize.WithHoldOutliers(*holdOutliers),
+		organize.WithMaxRuntime(*maxRuntime),
+		organize.WithMaxBytes(int64(maxBytes)),
+		organize.WithMaxMemory(int64(maxMemory)),
+		organize.WithStateFile(*stateFile),
+		organize.WithWorkers(*workers),
+		organize.WithVerify(*verifyMoves),
+		organize.WithJournal(*journal),
+		organize.WithBuild(buildSummary()),
+		organize.WithRecursive(*recursive),
+		organize.WithSkipDatedDirs(*skipDatedDirs),
+		organize.WithLogSkipped(*logSkipped),
+		organize.WithQuarantine(*quarantine),
+		organize.WithUnmatchedDir(*unmatchedDir),
+		organize.WithInclude(include...),
+		organize.WithExclude(exclude...),
+		organize.WithDryRun(*dryRun),
+		organize.WithProgress(onProgress),
+		organize.WithEarliestDate(earliest),
+		organize.WithRejectFuture(*rejectFuture),
+		organize.WithPreferredDateTag(*dateTag),
+		organize.WithExternalTools(!*noExternalTools),
+	)
 	if err != nil {
-		log.Fatalf("Error stating path: %s\n", err)
+		log.Fatal(err)
+	}
+
+	if *jsonRPC {
+		checkDir := func(dir string) error { return checkSafeToOrganize(dir, prof.mixed) }
+		if *force {
+			checkDir = nil
+		}
+		if err := serveJSONRPC(os.Stdin, os.Stdout, organizer, checkDir); err != nil {
+			log.Fatal(err)
+		}
+		return 0
 	}
-	if !dir.IsDir() {
-		log.Fatalf("Provider path is not a directory: %s", dirName)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Incorrect number of args to program. Expected 1, received %d\n", fs.NArg())
+		fs.Usage()
+		return 1
 	}
 
-	organizePics(dirName)
+	dirName := fs.Arg(0)
+	organizeOnce := func() (int, error) { return organizer.Organize(dirName) }
+	if mode == modeApply {
+		// The directory was checked when planning.
+		pf, err := loadPlan(fs.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		dirName = pf.Dir
+		organizeOnce = func() (int, error) { return organizer.Apply(pf) }
+	} else {
+		dir, err := os.Stat(dirName)
+		if err != nil {
+			log.Fatalf("Error stating path: %s\n", err)
+		}
+		if !dir.IsDir() {
+			log.Fatalf("Provider path is not a directory: %s", dirName)
+		}
+		if !*force {
+			if err := checkSafeToOrganize(dirName, prof.mixed); err != nil {
+				log.Fatalf("Refusing to organize: %v. Use --force if you are sure.", err)
+			}
+		}
+	}
+	if mode == modePlan {
+		pf, err := organizer.PlanFile(dirName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := writePlan(os.Stdout, pf); err != nil {
+			log.Fatal(err)
+		}
+		return 0
+	}
+
+	run := func() error {
+		moved, err := organizeOnce()
+		if err != nil {
+			return err
+		}
+		log.Printf(tr("Summary: %s"), formatSummary(organizer.Report()))
+		if report != "" {
+			if err := saveReport(*reportFile, report, organizer.Report()); err != nil {
+				log.Printf("unable to write the report: %v", err)
+			}
+		}
+		if nearMisses := organizer.NearMisses(); nearMisses.Total() > 0 {
+			log.Printf("Safety net: %s", nearMisses)
+		}
+		if *dryRun {
+			log.Printf(tr("Dry run: %d files would have been moved"), moved)
+			return nil
+		}
+		if moved > 0 {
+			if err := notifier.notify(); err != nil {
+				log.Printf("unable to notify of the new files: %v", err)
+			}
+		}
+		return nil
+	}
+	if mode != modeWatch {
+		if err := run(); err != nil {
+			log.Fatal(err)
+		}
+		return 0
+	}
+	log.Printf("Watching %s for new files", dirName)
+	watchDir(dirName, *recursive, *pollInterval, *settle, func() {
+		if err := run(); err != nil {
+			log.Print(err)
+		}
+	}, nil)
+	return 0
 }
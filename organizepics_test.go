@@ -1,36 +1,44 @@
 package main
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "IMG_20210222_213525.jpg"), []byte("a"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "IMG_20210222_213526.jpg"), []byte("a"), 0600); err != nil {
+		t.Fatal(err)
+	}
 
-func TestGetFolderName(t *testing.T) {
 	tests := []struct {
-		fileName           string
-		expectedFolderName string
-		errExpected        bool
+		args []string
+		want int
 	}{
-		{"1234", "", true}, // No matcher should exist for this, so expect an error.
-		{"IMG_20210222_213525.jpg", "2021-02-22", false},
-		{"VID_20201012_124124.mp4", "2020-10-12", false},
-		{"VID_20201012_124124_325_someextrastuff.mp4", "2020-10-12", false},
-		{"PXL_20210123_124124.mp4", "2021-01-23", false},
-		{"PXL_19891211_124124.jpg", "1989-12-11", false},
-		{"C360_2019-07-17-04-02-45-169.jpg", "2019-07-17", false},
-		{"C360_2019-07-17-04-02-45-169-12.jpg", "", true},
-		{"C360_2019-07-17-169.jpg", "", true},
-		{"20170402_1979.jpg", "2017-04-02", false},
-		{"20181030_1985.mp4", "2018-10-30", false},
+		{[]string{"frobnicate"}, 2},
+		{[]string{"--frobnicate", dir}, 2},
+		// Without a command, the directory is organized.
+		{[]string{"--no-external-tools", dir}, 0},
+		{[]string{"verify", "--trash", dir}, 2},
+		{[]string{"verify", dir}, 0},
+		{[]string{"stats", "--layout", "2006-01-02", dir}, 0},
+		{[]string{"stats", "--dry-run", dir}, 2},
+		{[]string{"dedupe", dir}, 2},
+		{[]string{"dedupe", "--dry-run", dir}, 0},
 	}
 
 	for _, tt := range tests {
-		name, err := getFolderName(tt.fileName)
-		if err != nil && !tt.errExpected {
-			t.Errorf("Expected no error but received: %s", err)
+		if got := run(tt.args); got != tt.want {
+			t.Errorf("run(%q) got %d, want %d", tt.args, got, tt.want)
 		}
-		if tt.errExpected && err == nil {
-			t.Error("Expected error but received none")
-		}
-		if name != tt.expectedFolderName {
-			t.Errorf("got %s, want %s (file name: %s)", name, tt.expectedFolderName, tt.fileName)
+	}
+	for _, name := range []string{"IMG_20210222_213525.jpg", "IMG_20210222_213526.jpg"} {
+		if _, err := os.Stat(filepath.Join(dir, "2021-02-22", name)); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
 		}
 	}
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// writePlan writes pf to w as indented JSON, for review.
+func writePlan(w io.Writer, pf organize.PlanFile) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(pf)
+}
+
+// loadPlan reads the plan written by the plan subcommand to the file at path.
+func loadPlan(path string) (organize.PlanFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return organize.PlanFile{}, err
+	}
+	var pf organize.PlanFile
+	if err := json.Unmarshal(data, &pf); err != nil {
+		return pf, fmt.Errorf("invalid plan file %q: %v", path, err)
+	}
+	if pf.Dir == "" {
+		return pf, fmt.Errorf("invalid plan file %q: no directory", path)
+	}
+	return pf, nil
+}
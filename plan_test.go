@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+func TestPlanRoundTrip(t *testing.T) {
+	modTime := time.Date(2021, 2, 22, 21, 35, 25, 123, time.UTC)
+	pf := organize.PlanFile{
+		Dir: "/pictures",
+		Plan: organize.Plan{
+			Moves:     []organize.PlannedMove{{Src: "/pictures/IMG_20210222_213525.jpg", DestDir: "/pictures/2021-02-22", Date: time.Date(2021, 2, 22, 0, 0, 0, 0, time.UTC)}},
+			Unmatched: []organize.UnmatchedFile{{Path: "/pictures/notes.txt", Reason: "no matcher"}},
+		},
+		Files: map[string]*organize.FileSnapshot{
+			"/pictures/IMG_20210222_213525.jpg":            {Size: 42, ModTime: modTime},
+			"/pictures/2021-02-22/IMG_20210222_213525.jpg": nil,
+		},
+	}
+	var buf bytes.Buffer
+	if err := writePlan(&buf, pf); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadPlan(path)
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if !reflect.DeepEqual(got, pf) {
+		t.Errorf("got %+v, want %+v", got, pf)
+	}
+
+	for _, content := range []string{"{", `{"moves": []}`} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadPlan(path); err == nil {
+			t.Errorf("Expected error but received none (plan: %s)", content)
+		}
+	}
+}
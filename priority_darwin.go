@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// prioDarwinProcess and prioDarwinBG are the setpriority(2) arguments that
+// put a process in the background state, which lowers both its CPU and I/O
+// priority.
+const (
+	prioDarwinProcess = 4
+	prioDarwinBG      = 0x1000
+)
+
+// lowerPriority gives the process a lower CPU and I/O priority.
+func lowerPriority() error {
+	if err := syscall.Setpriority(prioDarwinProcess, 0, prioDarwinBG); err != nil {
+		return fmt.Errorf("unable to enter background state: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+const (
+	// backgroundNice is the nice value of background runs.
+	backgroundNice = 10
+	// ioprioWhoProcess and ioprioIdle are the ioprio_set(2) arguments that
+	// give a thread the idle I/O scheduling class.
+	ioprioWhoProcess = 1
+	ioprioIdle       = 3 << 13
+)
+
+// lowerPriority gives the process a lower CPU and I/O priority. Linux sets
+// both per thread, so every thread of the process is changed; threads started
+// later inherit the priority of the thread starting them.
+func lowerPriority() error {
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil {
+			continue
+		}
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, backgroundNice); err != nil {
+			return fmt.Errorf("unable to lower CPU priority: %v", err)
+		}
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), ioprioIdle); errno != 0 {
+			return fmt.Errorf("unable to lower I/O priority: %v", errno)
+		}
+	}
+	return nil
+}
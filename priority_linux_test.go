@@ -0,0 +1,20 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestLowerPriority(t *testing.T) {
+	if err := lowerPriority(); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	// The raw getpriority(2) value is 20 minus the nice value.
+	prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nice := 20 - prio; nice < backgroundNice {
+		t.Errorf("got nice value %d, want at least %d", nice, backgroundNice)
+	}
+}
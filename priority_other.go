@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// lowerPriority would give the process a lower CPU and I/O priority, but
+// isn't supported on this platform.
+func lowerPriority() error {
+	return fmt.Errorf("not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// processModeBackgroundBegin is the SetPriorityClass priority class that
+// lowers both the CPU and I/O priority of the current process.
+const processModeBackgroundBegin = 0x00100000
+
+var setPriorityClass = syscall.NewLazyDLL("kernel32.dll").NewProc("SetPriorityClass")
+
+// lowerPriority gives the process a lower CPU and I/O priority.
+func lowerPriority() error {
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return err
+	}
+	if ok, _, err := setPriorityClass.Call(uintptr(process), processModeBackgroundBegin); ok == 0 {
+		return fmt.Errorf("unable to enter background mode: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// profile is a set of settings tuned for a kind of directory.
+type profile struct {
+	// flags are the values of the command line flags set by the profile, by
+	// flag name. Flags given explicitly take precedence.
+	flags map[string]string
+	// mixed is set for directories that mix pictures with other files, which
+	// are then not refused by checkSafeToOrganize.
+	mixed bool
+}
+
+// profiles are the built-in profiles, by name.
+var profiles = map[string]profile{
+	// downloads is tuned for a browser's Downloads folder: pictures there
+	// come from websites (e.g. "image (3).jpg", dated by their metadata) and
+	// messengers, next to documents and archives, which are left alone.
+	// Pictures that can't be dated are quarantined rather than left among the
+	// other downloads.
+	"downloads": {
+		flags: map[string]string{
+			"scan-dates":  "true",
+			"copy-suffix": "collapse",
+			"on-conflict": "rename",
+			"quarantine":  "Unsorted",
+		},
+		mixed: true,
+	},
+}
+
+// profileNames returns the names of the built-in profiles, sorted.
+func profileNames() []string {
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile sets the flags in fs of the named profile that were not given
+// explicitly on the command line. It must be called after fs is parsed.
+func applyProfile(fs *flag.FlagSet, name string) (profile, error) {
+	p, ok := profiles[name]
+	if !ok {
+		return profile{}, fmt.Errorf("unknown profile %q, want one of %s", name, strings.Join(profileNames(), ", "))
+	}
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for name, value := range p.flags {
+		if given[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return profile{}, err
+		}
+	}
+	return p, nil
+}
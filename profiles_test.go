@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	fs := flag.NewFlagSet("organizepics", flag.ContinueOnError)
+	scanDates := fs.Bool("scan-dates", false, "")
+	copySuffix := fs.String("copy-suffix", "keep", "")
+	onConflict := fs.String("on-conflict", "skip", "")
+	quarantine := fs.String("quarantine", "", "")
+	if err := fs.Parse([]string{"--on-conflict=fail"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := applyProfile(fs, "downloads")
+	if err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	if !p.mixed {
+		t.Error("got a profile for picture directories, want one for mixed directories")
+	}
+	if !*scanDates || *copySuffix != "collapse" || *quarantine != "Unsorted" {
+		t.Errorf("got scan-dates %t, copy-suffix %s, quarantine %s, want the profile's values", *scanDates, *copySuffix, *quarantine)
+	}
+	if *onConflict != "fail" {
+		t.Errorf("got on-conflict %s, want the explicitly given fail", *onConflict)
+	}
+
+	if _, err := applyProfile(fs, "unknown"); err == nil {
+		t.Error("Expected error but received none")
+	}
+}
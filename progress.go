@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// progressInterval is how often the progress line is redrawn at most.
+const progressInterval = 200 * time.Millisecond
+
+// isTerminal reports whether f is a terminal, as opposed to a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressLine draws the progress of a run on a single line of a terminal,
+// redrawing it in place.
+type progressLine struct {
+	w io.Writer
+	// phase is the phase being drawn, which started at start.
+	phase string
+	start time.Time
+	// drawn is when the line was last drawn.
+	drawn time.Time
+	now   func() time.Time
+}
+
+// newProgressLine returns a progressLine drawing to w.
+func newProgressLine(w io.Writer) *progressLine {
+	return &progressLine{w: w, now: time.Now}
+}
+
+// update draws p, unless the line was drawn very recently. The line is ended
+// once the phase is done.
+func (l *progressLine) update(p organize.Progress) {
+	now := l.now()
+	if p.Phase != l.phase {
+		l.phase, l.start, l.drawn = p.Phase, now, time.Time{}
+	}
+	done := p.Done >= p.Total
+	if !done && now.Sub(l.drawn) < progressInterval {
+		return
+	}
+	l.drawn = now
+	fmt.Fprintf(l.w, "\r%s", formatProgress(p, now.Sub(l.start)))
+	if done {
+		fmt.Fprintln(l.w)
+	}
+}
+
+// formatProgress describes p, after elapsed time in its phase, e.g. "moving
+// 1200/50000 files (2%), 3.1G, 12m30s left".
+func formatProgress(p organize.Progress, elapsed time.Duration) string {
+	percent := 100
+	if p.Total > 0 {
+		percent = p.Done * 100 / p.Total
+	}
+	s := fmt.Sprintf(tr("%s %d/%d files (%d%%)"), tr(p.Phase), p.Done, p.Total, percent)
+	if p.Bytes > 0 {
+		s += ", " + formatBytes(p.Bytes)
+	}
+	if p.Done > 0 && p.Done < p.Total {
+		left := elapsed * time.Duration(p.Total-p.Done) / time.Duration(p.Done)
+		s += ", " + fmt.Sprintf(tr("%s left"), left.Round(time.Second))
+	}
+	// Pad to overwrite the end of a longer previous line.
+	return fmt.Sprintf("%-60s", s)
+}
+
+// formatBytes formats n bytes with the largest unit of byteSizeUnits it has
+// at least one of, e.g. "3.1G".
+func formatBytes(n int64) string {
+	for _, unit := range []string{"G", "M", "K"} {
+		if m := byteSizeUnits[unit]; n >= m {
+			return fmt.Sprintf("%.1f%s", float64(n)/float64(m), unit)
+		}
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// summaryStatuses are the statuses listed by formatSummary, in order, with
+// their message.
+var summaryStatuses = []struct {
+	status  organize.FileStatus
+	message string
+}{
+	{organize.StatusMoved, "%d moved"},
+	{organize.StatusSkipped, "%d skipped"},
+	{organize.StatusRemoved, "%d removed as duplicates"},
+	{organize.StatusUnmatched, "%d unmatched"},
+	{organize.StatusConflict, "%d conflicts"},
+	{organize.StatusError, "%d errors"},
+}
+
+// formatSummary summarizes report, e.g. "12 moved, 3 skipped, 0 removed as
+// duplicates, 2 unmatched, 0 conflicts, 1 errors".
+func formatSummary(report organize.Report) string {
+	counts := report.Counts()
+	parts := make([]string, len(summaryStatuses))
+	for i, s := range summaryStatuses {
+		parts[i] = fmt.Sprintf(tr(s.message), counts[s.status])
+	}
+	return strings.Join(parts, ", ")
+}
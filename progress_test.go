@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+func TestFormatProgress(t *testing.T) {
+	tests := []struct {
+		p       organize.Progress
+		elapsed time.Duration
+		want    string
+	}{
+		{organize.Progress{Phase: "dating", Done: 0, Total: 10}, 0, "dating 0/10 files (0%)"},
+		{organize.Progress{Phase: "moving", Done: 25, Total: 100, Bytes: 3 << 29}, time.Minute, "moving 25/100 files (25%), 1.5G, 3m0s left"},
+		{organize.Progress{Phase: "moving", Done: 100, Total: 100, Bytes: 2048}, time.Minute, "moving 100/100 files (100%), 2.0K"},
+		{organize.Progress{Phase: "moving", Done: 0, Total: 0}, 0, "moving 0/0 files (100%)"},
+	}
+	for _, tt := range tests {
+		if got := strings.TrimRight(formatProgress(tt.p, tt.elapsed), " "); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestProgressLine(t *testing.T) {
+	var b bytes.Buffer
+	l := newProgressLine(&b)
+	now := time.Date(2021, 2, 22, 21, 35, 25, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	l.update(organize.Progress{Phase: "moving", Done: 0, Total: 3})
+	l.update(organize.Progress{Phase: "moving", Done: 1, Total: 3}) // Too soon.
+	now = now.Add(time.Second)
+	l.update(organize.Progress{Phase: "moving", Done: 2, Total: 3})
+	l.update(organize.Progress{Phase: "moving", Done: 3, Total: 3}) // Done.
+	if got, want := strings.Count(b.String(), "\r"), 3; got != want {
+		t.Errorf("got %d lines drawn, want %d", got, want)
+	}
+	if !strings.HasSuffix(b.String(), "\n") {
+		t.Errorf("got %q, want a line ended once done", b.String())
+	}
+}
+
+func TestFormatSummary(t *testing.T) {
+	report := organize.Report{Files: []organize.FileResult{
+		{Status: organize.StatusMoved},
+		{Status: organize.StatusMoved},
+		{Status: organize.StatusUnmatched},
+		{Status: organize.StatusError},
+	}}
+	want := "2 moved, 0 skipped, 0 removed as duplicates, 1 unmatched, 0 conflicts, 1 errors"
+	if got := formatSummary(report); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
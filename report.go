@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// reportFormat is a flag.Value selecting the format of the run report: json,
+// csv, or empty for none.
+type reportFormat string
+
+// String implements flag.Value.
+func (f *reportFormat) String() string {
+	return string(*f)
+}
+
+// Set implements flag.Value.
+func (f *reportFormat) Set(s string) error {
+	switch s {
+	case "json", "csv":
+		*f = reportFormat(s)
+		return nil
+	}
+	return fmt.Errorf("unknown report format %q, want \"json\" or \"csv\"", s)
+}
+
+// jsonReport is the JSON report of a run.
+type jsonReport struct {
+	Tool string `json:"tool"`
+	organize.Report
+	Counts map[organize.FileStatus]int `json:"counts"`
+}
+
+// writeReport writes report to w in the given format. CSV reports have a row
+// per file, and per skipped directory, for auditing in a spreadsheet.
+func writeReport(w io.Writer, format reportFormat, report organize.Report) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(jsonReport{buildSummary(), report, report.Counts()})
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"path", "status", "dest", "detail", "date"})
+		for _, f := range report.Files {
+			cw.Write([]string{f.Path, string(f.Status), f.Dest, f.Detail, f.Date})
+		}
+		for _, s := range report.Skipped {
+			cw.Write([]string{s.Path, "skipped directory", "", s.Reason, ""})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// saveReport writes report in the given format to the file at path, or to
+// standard output if path is empty or "-".
+func saveReport(path string, format reportFormat, report organize.Report) error {
+	if path == "" || path == "-" {
+		return writeReport(os.Stdout, format, report)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(f, format, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
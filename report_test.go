@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+func TestReportFormat(t *testing.T) {
+	var f reportFormat
+	for _, s := range []string{"json", "csv"} {
+		if err := f.Set(s); err != nil {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+	}
+	if err := f.Set("xml"); err == nil {
+		t.Errorf("Expected error but received none")
+	}
+}
+
+func TestWriteReportCSV(t *testing.T) {
+	report := organize.Report{
+		Files: []organize.FileResult{
+			{Path: "a, b.jpg", Status: organize.StatusMoved, Dest: "2021-02-22/a, b.jpg", Date: "2021-02-22T23:30:00+01:00"},
+			{Path: "notes.txt", Status: organize.StatusUnmatched, Detail: "no date"},
+		},
+		Skipped: []organize.SkippedDir{{Path: ".thumbnails", Reason: "hidden"}},
+	}
+	var b bytes.Buffer
+	if err := writeReport(&b, "csv", report); err != nil {
+		t.Fatalf("Expected no error but received: %s", err)
+	}
+	want := "path,status,dest,detail,date\n" +
+		"\"a, b.jpg\",moved,\"2021-02-22/a, b.jpg\",,2021-02-22T23:30:00+01:00\n" +
+		"notes.txt,unmatched,,no date,\n" +
+		".thumbnails,skipped directory,,hidden,\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// rm implements the rm subcommand, which deletes or trashes files of an
+// organized directory, recording each removal in a journal. It returns the
+// process exit code.
+func rm(args []string) int {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	root := fs.String("root", "", "organized directory holding the files (default: guessed from each file's location)")
+	journal := fs.String("journal", "", "journal to record the removals in (required)")
+	reason := fs.String("reason", "", "why the files are removed, recorded in the journal")
+	trash := fs.Bool("trash", false, "move the files into "+organize.TrashDirName+" at the root of the organized directory, from where undo can restore them, instead of deleting them")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s rm:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s rm --journal <journal> [--reason <reason>] [--trash] [--root <dir>] <files...>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 || *journal == "" {
+		fs.Usage()
+		return 2
+	}
+	o, err := organize.New(organize.WithJournal(*journal), organize.WithBuild(buildSummary()), organize.WithExternalTools(false))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	code := 0
+	for _, path := range fs.Args() {
+		dir := *root
+		if dir == "" {
+			dir = archiveRoot(path, organize.DefaultLayout)
+		}
+		if err := o.Remove(dir, path, *reason, *trash); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to remove %q: %v\n", path, err)
+			code = 1
+		}
+	}
+	return code
+}
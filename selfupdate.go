@@ -0,0 +1,248 @@
+//go:build !minimal
+// +build !minimal
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// latestReleaseURL is the GitHub API endpoint describing the latest release.
+const latestReleaseURL = "https://api.github.com/repos/cvanderw/organizepics/releases/latest"
+
+// checksumsAssetName is the release asset listing the SHA-256 checksums of
+// the binaries, in the format of sha256sum. If the binary was built with an
+// updatePublicKey, checksumsAssetName+".sig" must hold the base64 encoded
+// Ed25519 signature of the checksums.
+const checksumsAssetName = "SHA256SUMS"
+
+// updatePublicKey is the base64 encoded Ed25519 key that release checksums
+// are signed with, set when building releases with
+// -ldflags "-X main.updatePublicKey=...". Without it, self-update relies on
+// the checksums alone, which protect against corrupted but not against
+// tampered downloads.
+var updatePublicKey = ""
+
+const (
+	// updateTimeout bounds the duration of each request of self-update.
+	updateTimeout = 5 * time.Minute
+	// maxAssetSize bounds the size of downloaded release assets.
+	maxAssetSize = 256 << 20
+)
+
+// release is the part of a GitHub release used by self-update.
+type release struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the URL of the asset with the given name.
+func (r release) asset(name string) (string, error) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, nil
+		}
+	}
+	return "", fmt.Errorf("release %s has no asset %q", r.TagName, name)
+}
+
+// binaryAssetName is the name of the release asset holding the binary for
+// this platform, e.g. "organizepics_linux_arm64".
+func binaryAssetName() string {
+	name := fmt.Sprintf("organizepics_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// selfUpdate implements the self-update subcommand, which replaces the
+// running binary with the one of the latest GitHub release after verifying
+// its checksum (and signature, for binaries built with a public key). It
+// returns the process exit code.
+func selfUpdate(args []string) int {
+	fs := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	check := fs.Bool("check", false, "only report whether an update is available")
+	force := fs.Bool("force", false, "install the latest release even if it is the running version")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to locate the running binary: %v\n", err)
+		return 1
+	}
+	u := updater{
+		client:     &http.Client{Timeout: updateTimeout},
+		releaseURL: latestReleaseURL,
+		publicKey:  updatePublicKey,
+	}
+	if err := u.update(exe, versionString(), *check, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "self-update failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// updater updates binaries from the release described at releaseURL.
+type updater struct {
+	client     *http.Client
+	releaseURL string
+	publicKey  string
+}
+
+// update replaces the binary at exe, of the given version, with the binary of
+// the latest release if that is a different version (or force is set). If
+// check is set, it only reports whether an update is available.
+func (u updater) update(exe, current string, check, force bool) error {
+	var r release
+	data, err := u.get(u.releaseURL)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return fmt.Errorf("invalid release description: %v", err)
+	}
+	if r.TagName == current && !force {
+		fmt.Printf("organizepics %s is up to date\n", current)
+		return nil
+	}
+	if check {
+		fmt.Printf("organizepics %s is available (running %s)\n", r.TagName, current)
+		return nil
+	}
+
+	name := binaryAssetName()
+	binary, err := u.getAsset(r, name)
+	if err != nil {
+		return err
+	}
+	sums, err := u.getAsset(r, checksumsAssetName)
+	if err != nil {
+		return err
+	}
+	if u.publicKey != "" {
+		sig, err := u.getAsset(r, checksumsAssetName+".sig")
+		if err != nil {
+			return err
+		}
+		if err := verifySignature(sums, sig, u.publicKey); err != nil {
+			return err
+		}
+	}
+	if err := verifyChecksum(sums, name, binary); err != nil {
+		return err
+	}
+	if err := replaceFile(exe, binary); err != nil {
+		return err
+	}
+	fmt.Printf("Updated organizepics from %s to %s\n", current, r.TagName)
+	return nil
+}
+
+// getAsset downloads the asset of r with the given name.
+func (u updater) getAsset(r release, name string) ([]byte, error) {
+	url, err := r.asset(name)
+	if err != nil {
+		return nil, err
+	}
+	return u.get(url)
+}
+
+// get returns the body of url, which must be at most maxAssetSize bytes.
+func (u updater) get(url string) ([]byte, error) {
+	resp, err := u.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAssetSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, maxAssetSize)
+	}
+	return data, nil
+}
+
+// verifyChecksum checks data, the contents of the asset with the given name,
+// against its SHA-256 checksum in sums.
+func verifyChecksum(sums []byte, name string, data []byte) error {
+	sum := sha256.Sum256(data)
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks binary mode with a '*' before the name.
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		if fields[0] != hex.EncodeToString(sum[:]) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum for %s", name)
+}
+
+// verifySignature checks the base64 encoded Ed25519 signature sig of sums
+// against the base64 encoded publicKey.
+func verifySignature(sums, sig []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid built-in update public key")
+	}
+	rawSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(key), sums, rawSig) {
+		return fmt.Errorf("invalid signature of %s", checksumsAssetName)
+	}
+	return nil
+}
+
+// replaceFile atomically replaces the executable at path with data, by
+// writing data to a temporary file next to it and renaming that into place.
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,101 @@
+//go:build !minimal
+// +build !minimal
+
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdaterUpdate(t *testing.T) {
+	newBinary := []byte("new binary")
+	sum := sha256.Sum256(newBinary)
+	sums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), binaryAssetName())
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(sums)))
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			json.NewEncoder(w).Encode(release{
+				TagName: "v1.3.0",
+				Assets: []releaseAsset{
+					{binaryAssetName(), srv.URL + "/binary"},
+					{checksumsAssetName, srv.URL + "/sums"},
+					{checksumsAssetName + ".sig", srv.URL + "/sig"},
+				},
+			})
+		case "/binary":
+			w.Write(newBinary)
+		case "/sums":
+			w.Write([]byte(sums))
+		case "/sig":
+			w.Write([]byte(sig))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		current   string
+		publicKey []byte
+		want      string
+		errWanted bool
+	}{
+		{"v1.2.0", publicKey, "new binary", false},
+		{"v1.2.0", nil, "new binary", false},
+		{"v1.3.0", publicKey, "old binary", false},
+		// A signature by another key is rejected.
+		{"v1.2.0", make([]byte, ed25519.PublicKeySize), "old binary", true},
+	}
+
+	for _, tt := range tests {
+		exe := filepath.Join(t.TempDir(), "organizepics")
+		if err := os.WriteFile(exe, []byte("old binary"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		u := updater{client: srv.Client(), releaseURL: srv.URL + "/latest"}
+		if tt.publicKey != nil {
+			u.publicKey = base64.StdEncoding.EncodeToString(tt.publicKey)
+		}
+		err := u.update(exe, tt.current, false, false)
+		if err != nil && !tt.errWanted {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+		if err == nil && tt.errWanted {
+			t.Errorf("Expected error but received none (running %s)", tt.current)
+		}
+		if got, _ := os.ReadFile(exe); string(got) != tt.want {
+			t.Errorf("got binary %q, want %q (running %s)", got, tt.want, tt.current)
+		}
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("binary")
+	sum := sha256.Sum256(data)
+	sums := []byte(hex.EncodeToString(sum[:]) + " *organizepics_linux_amd64\n0000  organizepics_linux_arm64\n")
+	if err := verifyChecksum(sums, "organizepics_linux_amd64", data); err != nil {
+		t.Errorf("Expected no error but received: %s", err)
+	}
+	for _, name := range []string{"organizepics_linux_arm64", "organizepics_darwin_arm64"} {
+		if err := verifyChecksum(sums, name, data); err == nil {
+			t.Errorf("Expected error but received none (asset: %s)", name)
+		}
+	}
+}
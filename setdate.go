@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// setDateLayouts are the accepted formats of the set-date --date flag. Dates
+// without a time are set to noon, which keeps them on the same day in any
+// nearby time zone.
+var setDateLayouts = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// parseSetDate parses the value of the set-date --date flag.
+func parseSetDate(s string) (time.Time, error) {
+	for _, layout := range setDateLayouts {
+		if date, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			if layout == "2006-01-02" {
+				date = date.Add(12 * time.Hour)
+			}
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, want YYYY-MM-DD or YYYY-MM-DD hh:mm:ss", s)
+}
+
+// archiveRoot guesses the root of the organized directory holding path,
+// organized with the Go time layout layout: the directory above its dated
+// directories if it is in some, or else its own directory.
+func archiveRoot(path, layout string) string {
+	dir := filepath.Dir(path)
+	root := dir
+	var parts []string
+	for range strings.Split(layout, "/") {
+		parts = append([]string{filepath.Base(root)}, parts...)
+		root = filepath.Dir(root)
+	}
+	if _, err := time.Parse(layout, strings.Join(parts, "/")); err == nil {
+		return root
+	}
+	return dir
+}
+
+// setDate implements the set-date subcommand, which writes a capture date
+// into the metadata of files (using exiftool) and then moves them into the
+// directory for that date, as laid out by --layout, recording the moves in the
+// --journal, if any, like those of organizing. This is mostly useful for
+// scanned photos, whose metadata carries the date they were scanned. It
+// returns the process exit code.
+func setDate(args []string) int {
+	fs := flag.NewFlagSet("set-date", flag.ContinueOnError)
+	dateFlag := fs.String("date", "", "capture date to set, as YYYY-MM-DD or YYYY-MM-DD hh:mm:ss")
+	root := fs.String("root", "", "organized directory to refile the files into (default: guessed from each file's location)")
+	layout := fs.String("layout", organize.DefaultLayout, "Go time layout the directory was organized with, e.g. 2006/2006-01-02")
+	journal := fs.String("journal", "", "journal to record the moves in, as written with --journal, so undo can revert them")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s set-date:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s set-date --date <date> [--root <dir>] [--layout <date layout>] [--journal <journal>] <files...>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	date, err := parseSetDate(*dateFlag)
+	if err != nil || fs.NArg() == 0 {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		fs.Usage()
+		return 2
+	}
+	if _, err := exec.LookPath("exiftool"); err != nil {
+		fmt.Fprintf(os.Stderr, "set-date requires exiftool: %v\n", err)
+		return 1
+	}
+
+	o, err := organize.New(organize.WithLayout(*layout), organize.WithJournal(*journal), organize.WithBuild(buildSummary()), organize.WithExternalTools(false))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	code := 0
+	exifDate := date.Format("2006:01:02 15:04:05")
+	for _, path := range fs.Args() {
+		out, err := exec.Command("exiftool", "-overwrite_original", "-q",
+			"-AllDates="+exifDate, "-XMP:DateCreated="+exifDate, path).CombinedOutput()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to set date of %q: %v: %s\n", path, err, out)
+			code = 1
+			continue
+		}
+		dest := *root
+		if dest == "" {
+			dest = archiveRoot(path, *layout)
+		}
+		if _, err := o.Relocate(dest, path, date); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to refile %q: %v\n", path, err)
+			code = 1
+		}
+	}
+	return code
+}
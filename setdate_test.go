@@ -0,0 +1,53 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSetDate(t *testing.T) {
+	tests := []struct {
+		s           string
+		want        time.Time
+		errExpected bool
+	}{
+		{"1998-12-25", time.Date(1998, 12, 25, 12, 0, 0, 0, time.Local), false},
+		{"1998-12-25 08:30:00", time.Date(1998, 12, 25, 8, 30, 0, 0, time.Local), false},
+		{"25.12.1998", time.Time{}, true},
+		{"", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSetDate(tt.s)
+		if err != nil && !tt.errExpected {
+			t.Errorf("Expected no error but received: %s", err)
+		}
+		if tt.errExpected && err == nil {
+			t.Errorf("Expected error but received none (date: %q)", tt.s)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("got %s, want %s (date: %q)", got, tt.want, tt.s)
+		}
+	}
+}
+
+func TestArchiveRoot(t *testing.T) {
+	tests := []struct {
+		path   string
+		layout string
+		want   string
+	}{
+		{filepath.Join("photos", "2021-02-22", "scan.jpg"), "2006-01-02", "photos"},
+		{filepath.Join("photos", "incoming", "scan.jpg"), "2006-01-02", filepath.Join("photos", "incoming")},
+		{filepath.Join("photos", "2021", "2021-02-22", "scan.jpg"), "2006/2006-01-02", "photos"},
+		{filepath.Join("photos", "2021", "02", "scan.jpg"), "2006/01", "photos"},
+		{filepath.Join("photos", "2021-02-22", "scan.jpg"), "2006/2006-01-02", filepath.Join("photos", "2021-02-22")},
+	}
+
+	for _, tt := range tests {
+		if got := archiveRoot(tt.path, tt.layout); got != tt.want {
+			t.Errorf("archiveRoot(%q, %q) = %q, want %q", tt.path, tt.layout, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// stats implements the stats subcommand, which prints the number and size of
+// the files of an organized directory per year. It returns the process exit
+// code.
+func stats(args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	layout := fs.String("layout", organize.DefaultLayout, "Go time layout the directory was organized with, e.g. 2006/2006-01-02")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s stats:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s stats [--layout <date layout>] <organized directory>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	organizer, err := organize.New(organize.WithLayout(*layout), organize.WithExternalTools(false))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	s, err := organizer.Stats(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	years := make([]int, 0, len(s.Years))
+	for year := range s.Years {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	for _, year := range years {
+		fmt.Printf("%d: %s\n", year, formatCount(s.Years[year]))
+	}
+	if s.Undated.Files > 0 {
+		fmt.Printf("undated: %s\n", formatCount(s.Undated))
+	}
+	fmt.Printf("total: %s\n", formatCount(s.Total))
+	return 0
+}
+
+// formatCount formats c as e.g. "120 files, 1.2G".
+func formatCount(c organize.Count) string {
+	return fmt.Sprintf("%d files, %s", c.Files, formatBytes(c.Bytes))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// testMatcher implements the test-matcher subcommand, which checks a custom
+// matcher against sample file names and prints the date each name would be
+// filed under. It returns the process exit code: 0 if every name was matched,
+// 1 if some were not and 2 on usage errors.
+func testMatcher(args []string) int {
+	fs := flag.NewFlagSet("test-matcher", flag.ContinueOnError)
+	pattern := fs.String("pattern", "", "regular expression identifying the file names and their date")
+	layout := fs.String("layout", "", "Go time layout of the date captured by the pattern (e.g. 20060102); if empty the pattern must capture year, month and day groups")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s test-matcher:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s test-matcher --pattern <regex> [--layout <date layout>] [file names...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *pattern == "" {
+		fmt.Fprintln(os.Stderr, "--pattern is required")
+		fs.Usage()
+		return 2
+	}
+
+	matcher, err := organize.NewPatternMatcher(*pattern, *layout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	code := 0
+	for _, name := range fs.Args() {
+		date, err := matcher.ParseDate(name)
+		if err != nil {
+			code = 1
+			fmt.Printf("%s\tno match: %v\n", name, err)
+			if reason := matcher.NearMiss(name); reason != "" {
+				fmt.Printf("%s\t  %s\n", name, reason)
+			}
+			continue
+		}
+		fmt.Printf("%s\t%s\n", name, date.Format("2006-01-02"))
+	}
+	return code
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// undo implements the undo subcommand, which moves the files recorded in a
+// journal written with --journal back where they came from. It returns the
+// process exit code.
+func undo(args []string) int {
+	fs := flag.NewFlagSet("undo", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "log the moves that would be undone, without changing anything")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s undo:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s undo [--dry-run] <journal>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	n, err := organize.Undo(fs.Arg(0), *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *dryRun {
+		fmt.Printf("Dry run: %d files would have been moved back\n", n)
+	} else {
+		fmt.Printf("Moved %d files back\n", n)
+	}
+	return 0
+}
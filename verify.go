@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// verify implements the verify subcommand, which checks that the files of an
+// organized directory are in the dated directories they belong in. It returns
+// the process exit code: 1 if any file is misplaced.
+func verify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	layout := fs.String("layout", organize.DefaultLayout, "Go time layout the directory was organized with, e.g. 2006/2006-01-02")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s verify:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s verify [--layout <date layout>] <organized directory>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	organizer, err := organize.New(organize.WithLayout(*layout), organize.WithExternalTools(false))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	misplaced, err := organizer.Verify(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, m := range misplaced {
+		if m.Want != "" {
+			fmt.Printf("%s: %s, belongs in %s\n", m.Path, m.Reason, m.Want)
+		} else {
+			fmt.Printf("%s: %s\n", m.Path, m.Reason)
+		}
+	}
+	if len(misplaced) > 0 {
+		fmt.Printf("%d misplaced files\n", len(misplaced))
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// Build information, set when building releases with e.g.
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%FT%TZ)"
+var (
+	version   = ""
+	commit    = ""
+	buildDate = ""
+)
+
+// versionString returns the version of the binary: the one set at build
+// time, else the module version when installed with go install, else "dev".
+func versionString() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// buildSummary describes the binary in one line, so that the output of past
+// runs can be attributed to a binary.
+func buildSummary() string {
+	s := "organizepics " + versionString()
+	if commit != "" {
+		s += " (" + commit + ")"
+	}
+	return s
+}
+
+// writeVersion writes the version and build information of the binary and the
+// optional features available on this system to w.
+func writeVersion(w io.Writer) {
+	fmt.Fprintln(w, buildSummary())
+	if commit != "" {
+		fmt.Fprintf(w, "commit:         %s\n", commit)
+	}
+	if buildDate != "" {
+		fmt.Fprintf(w, "built:          %s\n", buildDate)
+	}
+	fmt.Fprintf(w, "go:             %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	tools := organize.InstalledExternalTools()
+	if len(tools) == 0 {
+		tools = []string{"none"}
+	}
+	fmt.Fprintf(w, "external tools: %s\n", strings.Join(tools, ", "))
+	previews := "no (ffmpeg not installed)"
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		previews = "yes"
+	}
+	fmt.Fprintf(w, "previews:       %s\n", previews)
+	fmt.Fprintf(w, "backends:       local\n")
+	if minimalBuild {
+		fmt.Fprintf(w, "build:          minimal (no config files, --notify-url, self-update or file system notifications)\n")
+	}
+}
+
+// printVersion implements the version subcommand. It returns the process exit
+// code.
+func printVersion(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "version takes no arguments")
+		return 2
+	}
+	writeVersion(os.Stdout)
+	return 0
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteVersion(t *testing.T) {
+	defer func(v, c, d string) { version, commit, buildDate = v, c, d }(version, commit, buildDate)
+	version, commit, buildDate = "v1.2.0", "0123abc", "2026-10-16T08:00:00Z"
+
+	var out bytes.Buffer
+	writeVersion(&out)
+	for _, want := range []string{
+		"organizepics v1.2.0 (0123abc)\n",
+		"commit:         0123abc\n",
+		"built:          2026-10-16T08:00:00Z\n",
+		"external tools: ",
+		"backends:       local\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("version output %q does not contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestVersionStringDefault(t *testing.T) {
+	if got := versionString(); got == "" {
+		t.Error("got an empty version, want a placeholder")
+	}
+}
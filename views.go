@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cvanderw/organizepics/organize"
+)
+
+// viewsFlag is a repeatable flag selecting views to build.
+type viewsFlag []organize.View
+
+// String implements flag.Value.
+func (v *viewsFlag) String() string {
+	names := make([]string, len(*v))
+	for i, view := range *v {
+		names[i] = string(view)
+	}
+	return strings.Join(names, ",")
+}
+
+// Set implements flag.Value.
+func (v *viewsFlag) Set(s string) error {
+	var view organize.View
+	if err := view.Set(s); err != nil {
+		return err
+	}
+	*v = append(*v, view)
+	return nil
+}
+
+// buildViews implements the views subcommand, which builds or refreshes
+// alternate views of an organized directory as directories of symbolic links,
+// without duplicating any file. It returns the process exit code.
+func buildViews(args []string) int {
+	fs := flag.NewFlagSet("views", flag.ContinueOnError)
+	var views viewsFlag
+	fs.Var(&views, "view", "view to build: year, camera or type; may be repeated (default: all)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s views:\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "  %s views [--view <view>] <organized directory>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if len(views) == 0 {
+		views = organize.AllViews
+	}
+	root := fs.Arg(0)
+	code := 0
+	for _, view := range views {
+		n, err := organize.BuildView(root, view)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to build the %s view: %v\n", view, err)
+			code = 1
+			continue
+		}
+		fmt.Printf("%s view: %d files\n", view, n)
+	}
+	return code
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"io/fs"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileState is the size and modification time of a file, which change while
+// the file is being written.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// dirState maps the paths of the files of a watched directory to their state.
+type dirState map[string]fileState
+
+// snapshotDir returns the state of the files directly in dir or, if recursive
+// is set, in its subdirectories too (except hidden ones, such as .trash).
+func snapshotDir(dir string, recursive bool) (dirState, error) {
+	state := make(dirState)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (!recursive || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		state[path] = fileState{info.Size(), info.ModTime()}
+		return nil
+	})
+	return state, err
+}
+
+// equal reports whether s and t hold the same files in the same state.
+func (s dirState) equal(t dirState) bool {
+	if len(s) != len(t) {
+		return false
+	}
+	for path, f := range s {
+		g, ok := t[path]
+		if !ok || f.size != g.size || !f.modTime.Equal(g.modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// settler decides when a watched directory is to be organized: once it has
+// changed since it was last organized, and then stayed the same for the
+// settling delay, so that files still being transferred aren't grabbed.
+type settler struct {
+	settle  time.Duration
+	last    dirState
+	changed time.Time
+	pending bool
+}
+
+// observe records the state of the directory at now and reports whether it is
+// time to organize it.
+func (s *settler) observe(state dirState, now time.Time) bool {
+	if !state.equal(s.last) {
+		s.last, s.changed, s.pending = state, now, true
+	}
+	return s.pending && now.Sub(s.changed) >= s.settle
+}
+
+// organized records the state of the directory after organizing it. Files
+// left in place, e.g. because they could not be dated, are then only
+// considered again if they change.
+func (s *settler) organized(state dirState) {
+	s.last, s.pending = state, false
+}
+
+// pollDir polls dir every interval, calling organize once it has settled
+// after changing, until stop is closed. Files already in dir count as a
+// change, so they are organized once they have settled too. Errors are
+// logged rather than returned, so that watching survives e.g. a network
+// mount going away for a while.
+func pollDir(dir string, recursive bool, interval, settle time.Duration, organize func(), stop <-chan struct{}) {
+	s := settler{settle: settle}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if state, err := snapshotDir(dir, recursive); err != nil {
+			log.Printf("unable to watch %s: %v", dir, err)
+		} else if s.observe(state, time.Now()) {
+			organize()
+			if state, err := snapshotDir(dir, recursive); err == nil {
+				s.organized(state)
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,103 @@
+//go:build !minimal
+// +build !minimal
+
+package main
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDir watches dir with the file system notifications of the OS, calling
+// organize once no file has been written for settle after a change, until
+// stop is closed. Files already in dir count as a change, so they are
+// organized once they have settled too. Changes that leave the names, sizes
+// and modification times of the files as they were after organizing, such as
+// the dated directories organize creates, are ignored. If notifications are
+// unavailable, dir is polled every interval instead. Errors are logged rather
+// than returned, so that watching survives e.g. a network mount going away
+// for a while.
+func watchDir(dir string, recursive bool, interval, settle time.Duration, organize func(), stop <-chan struct{}) {
+	w, err := fsnotify.NewWatcher()
+	if err == nil {
+		err = addWatches(w, dir, recursive)
+	}
+	if err != nil {
+		if w != nil {
+			w.Close()
+		}
+		log.Printf("unable to watch %s for changes, polling it instead: %v", dir, err)
+		pollDir(dir, recursive, interval, settle, organize, stop)
+		return
+	}
+	defer w.Close()
+
+	last := dirState{}
+	settled := time.NewTimer(settle)
+	defer settled.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if recursive && event.Op&fsnotify.Create != 0 && !strings.HasPrefix(filepath.Base(event.Name), ".") {
+				// Watch the new directories, with the subdirectories moved in with them.
+				if err := addWatches(w, event.Name, true); err != nil && !os.IsNotExist(err) {
+					log.Printf("unable to watch %s: %v", event.Name, err)
+				}
+			}
+			if !settled.Stop() {
+				select {
+				case <-settled.C:
+				default:
+				}
+			}
+			settled.Reset(settle)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("unable to watch %s: %v", dir, err)
+		case <-settled.C:
+			state, err := snapshotDir(dir, recursive)
+			if err != nil {
+				log.Printf("unable to watch %s: %v", dir, err)
+				continue
+			}
+			if state.equal(last) {
+				continue
+			}
+			organize()
+			if last, err = snapshotDir(dir, recursive); err != nil {
+				last = state
+			}
+		}
+	}
+}
+
+// addWatches adds dir to the directories w watches and, if recursive is set,
+// its subdirectories except hidden ones (such as .trash), as snapshotDir
+// does. Paths that are not directories are ignored.
+func addWatches(w *fsnotify.Watcher, dir string, recursive bool) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && (!recursive || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		return w.Add(path)
+	})
+}
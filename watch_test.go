@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSettler(t *testing.T) {
+	start := time.Date(2021, 2, 22, 21, 35, 0, 0, time.UTC)
+	a := dirState{"a.jpg": {100, start}}
+	aGrown := dirState{"a.jpg": {200, start.Add(time.Second)}}
+	ab := dirState{"a.jpg": {200, start.Add(time.Second)}, "b.jpg": {100, start}}
+	tests := []struct {
+		state    dirState
+		after    time.Duration
+		organize bool
+	}{
+		{dirState{}, 0, false}, // Nothing to organize.
+		{a, 10 * time.Second, false},
+		{aGrown, 20 * time.Second, false}, // Still being written.
+		{aGrown, 70 * time.Second, false},
+		{aGrown, 80 * time.Second, true}, // Unchanged for a minute.
+		{ab, 90 * time.Second, false},
+		{ab, 150 * time.Second, true},
+	}
+	s := settler{settle: time.Minute}
+	for i, tt := range tests {
+		if got := s.observe(tt.state, start.Add(tt.after)); got != tt.organize {
+			t.Errorf("got %t, want %t (step %d)", got, tt.organize, i)
+		}
+		if tt.organize {
+			s.organized(tt.state)
+			if s.observe(tt.state, start.Add(tt.after+time.Hour)) {
+				t.Errorf("Expected no organizing of an unchanged directory (step %d)", i)
+			}
+		}
+	}
+}
+
+func TestSnapshotDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"a.jpg", "DCIM/b.jpg", ".trash/c.jpg"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0700); err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, p), []byte(p), 0600); err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+	}
+	tests := []struct {
+		recursive bool
+		want      []string
+	}{
+		{false, []string{"a.jpg"}},
+		{true, []string{"a.jpg", "DCIM/b.jpg"}},
+	}
+	for _, tt := range tests {
+		state, err := snapshotDir(dir, tt.recursive)
+		if err != nil {
+			t.Fatalf("Expected no error but received: %s", err)
+		}
+		if len(state) != len(tt.want) {
+			t.Errorf("got %d files, want %d (recursive: %t)", len(state), len(tt.want), tt.recursive)
+		}
+		for _, p := range tt.want {
+			if f, ok := state[filepath.Join(dir, filepath.FromSlash(p))]; !ok || f.size != int64(len(p)) {
+				t.Errorf("got %+v, want %s of size %d (recursive: %t)", f, p, len(p), tt.recursive)
+			}
+		}
+	}
+}
+
+func TestWatchDir(t *testing.T) {
+	dir := t.TempDir()
+	organized := make(chan dirState, 10)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		watchDir(dir, false, 10*time.Millisecond, 100*time.Millisecond, func() {
+			state, _ := snapshotDir(dir, false)
+			organized <- state
+		}, stop)
+		close(done)
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	// A file written in several steps is organized once, when complete.
+	path := filepath.Join(dir, "IMG_20210222_213525.jpg")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := f.Write([]byte("data")); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	f.Close()
+	select {
+	case state := <-organized:
+		if got := state[path].size; got != 20 {
+			t.Errorf("organized with the file at %d bytes, want 20", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the directory was not organized")
+	}
+	select {
+	case <-organized:
+		t.Error("Expected no organizing of an unchanged directory")
+	case <-time.After(300 * time.Millisecond):
+	}
+}